@@ -0,0 +1,191 @@
+package amqp
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrPermanent marks handler error which never succeed on redelivery, such deliveries are rejected without requeue
+var ErrPermanent = errors.New("permanent error")
+
+// Permanent wraps err, so Ack middleware rejects delivery instead of nack
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &permanentError{err: err}
+}
+
+type permanentError struct {
+	err error
+}
+
+func (p *permanentError) Error() string { return p.err.Error() }
+
+func (p *permanentError) Unwrap() error { return p.err }
+
+func (p *permanentError) Is(target error) bool { return target == ErrPermanent }
+
+// acknowledger meters outcome of delivery settled by handler or Ack middleware per queue
+// and records it as event of consumer span
+type acknowledger struct {
+	next    amqp.Acknowledger
+	metrics *metrics
+	queue   string
+
+	mx      sync.Mutex
+	span    trace.Span
+	settled bool
+}
+
+func (a *acknowledger) Ack(tag uint64, multiple bool) error {
+	err := a.next.Ack(tag, multiple)
+	a.record(OutcomeAck, tag, err)
+
+	return err
+}
+
+func (a *acknowledger) Nack(tag uint64, multiple bool, requeue bool) error {
+	err := a.next.Nack(tag, multiple, requeue)
+	a.record(OutcomeNack, tag, err, Requeue.Bool(requeue))
+
+	return err
+}
+
+func (a *acknowledger) Reject(tag uint64, requeue bool) error {
+	err := a.next.Reject(tag, requeue)
+	a.record(OutcomeReject, tag, err, Requeue.Bool(requeue))
+
+	return err
+}
+
+func (a *acknowledger) record(outcome string, tag uint64, err error, attrs ...attribute.KeyValue) {
+	a.mx.Lock()
+	a.settled = true
+	span := a.span
+	a.mx.Unlock()
+
+	name := Acks
+
+	switch outcome {
+	case OutcomeNack:
+		name = Nacks
+	case OutcomeReject:
+		name = Rejects
+	}
+
+	a.metrics.counters[name].Add(context.Background(), 1,
+		metric.WithAttributes(append(attrs, Queue.String(a.queue), IsError.Bool(err != nil))...),
+	)
+
+	if span == nil {
+		return
+	}
+
+	attrs = append(attrs, DeliveryTag.Int64(int64(tag)))
+	if err != nil {
+		attrs = append(attrs, attribute.String("error.message", err.Error()))
+	}
+
+	span.AddEvent(outcome, trace.WithAttributes(attrs...))
+}
+
+func (a *acknowledger) setSpan(span trace.Span) {
+	a.mx.Lock()
+	a.span = span
+	a.mx.Unlock()
+}
+
+func (a *acknowledger) isSettled() bool {
+	a.mx.Lock()
+	defer a.mx.Unlock()
+
+	return a.settled
+}
+
+// acknowledgerOf consumed message, nil on publish
+func acknowledgerOf(msg *Message) *acknowledger {
+	if msg.Delivery == nil {
+		return nil
+	}
+
+	a, _ := msg.Delivery.Acknowledger.(*acknowledger)
+
+	return a
+}
+
+// RequeueFn reports if delivery failed with err should be requeued on nack
+type RequeueFn func(err error, d *amqp.Delivery) bool
+
+// AckOption configure Ack middleware
+type AckOption func(*Ack)
+
+// WithRequeuePolicy set policy which failed deliveries are requeued
+//
+// Default: deliveries are requeued once, redelivered ones are dropped or dead-lettered by broker
+func WithRequeuePolicy(fn RequeueFn) AckOption {
+	return func(a *Ack) {
+		a.requeue = fn
+	}
+}
+
+// Ack implementing Middleware: acks delivery when handler returned nil,
+// rejects it on permanent errors and nacks it otherwise.
+// Deliveries already settled by handler are passed as is, don't use it with auto-ack consumers.
+type Ack struct {
+	requeue RequeueFn
+}
+
+func NewAck(opts ...AckOption) *Ack {
+	a := &Ack{
+		requeue: func(_ error, d *amqp.Delivery) bool {
+			return !d.Redelivered
+		},
+	}
+
+	for _, o := range opts {
+		o(a)
+	}
+
+	return a
+}
+
+func (a *Ack) apply(next MsgHandler) MsgHandler {
+	return func(ctx context.Context, msg *Message) error {
+		err := next(ctx, msg)
+
+		d := msg.Delivery
+		if d == nil || d.Acknowledger == nil {
+			return err
+		}
+
+		// handler decided by itself
+		if ack := acknowledgerOf(msg); ack != nil && ack.isSettled() {
+			return err
+		}
+
+		var settleErr error
+
+		switch {
+		case err == nil:
+			settleErr = d.Ack(false)
+		case errors.Is(err, ErrPermanent):
+			settleErr = d.Reject(false)
+		default:
+			settleErr = d.Nack(false, a.requeue(err, d))
+		}
+
+		if err == nil {
+			return settleErr
+		}
+
+		return err
+	}
+}
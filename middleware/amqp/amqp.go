@@ -0,0 +1,176 @@
+// Package amqp instruments RabbitMQ consumers and publishers of github.com/rabbitmq/amqp091-go
+// with tel logs, traces and metrics. Deliveries are handled by Handler returning error
+// and both deliveries and publishings go through middleware chain.
+package amqp
+
+import (
+	"context"
+	"errors"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/tel-io/tel/v2"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrNacked is returned by Publisher when broker negatively confirmed publishing
+var ErrNacked = errors.New("publishing is nacked by broker")
+
+// Handler of consumed delivery
+type Handler func(ctx context.Context, d amqp.Delivery) error
+
+// Core keeps consumer and publisher middleware chains with shared instruments
+type Core struct {
+	*config
+
+	consumerInter  Interceptor
+	publisherInter Interceptor
+}
+
+// New middleware instance
+func New(opts ...Option) *Core {
+	cfg := newConfig(opts)
+
+	return &Core{
+		config:         cfg,
+		consumerInter:  MiddlewareChain(cfg.consumerMiddleware()...),
+		publisherInter: MiddlewareChain(cfg.publisherMiddleware()...),
+	}
+}
+
+// Handler wraps h with consumer middleware for deliveries of queue
+// Every delivery is handled with own copy of tel: from ctx if it's there, tel of Core otherwise.
+// Acknowledgements of delivery are metered per queue, even if they are done by handler.
+func (c *Core) Handler(queue string, h Handler) func(ctx context.Context, d amqp.Delivery) error {
+	in := c.consumerInter(func(ctx context.Context, msg *Message) error {
+		return h(ctx, *msg.Delivery)
+	})
+
+	return func(ctx context.Context, d amqp.Delivery) error {
+		t := c.tele
+		if v := tel.ContextValue(ctx); v != nil {
+			t = *v
+		}
+
+		if d.Acknowledger != nil {
+			d.Acknowledger = &acknowledger{next: d.Acknowledger, metrics: c.metrics, queue: queue}
+		}
+
+		msg := &Message{Exchange: d.Exchange, RoutingKey: d.RoutingKey, Queue: queue, Delivery: &d}
+
+		return in(WrapKindOfContext(tel.WrapContext(ctx, &t), KindConsume), msg)
+	}
+}
+
+// Consume handles deliveries of queue one by one until channel is closed or ctx is done.
+// Handler errors don't stop consuming: they are logged and settled by Ack middleware when it's enabled.
+func (c *Core) Consume(ctx context.Context, queue string, deliveries <-chan amqp.Delivery, h Handler) error {
+	handler := c.Handler(queue, h)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case d, ok := <-deliveries:
+			if !ok {
+				return nil
+			}
+
+			_ = handler(ctx, d)
+		}
+	}
+}
+
+// Channel publishes messages, *amqp.Channel implements it
+type Channel interface {
+	PublishWithDeferredConfirmWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool,
+		msg amqp.Publishing) (*amqp.DeferredConfirmation, error)
+}
+
+// confirmation of publishing, *amqp.DeferredConfirmation implements it
+type confirmation interface {
+	WaitContext(ctx context.Context) (bool, error)
+}
+
+type publishFn func(ctx context.Context, msg *Message) (confirmation, error)
+
+// Publisher publishes messages to channel with publisher middleware
+type Publisher struct {
+	handler MsgHandler
+}
+
+// Publisher of channel, put channel into confirm mode to get broker confirmations
+func (c *Core) Publisher(ch Channel) *Publisher {
+	return c.publisher(func(ctx context.Context, msg *Message) (confirmation, error) {
+		dc, err := ch.PublishWithDeferredConfirmWithContext(ctx, msg.Exchange, msg.RoutingKey,
+			msg.mandatory, msg.immediate, *msg.Publishing)
+		if dc == nil {
+			return nil, err
+		}
+
+		return dc, err
+	})
+}
+
+func (c *Core) publisher(publish publishFn) *Publisher {
+	return &Publisher{
+		handler: c.publisherInter(func(ctx context.Context, msg *Message) error {
+			conf, err := publish(ctx, msg)
+			if err != nil || conf == nil || !c.waitConfirm {
+				return err
+			}
+
+			return c.confirm(ctx, msg, conf)
+		}),
+	}
+}
+
+// confirm waits for broker confirmation and records it
+func (c *Core) confirm(ctx context.Context, msg *Message, conf confirmation) error {
+	ack, err := conf.WaitContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.metrics.counters[PublishConfirms].Add(ctx, 1,
+		metric.WithAttributes(Exchange.String(msg.Exchange), Acked.Bool(ack)),
+	)
+
+	opts := []trace.EventOption{trace.WithAttributes(Acked.Bool(ack))}
+	if dc, ok := conf.(*amqp.DeferredConfirmation); ok {
+		opts = append(opts, trace.WithAttributes(DeliveryTag.Int64(int64(dc.DeliveryTag))))
+	}
+
+	trace.SpanFromContext(ctx).AddEvent(EventPublishConfirm, opts...)
+
+	if !ack {
+		return ErrNacked
+	}
+
+	return nil
+}
+
+// Publish message with publisher middleware, trace context is injected into msg headers.
+// When channel is in confirm mode it returns after broker confirmation, see WithPublishConfirm.
+func (p *Publisher) Publish(ctx context.Context, exchange, key string, mandatory, immediate bool,
+	msg amqp.Publishing) error {
+	m := &Message{
+		Exchange:   exchange,
+		RoutingKey: key,
+		Publishing: &msg,
+		mandatory:  mandatory,
+		immediate:  immediate,
+	}
+
+	// headers are injected into copy of table
+	if msg.Headers != nil {
+		headers := make(amqp.Table, len(msg.Headers))
+		for k, v := range msg.Headers {
+			headers[k] = v
+		}
+
+		msg.Headers = headers
+	}
+
+	return p.handler(WrapKindOfContext(ctx, KindPublish), m)
+}
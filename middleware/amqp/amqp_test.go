@@ -0,0 +1,323 @@
+package amqp
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/suite"
+	"github.com/tel-io/tel/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+const queue = "orders.billing"
+
+type settle struct {
+	outcome string
+	requeue bool
+}
+
+// fakeAcknowledger records settlements of delivery
+type fakeAcknowledger struct {
+	mx   sync.Mutex
+	list []settle
+}
+
+func (f *fakeAcknowledger) add(s settle) error {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	f.list = append(f.list, s)
+
+	return nil
+}
+
+func (f *fakeAcknowledger) Ack(uint64, bool) error { return f.add(settle{outcome: OutcomeAck}) }
+
+func (f *fakeAcknowledger) Nack(_ uint64, _ bool, requeue bool) error {
+	return f.add(settle{outcome: OutcomeNack, requeue: requeue})
+}
+
+func (f *fakeAcknowledger) Reject(_ uint64, requeue bool) error {
+	return f.add(settle{outcome: OutcomeReject, requeue: requeue})
+}
+
+type fakeConfirmation bool
+
+func (f fakeConfirmation) WaitContext(context.Context) (bool, error) { return bool(f), nil }
+
+type Suite struct {
+	suite.Suite
+
+	core   *Core
+	spans  *tracetest.SpanRecorder
+	reader *sdkmetric.ManualReader
+	logs   *observer.ObservedLogs
+}
+
+func (s *Suite) SetupTest() {
+	s.setup()
+}
+
+func TestAMQP(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+// setup new core with opts
+func (s *Suite) setup(opts ...Option) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	tele := tel.NewNull()
+	tele.Logger = zap.New(core)
+
+	s.spans = tracetest.NewSpanRecorder()
+	s.reader = sdkmetric.NewManualReader()
+	s.logs = logs
+
+	s.core = New(append([]Option{
+		WithTel(tele),
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(s.spans))),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(s.reader))),
+		WithPropagators(propagation.TraceContext{}),
+	}, opts...)...)
+}
+
+// publisher which delivers publishings to returned channel and confirms them with ack
+func (s *Suite) publisher(ack bool, ackr amqp.Acknowledger) (*Publisher, chan amqp.Delivery) {
+	deliveries := make(chan amqp.Delivery, 10)
+
+	p := s.core.publisher(func(ctx context.Context, msg *Message) (confirmation, error) {
+		deliveries <- amqp.Delivery{
+			Acknowledger: ackr,
+			Headers:      msg.Publishing.Headers,
+			Exchange:     msg.Exchange,
+			RoutingKey:   msg.RoutingKey,
+			Body:         msg.Publishing.Body,
+			DeliveryTag:  uint64(len(deliveries) + 1),
+		}
+
+		return fakeConfirmation(ack), nil
+	})
+
+	return p, deliveries
+}
+
+func (s *Suite) sum(name string, attrs ...attribute.KeyValue) int64 {
+	var rm metricdata.ResourceMetrics
+	s.Require().NoError(s.reader.Collect(context.Background(), &rm))
+
+	var res int64
+
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			data, ok := m.Data.(metricdata.Sum[int64])
+			if m.Name != name || !ok {
+				continue
+			}
+
+			for _, dp := range data.DataPoints {
+				if hasAttrs(dp.Attributes, attrs) {
+					res += dp.Value
+				}
+			}
+		}
+	}
+
+	return res
+}
+
+func hasAttrs(set attribute.Set, attrs []attribute.KeyValue) bool {
+	for _, kv := range attrs {
+		if v, ok := set.Value(kv.Key); !ok || v != kv.Value {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *Suite) span(name string) sdktrace.ReadOnlySpan {
+	for _, span := range s.spans.Ended() {
+		if span.Name() == name {
+			return span
+		}
+	}
+
+	s.Require().Failf("span not found", name)
+
+	return nil
+}
+
+func events(span sdktrace.ReadOnlySpan) []string {
+	var res []string
+	for _, e := range span.Events() {
+		res = append(res, e.Name)
+	}
+
+	return res
+}
+
+func (s *Suite) TestPublishConsume() {
+	s.setup(WithAck())
+	ctx := context.Background()
+	ackr := &fakeAcknowledger{}
+
+	p, deliveries := s.publisher(true, ackr)
+
+	headers := amqp.Table{"tenant": "acme"}
+	s.Require().NoError(p.Publish(ctx, "orders", "orders.new", false, false,
+		amqp.Publishing{Headers: headers, Body: []byte("{}")},
+	))
+	s.Len(headers, 1, "headers of caller are not modified")
+
+	close(deliveries)
+
+	var got amqp.Delivery
+
+	s.Require().NoError(s.core.Consume(ctx, queue, deliveries, func(ctx context.Context, d amqp.Delivery) error {
+		s.True(trace.SpanContextFromContext(ctx).IsValid())
+		got = d
+
+		return nil
+	}))
+
+	s.Equal("acme", got.Headers["tenant"])
+	s.Equal([]settle{{outcome: OutcomeAck}}, ackr.list)
+
+	pub := s.span("AMQP:PUBLISH/orders")
+	s.Equal(trace.SpanKindProducer, pub.SpanKind())
+	s.Equal([]string{EventPublishConfirm}, events(pub))
+
+	sub := s.span("AMQP:CONSUME/" + queue)
+	s.Equal(pub.SpanContext().SpanID(), sub.Parent().SpanID())
+	s.Equal([]string{OutcomeAck}, events(sub))
+
+	s.Equal(int64(1), s.sum(Acks, Queue.String(queue)))
+	s.Equal(int64(1), s.sum(PublishConfirms, Exchange.String("orders"), Acked.Bool(true)))
+	s.Equal(int64(1), s.sum(Count, Kind.String(KindConsume), Queue.String(queue)))
+	s.Equal(int64(1), s.sum(Count, Kind.String(KindPublish)))
+}
+
+func (s *Suite) TestPublish_Nacked() {
+	p, _ := s.publisher(false, nil)
+
+	err := p.Publish(context.Background(), "orders", "orders.new", false, false, amqp.Publishing{})
+	s.Require().ErrorIs(err, ErrNacked)
+
+	span := s.span("AMQP:PUBLISH/orders")
+	s.Equal(codes.Error, span.Status().Code)
+	s.Equal(int64(1), s.sum(PublishConfirms, Acked.Bool(false)))
+
+	s.Run("without confirm", func() {
+		s.setup(WithPublishConfirm(false))
+		p, _ := s.publisher(false, nil)
+
+		s.Require().NoError(p.Publish(context.Background(), "orders", "orders.new", false, false, amqp.Publishing{}))
+		s.Empty(events(s.span("AMQP:PUBLISH/orders")))
+	})
+}
+
+func (s *Suite) TestAck() {
+	errTest := errors.New("test")
+
+	tests := []struct {
+		name        string
+		redelivered bool
+		err         error
+		want        settle
+		metric      string
+	}{
+		{name: "ack", want: settle{outcome: OutcomeAck}, metric: Acks},
+		{name: "nack", err: errTest, want: settle{outcome: OutcomeNack, requeue: true}, metric: Nacks},
+		{name: "nack redelivered", redelivered: true, err: errTest, want: settle{outcome: OutcomeNack}, metric: Nacks},
+		{name: "reject", err: Permanent(errTest), want: settle{outcome: OutcomeReject}, metric: Rejects},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			s.setup(WithAck())
+			ackr := &fakeAcknowledger{}
+
+			err := s.core.Handler(queue, func(ctx context.Context, d amqp.Delivery) error {
+				return tt.err
+			})(context.Background(), amqp.Delivery{Acknowledger: ackr, Redelivered: tt.redelivered})
+
+			s.ErrorIs(err, tt.err)
+			s.Equal([]settle{tt.want}, ackr.list)
+			s.Equal(int64(1), s.sum(tt.metric, Queue.String(queue)))
+		})
+	}
+}
+
+func (s *Suite) TestAck_Handler() {
+	s.Run("settled by handler", func() {
+		s.setup(WithAck())
+		ackr := &fakeAcknowledger{}
+
+		err := s.core.Handler(queue, func(ctx context.Context, d amqp.Delivery) error {
+			s.Require().NoError(d.Reject(true))
+
+			return errors.New("test")
+		})(context.Background(), amqp.Delivery{Acknowledger: ackr})
+
+		s.Error(err)
+		s.Equal([]settle{{outcome: OutcomeReject, requeue: true}}, ackr.list)
+		s.Equal(int64(1), s.sum(Rejects, Queue.String(queue), Requeue.Bool(true)))
+	})
+
+	s.Run("panic", func() {
+		s.setup(WithAck())
+		ackr := &fakeAcknowledger{}
+
+		err := s.core.Handler(queue, func(ctx context.Context, d amqp.Delivery) error {
+			panic("test")
+		})(context.Background(), amqp.Delivery{Acknowledger: ackr})
+
+		s.Error(err)
+		s.Equal([]settle{{outcome: OutcomeNack, requeue: true}}, ackr.list)
+	})
+
+	s.Run("disabled", func() {
+		s.setup()
+		ackr := &fakeAcknowledger{}
+
+		err := s.core.Handler(queue, func(ctx context.Context, d amqp.Delivery) error {
+			return nil
+		})(context.Background(), amqp.Delivery{Acknowledger: ackr})
+
+		s.NoError(err)
+		s.Empty(ackr.list)
+	})
+}
+
+func (s *Suite) TestLogs() {
+	_ = s.core.Handler(queue, func(ctx context.Context, d amqp.Delivery) error {
+		return errors.New("test")
+	})(context.Background(), amqp.Delivery{Exchange: "orders", Body: []byte("payload")})
+
+	entries := s.logs.FilterMessage("AMQP:CONSUME/" + queue).All()
+	s.Require().Len(entries, 1)
+	s.Equal(zapcore.ErrorLevel, entries[0].Level)
+	s.Equal("payload", entries[0].ContextMap()[PayloadKey])
+	s.Equal(queue, entries[0].ContextMap()[string(Queue)])
+}
+
+func (s *Suite) TestTableCarrier() {
+	c := TableCarrier{"a": "1", "b": []byte("2"), "c": int32(3)}
+
+	s.Equal("1", c.Get("a"))
+	s.Equal("2", c.Get("b"))
+	s.Equal("", c.Get("c"))
+	s.ElementsMatch([]string{"a", "b", "c"}, c.Keys())
+}
@@ -0,0 +1,63 @@
+package amqp
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// ExtractAttributes for logs: exchange, routing key, kind, queue and delivery tag of consumed message
+func ExtractAttributes(msg *Message, kind string) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		Exchange.String(msg.Exchange),
+		RoutingKey.String(msg.RoutingKey),
+		Kind.String(kind),
+	}
+
+	if msg.Queue != "" {
+		attrs = append(attrs, Queue.String(msg.Queue))
+	}
+
+	if msg.Delivery != nil {
+		attrs = append(attrs, DeliveryTag.Int64(int64(msg.Delivery.DeliveryTag)))
+	}
+
+	return attrs
+}
+
+// SpanAttributes messaging semantic conventions attributes of message and kind
+func SpanAttributes(msg *Message, kind string) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemRabbitmq,
+		semconv.MessagingDestinationName(msg.Exchange),
+		semconv.MessagingMessageBodySize(len(msg.Body())),
+		Kind.String(kind),
+	}
+
+	if msg.RoutingKey != "" {
+		attrs = append(attrs, semconv.MessagingRabbitmqDestinationRoutingKey(msg.RoutingKey))
+	}
+
+	if kind == KindPublish {
+		attrs = append(attrs, semconv.MessagingOperationTypePublish, semconv.MessagingOperationName("publish"))
+	} else {
+		attrs = append(attrs, semconv.MessagingOperationTypeDeliver, semconv.MessagingOperationName("process"))
+	}
+
+	if msg.Queue != "" {
+		attrs = append(attrs, Queue.String(msg.Queue))
+	}
+
+	if id := msg.MessageID(); id != "" {
+		attrs = append(attrs, semconv.MessagingMessageID(id))
+	}
+
+	if id := msg.CorrelationID(); id != "" {
+		attrs = append(attrs, semconv.MessagingMessageConversationID(id))
+	}
+
+	if msg.Delivery != nil {
+		attrs = append(attrs, semconv.MessagingRabbitmqMessageDeliveryTag(int(msg.Delivery.DeliveryTag)))
+	}
+
+	return attrs
+}
@@ -0,0 +1,53 @@
+package amqp
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	KindKey    = "kind_of"
+	PayloadKey = "payload"
+)
+
+// Attribute keys that can be added to a span, log or metric.
+const (
+	Exchange    = attribute.Key("exchange")
+	RoutingKey  = attribute.Key("routing_key")
+	Queue       = attribute.Key("queue")
+	DeliveryTag = attribute.Key("delivery_tag")
+	Requeue     = attribute.Key("requeue")
+	Acked       = attribute.Key("ack")
+	IsError     = attribute.Key("error")
+	Kind        = attribute.Key(KindKey)
+	Duration    = attribute.Key("duration")
+)
+
+const (
+	KindUnk     = "UNK"
+	KindConsume = "CONSUME"
+	KindPublish = "PUBLISH"
+)
+
+// Outcomes of delivery, they are span events of consumer span
+const (
+	OutcomeAck    = "ack"
+	OutcomeNack   = "nack"
+	OutcomeReject = "reject"
+)
+
+// EventPublishConfirm is span event of publisher span with broker confirmation
+const EventPublishConfirm = "publish.confirm"
+
+// AMQP metrics
+const (
+	Count         = "amqp.count"          // Consumed and published messages total
+	ContentLength = "amqp.content_length" // Consumed and published bytes of message bodies total
+	Latency       = "amqp.duration"       // Handler or publish duration, milliseconds
+	EndToEnd      = "amqp.e2e.duration"   // Time since message timestamp till handler start, milliseconds
+
+	Acks    = "amqp.ack.count"    // Acknowledged deliveries by queue
+	Nacks   = "amqp.nack.count"   // Negatively acknowledged deliveries by queue and requeue
+	Rejects = "amqp.reject.count" // Rejected deliveries by queue and requeue
+
+	PublishConfirms = "amqp.publish.confirm.count" // Broker confirmations of publishings by exchange and ack
+)
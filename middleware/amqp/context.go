@@ -0,0 +1,22 @@
+package amqp
+
+import (
+	"context"
+)
+
+type kindKey struct{}
+
+// WrapKindOfContext put kind of message operation into ctx: KindConsume for deliveries, KindPublish for publishings.
+// Tracer extracts trace context from headers table of deliveries and injects it into publishings by it.
+func WrapKindOfContext(ctx context.Context, kindOf string) context.Context {
+	return context.WithValue(ctx, kindKey{}, kindOf)
+}
+
+// KindOfContext returns kind put by WrapKindOfContext or KindUnk
+func KindOfContext(ctx context.Context) string {
+	if v, ok := ctx.Value(kindKey{}).(string); ok {
+		return v
+	}
+
+	return KindUnk
+}
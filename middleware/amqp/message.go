@@ -0,0 +1,112 @@
+package amqp
+
+import (
+	"context"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Message is delivery or publishing viewed by middleware
+type Message struct {
+	Exchange   string
+	RoutingKey string
+	// Queue of consumer, empty on publish
+	Queue string
+
+	// Delivery is consumed message, nil on publish
+	Delivery *amqp.Delivery
+	// Publishing is published message, nil on consume
+	Publishing *amqp.Publishing
+
+	mandatory bool
+	immediate bool
+}
+
+// Headers of delivery or publishing
+func (m *Message) Headers() amqp.Table {
+	if m.Delivery != nil {
+		return m.Delivery.Headers
+	}
+
+	return m.Publishing.Headers
+}
+
+// Body of delivery or publishing
+func (m *Message) Body() []byte {
+	if m.Delivery != nil {
+		return m.Delivery.Body
+	}
+
+	return m.Publishing.Body
+}
+
+// Timestamp of delivery or publishing, zero if it isn't set
+func (m *Message) Timestamp() time.Time {
+	if m.Delivery != nil {
+		return m.Delivery.Timestamp
+	}
+
+	return m.Publishing.Timestamp
+}
+
+// MessageID of delivery or publishing
+func (m *Message) MessageID() string {
+	if m.Delivery != nil {
+		return m.Delivery.MessageId
+	}
+
+	return m.Publishing.MessageId
+}
+
+// CorrelationID of delivery or publishing
+func (m *Message) CorrelationID() string {
+	if m.Delivery != nil {
+		return m.Delivery.CorrelationId
+	}
+
+	return m.Publishing.CorrelationId
+}
+
+// carrier of headers, publishing gets headers table if it has none
+func (m *Message) carrier() TableCarrier {
+	if m.Publishing != nil && m.Publishing.Headers == nil {
+		m.Publishing.Headers = amqp.Table{}
+	}
+
+	return TableCarrier(m.Headers())
+}
+
+// MsgHandler handles delivery or publishing viewed as Message: ctx carries tel and span of the message,
+// error of delivery handler is settled by Ack middleware, error of publishing is returned by Publisher
+type MsgHandler func(ctx context.Context, msg *Message) error
+
+// TableCarrier adapts amqp.Table headers to propagation.TextMapCarrier
+type TableCarrier amqp.Table
+
+// Get returns the value associated with the passed key.
+func (c TableCarrier) Get(key string) string {
+	switch v := c[key].(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return ""
+	}
+}
+
+// Set stores the key-value pair.
+func (c TableCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+// Keys lists the keys stored in this carrier.
+func (c TableCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
@@ -0,0 +1,89 @@
+package amqp
+
+import (
+	"context"
+	"time"
+
+	"github.com/tel-io/tel/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+type metrics struct {
+	counters       map[string]metric.Int64Counter
+	valueRecorders map[string]metric.Float64Histogram
+}
+
+func createMeasures(tele tel.Telemetry, meter metric.Meter) *metrics {
+	counters := make(map[string]metric.Int64Counter)
+	valueRecorders := make(map[string]metric.Float64Histogram)
+
+	for _, name := range []string{Count, ContentLength, Acks, Nacks, Rejects, PublishConfirms} {
+		c, err := meter.Int64Counter(name)
+		if err != nil {
+			tele.Panic("amqp mw", tel.String("key", name))
+		}
+
+		counters[name] = c
+	}
+
+	for _, name := range []string{Latency, EndToEnd} {
+		h, err := meter.Float64Histogram(name, metric.WithUnit("ms"))
+		if err != nil {
+			tele.Panic("amqp mw", tel.String("key", name))
+		}
+
+		valueRecorders[name] = h
+	}
+
+	return &metrics{
+		counters:       counters,
+		valueRecorders: valueRecorders,
+	}
+}
+
+// Metrics implement Middleware interface
+type Metrics struct {
+	*metrics
+}
+
+func NewMetrics(m *metrics) *Metrics {
+	return &Metrics{metrics: m}
+}
+
+func (t *Metrics) apply(next MsgHandler) MsgHandler {
+	return func(ctx context.Context, msg *Message) (err error) {
+		kind := KindOfContext(ctx)
+
+		defer func(start time.Time) {
+			if ctx.Err() != nil {
+				err = ctx.Err()
+				ctx = tel.FromCtx(ctx).Ctx()
+			}
+
+			attr := []attribute.KeyValue{
+				IsError.Bool(err != nil),
+				Exchange.String(msg.Exchange),
+				Kind.String(kind),
+			}
+
+			if msg.Queue != "" {
+				attr = append(attr, Queue.String(msg.Queue))
+			}
+
+			t.counters[Count].Add(ctx, 1, metric.WithAttributes(attr...))
+			t.counters[ContentLength].Add(ctx, int64(len(msg.Body())), metric.WithAttributes(attr...))
+			t.valueRecorders[Latency].Record(ctx, float64(time.Since(start).Milliseconds()),
+				metric.WithAttributes(attr...),
+			)
+		}(time.Now())
+
+		if kind == KindConsume && !msg.Timestamp().IsZero() {
+			t.valueRecorders[EndToEnd].Record(ctx, float64(time.Since(msg.Timestamp()).Milliseconds()),
+				metric.WithAttributes(Queue.String(msg.Queue)),
+			)
+		}
+
+		return next(ctx, msg)
+	}
+}
@@ -0,0 +1,31 @@
+package amqp
+
+// Middleware decorates MsgHandler of delivery or publishing
+type Middleware interface {
+	apply(next MsgHandler) MsgHandler
+}
+
+// Interceptor wraps MsgHandler, Core keeps one for consumer and one for publisher middleware
+type Interceptor func(next MsgHandler) MsgHandler
+
+// MiddlewareChain - MsgHandler decorator with middleware, the last one is the outermost
+func MiddlewareChain(mw ...Middleware) Interceptor {
+	return func(next MsgHandler) MsgHandler {
+		for _, m := range mw {
+			next = m.apply(next)
+		}
+
+		return next
+	}
+}
+
+// ChainInterceptor combines interceptors, the last one is the outermost
+func ChainInterceptor(interceptors ...Interceptor) Interceptor {
+	return func(next MsgHandler) MsgHandler {
+		for _, mw := range interceptors {
+			next = mw(next)
+		}
+
+		return next
+	}
+}
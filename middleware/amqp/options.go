@@ -0,0 +1,247 @@
+package amqp
+
+import (
+	"strings"
+
+	"github.com/tel-io/tel/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Option allows configuration of the amqp middleware
+type Option interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (o optionFunc) apply(c *config) {
+	o(c)
+}
+
+// NameFn names spans and log entries of deliveries and publishings
+type NameFn func(kind string, msg *Message) string
+
+// defaultOperationFn default name convention: AMQP:CONSUME/queue, AMQP:PUBLISH/exchange
+func defaultOperationFn(kind string, msg *Message) string {
+	var b strings.Builder
+
+	b.WriteString("AMQP:")
+	b.WriteString(kind)
+	b.WriteByte('/')
+
+	if kind == KindPublish {
+		b.WriteString(msg.Exchange)
+	} else {
+		b.WriteString(msg.Queue)
+	}
+
+	return b.String()
+}
+
+type config struct {
+	tele    tel.Telemetry
+	meter   metric.Meter
+	metrics *metrics
+
+	// set by WithTracerProvider and WithMeterProvider, nil means providers of tele
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	tracer         trace.Tracer
+
+	propagators propagation.TextMapPropagator
+
+	dump               bool
+	dumpPayloadOnError bool
+	waitConfirm        bool
+
+	nameFn NameFn
+
+	// Ack middleware, nil if disabled
+	ackOpts []AckOption
+
+	// middleware processors
+	notUserDefaultMW bool
+	consumerList     []Middleware
+	publisherList    []Middleware
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{
+		tele:               tel.Global(),
+		propagators:        otel.GetTextMapPropagator(),
+		dumpPayloadOnError: true,
+		waitConfirm:        true,
+		nameFn:             defaultOperationFn,
+	}
+
+	for _, o := range opts {
+		o.apply(c)
+	}
+
+	if c.meterProvider != nil {
+		c.meter = c.meterProvider.Meter(
+			instrumentationName,
+			metric.WithInstrumentationVersion(SemVersion()),
+		)
+	} else {
+		c.meter = c.tele.Meter(
+			instrumentationName,
+			metric.WithInstrumentationVersion(SemVersion()),
+		)
+	}
+
+	if c.tracerProvider != nil {
+		c.tracer = c.tracerProvider.Tracer(
+			instrumentationName,
+			trace.WithInstrumentationVersion(SemVersion()),
+		)
+	}
+
+	c.metrics = createMeasures(c.tele, c.meter)
+
+	return c
+}
+
+// DefaultMiddleware recovery, logs, tracer and metrics, the last is the outermost
+func (c *config) DefaultMiddleware() []Middleware {
+	return []Middleware{
+		NewRecovery(),
+		NewLogs(c.nameFn, c.dumpPayloadOnError, c.dump),
+		&Tracer{
+			tracer:      c.tracer,
+			nameFn:      c.nameFn,
+			propagators: c.propagators,
+		},
+		NewMetrics(c.metrics),
+	}
+}
+
+func (c *config) consumerMiddleware() []Middleware {
+	if c.notUserDefaultMW {
+		return c.withAck(c.consumerList, 0)
+	}
+
+	// inside tracer to record outcome as span event, outside of recovery to nack on panic
+	return append(c.withAck(c.DefaultMiddleware(), 1), c.consumerList...)
+}
+
+// withAck insert Ack into list at pos when it's enabled
+func (c *config) withAck(list []Middleware, pos int) []Middleware {
+	if c.ackOpts == nil {
+		return list
+	}
+
+	res := make([]Middleware, 0, len(list)+1)
+	res = append(res, list[:pos]...)
+	res = append(res, NewAck(c.ackOpts...))
+
+	return append(res, list[pos:]...)
+}
+
+func (c *config) publisherMiddleware() []Middleware {
+	if c.notUserDefaultMW {
+		return c.publisherList
+	}
+
+	return append(c.DefaultMiddleware(), c.publisherList...)
+}
+
+// WithTel used for deliveries handled without tel in ctx, its meter and tracer are used unless providers are overridden
+//
+// Default: tel.Global()
+func WithTel(t tel.Telemetry) Option {
+	return optionFunc(func(c *config) {
+		c.tele = t
+	})
+}
+
+// WithTracerProvider start consumer and publisher spans with tp instead of tel tracer provider
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return optionFunc(func(c *config) {
+		c.tracerProvider = tp
+	})
+}
+
+// WithMeterProvider create delivery, acknowledgement and publish instruments with mp instead of tel meter provider
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return optionFunc(func(c *config) {
+		c.meterProvider = mp
+	})
+}
+
+// WithPropagators of trace context and baggage via headers table
+//
+// Default: otel.GetTextMapPropagator()
+func WithPropagators(p propagation.TextMapPropagator) Option {
+	return optionFunc(func(c *config) {
+		if p != nil {
+			c.propagators = p
+		}
+	})
+}
+
+// WithDump dump message body as plain text to log
+func WithDump(enable bool) Option {
+	return optionFunc(func(c *config) {
+		c.dump = enable
+	})
+}
+
+// WithDumpPayloadOnError write dump of message body on faults
+//
+// Default: true
+func WithDumpPayloadOnError(enable bool) Option {
+	return optionFunc(func(c *config) {
+		c.dumpPayloadOnError = enable
+	})
+}
+
+// WithPublishConfirm wait for broker confirmation when channel is in confirm mode,
+// confirmation is recorded as span event and nack is returned as ErrNacked.
+// Disabled publisher returns as soon as message is sent.
+//
+// Default: true
+func WithPublishConfirm(enable bool) Option {
+	return optionFunc(func(c *config) {
+		c.waitConfirm = enable
+	})
+}
+
+func WithNameFunction(fn NameFn) Option {
+	return optionFunc(func(c *config) {
+		c.nameFn = fn
+	})
+}
+
+// WithAck enable Ack middleware: deliveries are acked when handler returned nil,
+// nacked on error and rejected on permanent errors.
+// With WithDisableDefaultMiddleware it's the innermost middleware of consumer chain.
+func WithAck(opts ...AckOption) Option {
+	return optionFunc(func(c *config) {
+		c.ackOpts = append(make([]AckOption, 0, len(opts)), opts...)
+	})
+}
+
+// WithConsumerMiddleware for consumer handlers
+func WithConsumerMiddleware(list ...Middleware) Option {
+	return optionFunc(func(c *config) {
+		c.consumerList = append(c.consumerList, list...)
+	})
+}
+
+// WithPublisherMiddleware for publishers
+func WithPublisherMiddleware(list ...Middleware) Option {
+	return optionFunc(func(c *config) {
+		c.publisherList = append(c.publisherList, list...)
+	})
+}
+
+// WithDisableDefaultMiddleware leaves only middleware of WithConsumerMiddleware, WithPublisherMiddleware and WithAck
+func WithDisableDefaultMiddleware() Option {
+	return optionFunc(func(c *config) {
+		c.notUserDefaultMW = true
+	})
+}
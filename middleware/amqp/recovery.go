@@ -0,0 +1,23 @@
+package amqp
+
+import (
+	"context"
+
+	"github.com/tel-io/instrumentation/module/telmsg"
+)
+
+// Recovery turns panic of delivery handler into error, so Consume keeps reading deliveries of the channel.
+// Ack middleware enabled by WithAck wraps Recovery and nacks such delivery instead of leaving it unacknowledged.
+type Recovery struct{}
+
+func NewRecovery() *Recovery {
+	return &Recovery{}
+}
+
+func (t *Recovery) apply(next MsgHandler) MsgHandler {
+	return func(ctx context.Context, msg *Message) (err error) {
+		defer telmsg.Recover(ctx, &err)
+
+		return next(ctx, msg)
+	}
+}
@@ -0,0 +1,66 @@
+package amqp
+
+import (
+	"context"
+
+	"github.com/tel-io/instrumentation/module/telmsg"
+	"github.com/tel-io/tel/v2"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracer starts span per message: publisher span injects trace context into headers table of publishing,
+// consumer span is child of span extracted from headers of delivery. Ack, nack and reject of delivery
+// and publisher confirmation are recorded as events of the span.
+type Tracer struct {
+	// set by WithTracerProvider, nil means tel tracer
+	tracer trace.Tracer
+
+	nameFn      NameFn
+	propagators propagation.TextMapPropagator
+}
+
+func NewTracer(fn NameFn, propagators propagation.TextMapPropagator) *Tracer {
+	return &Tracer{nameFn: fn, propagators: propagators}
+}
+
+func (t *Tracer) apply(next MsgHandler) MsgHandler {
+	return func(ctx context.Context, msg *Message) error {
+		var (
+			kind    = KindOfContext(ctx)
+			carrier = msg.carrier()
+		)
+
+		spanKind := trace.SpanKindConsumer
+		if kind == KindPublish {
+			spanKind = trace.SpanKindProducer
+		} else {
+			// baggage and remote span of publisher
+			ctx = t.propagators.Extract(ctx, carrier)
+		}
+
+		span, ctx := telmsg.StartSpan(ctx, t.tracer, t.nameFn(kind, msg),
+			trace.WithSpanKind(spanKind),
+			trace.WithAttributes(SpanAttributes(msg, kind)...),
+		)
+		defer span.End()
+
+		tel.FromCtx(ctx).PutAttr(Exchange.String(msg.Exchange), Kind.String(kind))
+		tel.UpdateTraceFields(ctx)
+
+		if kind == KindPublish {
+			t.propagators.Inject(ctx, carrier)
+		}
+
+		// outcome of delivery is recorded as span event
+		if a := acknowledgerOf(msg); a != nil {
+			a.setSpan(span)
+			defer a.setSpan(nil)
+		}
+
+		err := next(ctx, msg)
+		telmsg.SetStatus(span, err)
+
+		return err
+	}
+}
@@ -26,6 +26,7 @@ require (
 	github.com/power-devops/perfstat v0.0.0-20240221224432-82ca36839d55 // indirect
 	github.com/shirou/gopsutil/v4 v4.24.6 // indirect
 	github.com/shoenig/go-m1cpu v0.1.6 // indirect
+	github.com/tel-io/instrumentation/module/telfields v1.0.0 // indirect
 	github.com/tel-io/tel/v2 v2.3.6 // indirect
 	github.com/tklauser/go-sysconf v0.3.14 // indirect
 	github.com/tklauser/numcpus v0.8.0 // indirect
@@ -55,3 +56,5 @@ require (
 )
 
 replace github.com/tel-io/instrumentation/middleware/http => ../../middleware/http
+
+replace github.com/tel-io/instrumentation/module/telfields => ../../module/telfields
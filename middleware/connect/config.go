@@ -0,0 +1,90 @@
+package connect
+
+import (
+	"github.com/tel-io/tel/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/tel-io/instrumentation/middleware/connect"
+
+type config struct {
+	log *tel.Telemetry
+
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	propagators    propagation.TextMapPropagator
+
+	// ignore procedure list
+	ignore []string
+
+	// trustRemote start server span as child of remote span even when ctx already holds a span
+	trustRemote bool
+}
+
+// Option interface used for setting optional config properties.
+type Option interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (o optionFunc) apply(c *config) {
+	o(c)
+}
+
+// newConfig creates a new config struct and applies opts to it.
+func newConfig(opts ...Option) *config {
+	l := tel.Global()
+
+	c := &config{
+		log:            &l,
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+		propagators:    otel.GetTextMapPropagator(),
+	}
+
+	for _, opt := range opts {
+		opt.apply(c)
+	}
+
+	return c
+}
+
+// WithTel also add options to pass own metric and trace provider
+func WithTel(t *tel.Telemetry) Option {
+	return optionFunc(func(c *config) {
+		c.log = t
+		c.tracerProvider = t.TracerProvider()
+		c.meterProvider = t.MetricProvider()
+	})
+}
+
+// WithIgnoreList skip debug log for listed procedures, e.g. "/grpc.health.v1.Health/Check"
+// Errors and recovery are still logged
+func WithIgnoreList(ignore []string) Option {
+	return optionFunc(func(c *config) {
+		c.ignore = append(c.ignore, ignore...)
+	})
+}
+
+// WithPropagators sets the propagators used for trace context injection and extraction
+func WithPropagators(props propagation.TextMapPropagator) Option {
+	return optionFunc(func(c *config) {
+		if props != nil {
+			c.propagators = props
+		}
+	})
+}
+
+// WithTrustRemote makes server span a child of the span taken from request headers
+// even if handler context already contains span, for example from HTTP middleware.
+//
+// Default: false, connect span nests under HTTP span
+func WithTrustRemote(enable bool) Option {
+	return optionFunc(func(c *config) {
+		c.trustRemote = enable
+	})
+}
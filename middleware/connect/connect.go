@@ -0,0 +1,419 @@
+package connect
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/tel-io/tel/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+// Interceptor implements connect.Interceptor for both handlers and clients
+// Per call it performs:
+//   - trace context extraction/injection + span
+//   - new telemetry instance in ctx (server side)
+//   - recovery
+//   - detail log during errors (+ in recovery also)
+//   - started/handled metrics and execution time
+type Interceptor struct {
+	c *config
+
+	tracer trace.Tracer
+	server *metrics
+	client *metrics
+}
+
+var _ connect.Interceptor = (*Interceptor)(nil)
+
+// NewInterceptor returns interceptor which should be passed via connect.WithInterceptors
+// to both handler and client constructors
+func NewInterceptor(opts ...Option) *Interceptor {
+	c := newConfig(opts...)
+	meter := c.meterProvider.Meter(instrumentationName)
+
+	return &Interceptor{
+		c:      c,
+		tracer: c.tracerProvider.Tracer(instrumentationName),
+		server: newServerMetrics(meter),
+		client: newClientMetrics(meter),
+	}
+}
+
+// WrapUnary implements connect.Interceptor
+func (i *Interceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if req.Spec().IsClient {
+			return i.unaryClient(ctx, req, next)
+		}
+
+		return i.unaryServer(ctx, req, next)
+	}
+}
+
+// WrapStreamingClient implements connect.Interceptor
+// Span and metrics are finished when response is closed
+func (i *Interceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return func(ctx context.Context, spec connect.Spec) connect.StreamingClientConn {
+		ctx, span := i.tracer.Start(ctx, spanName(spec.Procedure),
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(rpcAttributes(spec.Procedure)...),
+		)
+
+		conn := next(ctx, spec)
+		i.c.propagators.Inject(ctx, propagation.HeaderCarrier(conn.RequestHeader()))
+
+		return &streamingClientConn{
+			StreamingClientConn: conn,
+			ctx:                 ctx,
+			span:                span,
+			reporter:            i.client.start(ctx, spec),
+			skip:                isSkip(i.c.ignore, spec.Procedure),
+		}
+	}
+}
+
+// WrapStreamingHandler implements connect.Interceptor
+func (i *Interceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(root context.Context, conn connect.StreamingHandlerConn) (err error) {
+		spec := conn.Spec()
+
+		ctx, span := i.startServerSpan(root, spec.Procedure, conn.RequestHeader())
+		defer span.End()
+
+		rep := i.server.start(ctx, spec)
+
+		defer func(start time.Time) {
+			recoveryData := recover()
+			if recoveryData != nil {
+				err = newInternalError()
+			}
+
+			var name = fmt.Sprintf("CONNECT:SERVER/%s", spec.Procedure)
+
+			connectLogHelper(ctx, name, isSkip(i.c.ignore, spec.Procedure), recoveryData, err,
+				tel.Duration("duration", time.Since(start)),
+				tel.String("method", spec.Procedure),
+				tel.String("headers", marshal(conn.RequestHeader())),
+				tel.String("status_code", codeOf(err)),
+			)
+
+			endSpan(span, err)
+			rep.Handled(ctx, err)
+		}(time.Now())
+
+		return next(ctx, conn)
+	}
+}
+
+// unaryServer creates new telepresence instance + fill trace ids
+func (i *Interceptor) unaryServer(root context.Context, req connect.AnyRequest, next connect.UnaryFunc) (
+	resp connect.AnyResponse, err error) {
+	spec := req.Spec()
+
+	ctx, span := i.startServerSpan(root, spec.Procedure, req.Header())
+	defer span.End()
+
+	rep := i.server.start(ctx, spec)
+
+	defer func(start time.Time) {
+		recoveryData := recover()
+
+		var name = fmt.Sprintf("CONNECT:SERVER/%s", spec.Procedure)
+
+		putConnectError(ctx, name, err)
+
+		connectLogHelper(ctx, name, isSkip(i.c.ignore, spec.Procedure), recoveryData, err,
+			tel.Duration("duration", time.Since(start)),
+			tel.String("method", spec.Procedure),
+			tel.String("request", marshal(anyMsg(req))),
+			tel.String("headers", marshal(req.Header())),
+			tel.String("response", marshal(anyMsg(resp))),
+			tel.String("status_code", codeOf(err)),
+			tel.String("status_message", errMessage(err)),
+			tel.String("status_details", marshal(errDetails(err))),
+		)
+
+		if recoveryData != nil {
+			err = newInternalError()
+		}
+
+		endSpan(span, err)
+		rep.Handled(ctx, err)
+	}(time.Now())
+
+	return next(ctx, req)
+}
+
+// unaryClient input ctx assume that it contain telemetry instance
+func (i *Interceptor) unaryClient(ctx context.Context, req connect.AnyRequest, next connect.UnaryFunc) (
+	resp connect.AnyResponse, err error) {
+	spec := req.Spec()
+
+	ctx, span := i.tracer.Start(ctx, spanName(spec.Procedure),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(rpcAttributes(spec.Procedure)...),
+	)
+	defer span.End()
+
+	i.c.propagators.Inject(ctx, propagation.HeaderCarrier(req.Header()))
+
+	rep := i.client.start(ctx, spec)
+
+	defer func(start time.Time) {
+		var name = fmt.Sprintf("CONNECT:CLIENT/%s", spec.Procedure)
+
+		tele := tel.FromCtx(ctx).Copy()
+		ctx = tel.WrapContext(ctx, &tele)
+
+		putConnectError(ctx, name, err)
+
+		connectLogHelper(ctx, name, isSkip(i.c.ignore, spec.Procedure), recover(), err,
+			tel.Duration("duration", time.Since(start)),
+			tel.String("method", spec.Procedure),
+			tel.String("request", marshal(anyMsg(req))),
+			tel.String("response", marshal(anyMsg(resp))),
+			tel.String("status_code", codeOf(err)),
+			tel.String("status_message", errMessage(err)),
+			tel.String("status_details", marshal(errDetails(err))),
+		)
+
+		endSpan(span, err)
+		rep.Handled(ctx, err)
+	}(time.Now())
+
+	return next(ctx, req)
+}
+
+// startServerSpan continue span which already exists in ctx, for example created by HTTP middleware,
+// otherwise extract remote one from headers. Returned ctx contains new telemetry instance with trace fields.
+func (i *Interceptor) startServerSpan(ctx context.Context, procedure string, header http.Header) (context.Context, trace.Span) {
+	if i.c.trustRemote || !trace.SpanContextFromContext(ctx).IsValid() {
+		ctx = i.c.propagators.Extract(ctx, propagation.HeaderCarrier(header))
+	}
+
+	ctx, span := i.tracer.Start(ctx, spanName(procedure),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(rpcAttributes(procedure)...),
+	)
+
+	ctx = i.c.log.WithContext(ctx)
+
+	// set tracing identification to log
+	tel.UpdateTraceFields(ctx)
+
+	return ctx, span
+}
+
+type streamingClientConn struct {
+	connect.StreamingClientConn
+
+	ctx      context.Context
+	span     trace.Span
+	reporter *reporter
+	skip     bool
+	err      error
+}
+
+func (s *streamingClientConn) Send(msg any) error {
+	err := s.StreamingClientConn.Send(msg)
+	s.observe(err)
+
+	return err
+}
+
+func (s *streamingClientConn) Receive(msg any) error {
+	err := s.StreamingClientConn.Receive(msg)
+	s.observe(err)
+
+	return err
+}
+
+func (s *streamingClientConn) CloseResponse() error {
+	err := s.StreamingClientConn.CloseResponse()
+	s.observe(err)
+
+	var name = fmt.Sprintf("CONNECT:CLIENT/%s", s.Spec().Procedure)
+
+	connectLogHelper(s.ctx, name, s.skip, nil, s.err,
+		tel.String("method", s.Spec().Procedure),
+		tel.String("status_code", codeOf(s.err)),
+	)
+
+	endSpan(s.span, s.err)
+	s.reporter.Handled(s.ctx, s.err)
+
+	return err
+}
+
+// observe keeps first significant stream error
+func (s *streamingClientConn) observe(err error) {
+	if err == nil || errors.Is(err, io.EOF) || s.err != nil {
+		return
+	}
+
+	s.err = err
+}
+
+func connectLogHelper(
+	ctx context.Context,
+	name string,
+	skip bool,
+	hasRecovery interface{},
+	err error,
+	fields ...zap.Field,
+) {
+	t := tel.FromCtx(ctx)
+
+	lvl := zapcore.DebugLevel
+	if err != nil {
+		lvl = zapcore.ErrorLevel
+		fields = append(fields, tel.Error(err))
+	}
+
+	if hasRecovery != nil {
+		lvl = zapcore.ErrorLevel
+		fields = append(fields, tel.Error(fmt.Errorf("recovery info: %+v", hasRecovery)))
+
+		if t.IsDebug() {
+			debug.PrintStack()
+		}
+	} else if skip {
+		return
+	}
+
+	t.Check(lvl, name).Write(fields...)
+}
+
+// putConnectError expose error details the same way as middleware/grpc does
+func putConnectError(ctx context.Context, name string, err error) {
+	var connectErr *connect.Error
+	if !errors.As(err, &connectErr) {
+		return
+	}
+
+	tel.FromCtx(ctx).PutFields(
+		tel.Strings("connect-error-call", []string{name, connectErr.Error()}),
+	)
+
+	for _, v := range errDetails(err) {
+		switch t := v.(type) {
+		case *errdetails.PreconditionFailure:
+			for _, violation := range t.GetViolations() {
+				k := fmt.Sprintf("%s/%s", name, violation.Type)
+				tel.FromCtx(ctx).PutFields(
+					tel.Strings(k, []string{violation.GetDescription(), violation.GetSubject()}),
+				)
+			}
+		case *errdetails.BadRequest:
+			for _, violation := range t.GetFieldViolations() {
+				k := fmt.Sprintf("%s/field/%s", name, violation.GetField())
+				tel.FromCtx(ctx).PutFields(
+					tel.String(k, violation.GetDescription()),
+				)
+			}
+		}
+	}
+}
+
+// errDetails returns decoded details of connect error
+func errDetails(err error) []any {
+	var connectErr *connect.Error
+	if !errors.As(err, &connectErr) {
+		return nil
+	}
+
+	var res []any
+
+	for _, detail := range connectErr.Details() {
+		v, e := detail.Value()
+		if e != nil {
+			continue
+		}
+
+		res = append(res, v)
+	}
+
+	return res
+}
+
+func errMessage(err error) string {
+	var connectErr *connect.Error
+	if errors.As(err, &connectErr) {
+		return connectErr.Message()
+	}
+
+	if err != nil {
+		return err.Error()
+	}
+
+	return ""
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.SetAttributes(semconv.RPCConnectRPCErrorCodeKey.String(codeOf(err)))
+	}
+}
+
+func rpcAttributes(procedure string) []attribute.KeyValue {
+	service, method := splitProcedure(procedure)
+
+	return []attribute.KeyValue{
+		semconv.RPCSystemConnectRPC,
+		semconv.RPCService(service),
+		semconv.RPCMethod(method),
+	}
+}
+
+func spanName(procedure string) string {
+	return strings.TrimPrefix(procedure, "/")
+}
+
+func newInternalError() error {
+	return connect.NewError(connect.CodeInternal, errors.New("internal server error"))
+}
+
+// anyMsg safe for typed nil response returned with error
+func anyMsg(v interface{ Any() any }) any {
+	if v == nil || reflect.ValueOf(v).IsNil() {
+		return nil
+	}
+
+	return v.Any()
+}
+
+func isSkip(ignore []string, method string) bool {
+	skip := false
+	for _, m := range ignore {
+		if m == method {
+			skip = true
+
+			break
+		}
+	}
+	return skip
+}
+
+func marshal(input interface{}) string {
+	data, _ := json.MarshalIndent(input, "", "    ")
+
+	return string(data)
+}
@@ -0,0 +1,147 @@
+package connect
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/suite"
+	"github.com/tel-io/tel/v2"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const procedure = "/test.v1.EchoService/Echo"
+
+type Suite struct {
+	suite.Suite
+
+	tel   tel.Telemetry
+	close func()
+
+	rec    *tracetest.SpanRecorder
+	srv    *httptest.Server
+	client *connect.Client[wrapperspb.StringValue, wrapperspb.StringValue]
+
+	// cb is called by echo handler
+	cb func(ctx context.Context) error
+}
+
+func (s *Suite) SetupSuite() {
+	cfg := tel.DefaultDebugConfig()
+	cfg.OtelConfig.Enable = false
+
+	s.tel, s.close = tel.New(context.Background(), cfg)
+}
+
+func (s *Suite) TearDownSuite() {
+	s.close()
+}
+
+func (s *Suite) SetupTest() {
+	s.rec = tracetest.NewSpanRecorder()
+	s.cb = func(context.Context) error { return nil }
+
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(s.rec))
+
+	opts := []Option{
+		WithTel(&s.tel),
+		WithPropagators(propagation.TraceContext{}),
+		optionFunc(func(c *config) { c.tracerProvider = tp }),
+	}
+
+	handler := connect.NewUnaryHandler(procedure,
+		func(ctx context.Context, req *connect.Request[wrapperspb.StringValue]) (
+			*connect.Response[wrapperspb.StringValue], error) {
+			if err := s.cb(ctx); err != nil {
+				return nil, err
+			}
+
+			return connect.NewResponse(req.Msg), nil
+		},
+		connect.WithInterceptors(NewInterceptor(opts...)),
+	)
+
+	// emulate HTTP middleware which creates own span
+	mux := http.NewServeMux()
+	mux.Handle(procedure, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := propagation.TraceContext{}.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tp.Tracer("http").Start(ctx, "HTTP", trace.WithSpanKind(trace.SpanKindServer))
+		defer span.End()
+
+		handler.ServeHTTP(w, r.WithContext(ctx))
+	}))
+
+	s.srv = httptest.NewServer(mux)
+
+	s.client = connect.NewClient[wrapperspb.StringValue, wrapperspb.StringValue](
+		s.srv.Client(), s.srv.URL+procedure,
+		connect.WithInterceptors(NewInterceptor(opts...)),
+	)
+}
+
+func (s *Suite) TearDownTest() {
+	s.srv.Close()
+}
+
+func TestConnect(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+func (s *Suite) span(name string, kind trace.SpanKind) sdktrace.ReadOnlySpan {
+	for _, span := range s.rec.Ended() {
+		if span.Name() == name && span.SpanKind() == kind {
+			return span
+		}
+	}
+
+	s.Require().Failf("span not found", "%s %s", name, kind)
+
+	return nil
+}
+
+func (s *Suite) TestInterceptorNesting() {
+	s.cb = func(ctx context.Context) error {
+		s.True(trace.SpanContextFromContext(ctx).IsValid())
+		return nil
+	}
+
+	res, err := s.client.CallUnary(context.Background(), connect.NewRequest(wrapperspb.String("hello")))
+	s.Require().NoError(err)
+	s.Equal("hello", res.Msg.GetValue())
+
+	client := s.span("test.v1.EchoService/Echo", trace.SpanKindClient)
+	httpSpan := s.span("HTTP", trace.SpanKindServer)
+	server := s.span("test.v1.EchoService/Echo", trace.SpanKindServer)
+
+	s.Equal(client.SpanContext().SpanID(), httpSpan.Parent().SpanID())
+	s.Equal(httpSpan.SpanContext().SpanID(), server.Parent().SpanID())
+	s.Equal(client.SpanContext().TraceID(), server.SpanContext().TraceID())
+}
+
+func (s *Suite) TestInterceptorError() {
+	s.cb = func(ctx context.Context) error {
+		return connect.NewError(connect.CodeInvalidArgument, errors.New("bad"))
+	}
+
+	_, err := s.client.CallUnary(context.Background(), connect.NewRequest(wrapperspb.String("hello")))
+	s.Equal(connect.CodeInvalidArgument, connect.CodeOf(err))
+
+	server := s.span("test.v1.EchoService/Echo", trace.SpanKindServer)
+	s.Equal("Error", server.Status().Code.String())
+}
+
+func (s *Suite) TestInterceptorRecovery() {
+	s.cb = func(ctx context.Context) error {
+		panic("manic call")
+	}
+
+	_, err := s.client.CallUnary(context.Background(), connect.NewRequest(wrapperspb.String("hello")))
+	s.Equal(connect.CodeInternal, connect.CodeOf(err))
+}
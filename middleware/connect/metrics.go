@@ -0,0 +1,153 @@
+package connect
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"connectrpc.com/connect"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	serverStartedCounter   = "connect_server_started_total"
+	serverHandledCounter   = "connect_server_handled_total"
+	serverHandledHistogram = "connect_server_handling_seconds"
+
+	clientStartedCounter   = "connect_client_started_total"
+	clientHandledCounter   = "connect_client_handled_total"
+	clientHandledHistogram = "connect_client_handling_seconds"
+)
+
+const (
+	AttrType    = "connect_type"
+	AttrService = "connect_service"
+	AttrMethod  = "connect_method"
+	AttrCode    = "connect_code"
+)
+
+const codeOK = "ok"
+
+type metrics struct {
+	started metric.Int64Counter
+	handled metric.Int64Counter
+	latency metric.Float64Histogram
+}
+
+func newServerMetrics(m metric.Meter) *metrics {
+	return &metrics{
+		started: mustCounter(m.Int64Counter(serverStartedCounter,
+			metric.WithDescription("Total number of RPCs started on the server."),
+			metric.WithUnit("1"),
+		)),
+		handled: mustCounter(m.Int64Counter(serverHandledCounter,
+			metric.WithDescription("Total number of RPCs completed on the server, regardless of success or failure."),
+			metric.WithUnit("1"),
+		)),
+		latency: mustHistogram(m.Float64Histogram(serverHandledHistogram,
+			metric.WithDescription("Histogram of response latency of RPC that had been application-level handled by the server."),
+			metric.WithUnit("s"),
+		)),
+	}
+}
+
+func newClientMetrics(m metric.Meter) *metrics {
+	return &metrics{
+		started: mustCounter(m.Int64Counter(clientStartedCounter,
+			metric.WithDescription("Total number of RPCs started on the client."),
+			metric.WithUnit("1"),
+		)),
+		handled: mustCounter(m.Int64Counter(clientHandledCounter,
+			metric.WithDescription("Total number of RPCs completed by the client, regardless of success or failure."),
+			metric.WithUnit("1"),
+		)),
+		latency: mustHistogram(m.Float64Histogram(clientHandledHistogram,
+			metric.WithDescription("Histogram of response latency of RPC until it is finished by the application."),
+			metric.WithUnit("s"),
+		)),
+	}
+}
+
+// reporter holds precomputed attributes of single call
+type reporter struct {
+	m     *metrics
+	attrs []attribute.KeyValue
+	start time.Time
+}
+
+func (m *metrics) start(ctx context.Context, spec connect.Spec) *reporter {
+	service, method := splitProcedure(spec.Procedure)
+
+	r := &reporter{
+		m: m,
+		attrs: []attribute.KeyValue{
+			attribute.String(AttrType, streamType(spec.StreamType)),
+			attribute.String(AttrService, service),
+			attribute.String(AttrMethod, method),
+		},
+		start: time.Now(),
+	}
+
+	m.started.Add(ctx, 1, metric.WithAttributes(r.attrs...))
+
+	return r
+}
+
+func (r *reporter) Handled(ctx context.Context, err error) {
+	set := metric.WithAttributes(r.attrs...)
+
+	attrs := make([]attribute.KeyValue, 0, len(r.attrs)+1)
+	attrs = append(attrs, r.attrs...)
+	attrs = append(attrs, attribute.String(AttrCode, codeOf(err)))
+
+	r.m.handled.Add(ctx, 1, metric.WithAttributes(attrs...))
+	r.m.latency.Record(ctx, time.Since(r.start).Seconds(), set)
+}
+
+func codeOf(err error) string {
+	if err == nil {
+		return codeOK
+	}
+
+	return connect.CodeOf(err).String()
+}
+
+func streamType(t connect.StreamType) string {
+	switch t {
+	case connect.StreamTypeUnary:
+		return "unary"
+	case connect.StreamTypeClient:
+		return "client_stream"
+	case connect.StreamTypeServer:
+		return "server_stream"
+	}
+
+	return "bidi_stream"
+}
+
+func splitProcedure(procedure string) (string, string) {
+	procedure = strings.TrimPrefix(procedure, "/") // remove leading slash
+	if i := strings.Index(procedure, "/"); i >= 0 {
+		return procedure[:i], procedure[i+1:]
+	}
+
+	return "unknown", "unknown"
+}
+
+func mustCounter(v metric.Int64Counter, err error) metric.Int64Counter {
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return v
+}
+
+func mustHistogram(v metric.Float64Histogram, err error) metric.Float64Histogram {
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return v
+}
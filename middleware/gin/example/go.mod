@@ -37,6 +37,7 @@ require (
 	github.com/shirou/gopsutil/v4 v4.24.6 // indirect
 	github.com/shoenig/go-m1cpu v0.1.6 // indirect
 	github.com/tel-io/instrumentation/middleware/http v1.2.9 // indirect
+	github.com/tel-io/instrumentation/module/telfields v1.0.0 // indirect
 	github.com/tklauser/go-sysconf v0.3.14 // indirect
 	github.com/tklauser/numcpus v0.8.0 // indirect
 	github.com/ugorji/go/codec v1.2.7 // indirect
@@ -69,3 +70,5 @@ require (
 replace github.com/tel-io/instrumentation/middleware/gin => ../../../middleware/gin
 
 replace github.com/tel-io/instrumentation/middleware/http => ../../../middleware/http
+
+replace github.com/tel-io/instrumentation/module/telfields => ../../../module/telfields
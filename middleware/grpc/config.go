@@ -2,6 +2,7 @@ package grpc
 
 import (
 	"github.com/tel-io/instrumentation/module/otelgrpc"
+	"github.com/tel-io/instrumentation/module/telfields"
 	"github.com/tel-io/tel/v2"
 	otracer "go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 )
@@ -14,6 +15,9 @@ type config struct {
 
 	// ignore grpc list
 	ignore []string
+
+	// nil unless WithLogFields is set
+	fields *telfields.Propagator
 }
 
 // Option interface used for setting optional config properties.
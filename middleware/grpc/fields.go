@@ -0,0 +1,90 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/tel-io/instrumentation/module/telfields"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// DefaultFieldPrefix of metadata keys carrying tel log fields
+	DefaultFieldPrefix = "tel-field-"
+	// DefaultFieldMaxValue max size of single field value, bytes
+	DefaultFieldMaxValue = telfields.DefaultMaxValue
+	// DefaultFieldMaxTotal max size of all propagated fields: keys and values, bytes
+	DefaultFieldMaxTotal = telfields.DefaultMaxTotal
+)
+
+func (c *config) fieldsConfig() *telfields.Propagator {
+	if c.fields == nil {
+		c.fields = telfields.NewPropagator(DefaultFieldPrefix)
+	}
+
+	return c.fields
+}
+
+// WithLogFields propagate tel log fields with listed keys via metadata:
+// client interceptors write them from tel of call ctx, server interceptors put them to tel of call ctx.
+// Fields are visible for client only when logger is wrapped by RecordFields or fields were restored by server.
+func WithLogFields(keys ...string) Option {
+	return optionFunc(func(c *config) {
+		f := c.fieldsConfig()
+		f.Keys = append(f.Keys, keys...)
+	})
+}
+
+// WithLogFieldPrefix of field metadata keys, metadata keys are lowercase
+// Default: DefaultFieldPrefix
+func WithLogFieldPrefix(prefix string) Option {
+	return optionFunc(func(c *config) {
+		c.fieldsConfig().Prefix = prefix
+	})
+}
+
+// WithLogFieldLimits skips field values longer than maxValue and fields exceeding maxTotal size of all fields
+// Default: DefaultFieldMaxValue, DefaultFieldMaxTotal
+func WithLogFieldLimits(maxValue, maxTotal int) Option {
+	return optionFunc(func(c *config) {
+		f := c.fieldsConfig()
+		f.MaxValue, f.MaxTotal = maxValue, maxTotal
+	})
+}
+
+// RecordFields wraps logger core, so fields put to tel afterwards are available for propagation.
+// It's telfields.RecordFields, loggers wrapped by nats or http middleware are understood as well.
+func RecordFields(l *zap.Logger) *zap.Logger {
+	return telfields.RecordFields(l)
+}
+
+// injectFields returns ctx with allowed fields recorded by tel of ctx in outgoing metadata
+func injectFields(ctx context.Context, f *telfields.Propagator) context.Context {
+	var kv []string
+
+	f.Inject(ctx, func(key, value string) {
+		kv = append(kv, key, value)
+	})
+
+	if len(kv) == 0 {
+		return ctx
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, kv...)
+}
+
+// extractFields puts allowed fields from incoming metadata to tel of ctx
+func extractFields(ctx context.Context, f *telfields.Propagator) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return
+	}
+
+	f.Extract(ctx, func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+
+		return ""
+	})
+}
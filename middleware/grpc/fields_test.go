@@ -0,0 +1,126 @@
+package grpc
+
+import (
+	"context"
+	"net"
+
+	"github.com/tel-io/tel/v2"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/examples/helloworld/helloworld"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+)
+
+func (s *Suite) TestLogFields() {
+	core, logs := observer.New(zapcore.DebugLevel)
+	consumer := tel.NewNull()
+	consumer.Logger = zap.New(core)
+
+	var md metadata.MD
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		UnaryServerInterceptor(WithTel(&consumer), WithLogFields("order_id", "tenant")),
+		func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (
+			interface{}, error) {
+			md, _ = metadata.FromIncomingContext(ctx)
+			tel.FromCtx(ctx).Info("handler")
+
+			return handler(ctx, req)
+		},
+	))
+	helloworld.RegisterGreeterServer(srv, &MockServer{Fixture: Fixture{
+		Res: &helloworld.HelloReply{},
+		CB:  func(*helloworld.HelloRequest) {},
+	}})
+
+	go func() { _ = srv.Serve(l) }()
+	defer srv.Stop()
+
+	producer := tel.NewNull()
+	producer.Logger = RecordFields(producer.Logger).With(
+		zap.String("order_id", "42"),
+		zap.String("tenant", "acme corp"),
+		zap.String("secret", "pass"),
+	)
+
+	conn, err := grpc.NewClient(l.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor(
+			WithTel(&producer), WithLogFields("order_id", "tenant"), WithLogFieldLimits(5, 100),
+		)),
+	)
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	_, err = helloworld.NewGreeterClient(conn).SayHello(producer.Ctx(), &helloworld.HelloRequest{})
+	s.Require().NoError(err)
+
+	s.Equal([]string{"42"}, md.Get("tel-field-order_id"))
+	s.Empty(md.Get("tel-field-tenant"), "exceeds value limit")
+	s.Empty(md.Get("tel-field-secret"))
+
+	entries := logs.FilterMessage("handler").All()
+	s.Require().Len(entries, 1)
+	s.Equal("42", entries[0].ContextMap()["order_id"])
+	s.NotContains(entries[0].ContextMap(), "tenant")
+}
+
+// watchServer logs in stream handler
+type watchServer struct {
+	healthpb.UnimplementedHealthServer
+	md metadata.MD
+}
+
+func (w *watchServer) Watch(_ *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	w.md, _ = metadata.FromIncomingContext(stream.Context())
+	tel.FromCtx(stream.Context()).Info("stream handler")
+
+	return stream.Send(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING})
+}
+
+func (s *Suite) TestLogFieldsStream() {
+	core, logs := observer.New(zapcore.DebugLevel)
+	consumer := tel.NewNull()
+	consumer.Logger = zap.New(core)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+
+	srv := grpc.NewServer(grpc.StreamInterceptor(
+		StreamServerInterceptor(WithTel(&consumer), WithLogFields("order_id")),
+	))
+	ws := &watchServer{}
+	healthpb.RegisterHealthServer(srv, ws)
+
+	go func() { _ = srv.Serve(l) }()
+	defer srv.Stop()
+
+	producer := tel.NewNull()
+	producer.Logger = RecordFields(producer.Logger).With(zap.String("order_id", "42"))
+
+	conn, err := grpc.NewClient(l.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStreamInterceptor(StreamClientInterceptor(WithTel(&producer), WithLogFields("order_id"))),
+	)
+	s.Require().NoError(err)
+	defer conn.Close()
+
+	stream, err := healthpb.NewHealthClient(conn).Watch(producer.Ctx(), &healthpb.HealthCheckRequest{})
+	s.Require().NoError(err)
+
+	_, err = stream.Recv()
+	s.Require().NoError(err)
+
+	s.Equal([]string{"42"}, ws.md.Get("tel-field-order_id"))
+
+	entries := logs.FilterMessage("stream handler").All()
+	s.Require().Len(entries, 1)
+	s.Equal("42", entries[0].ContextMap()["order_id"])
+}
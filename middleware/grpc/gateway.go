@@ -0,0 +1,84 @@
+package grpc
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/tel-io/tel/v2"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// MetadataHTTPRoute carry HTTP route template, e.g. /v1/users/{id}, from grpc-gateway to gRPC server
+	MetadataHTTPRoute = "x-http-route"
+	// MetadataHTTPMethod carry original HTTP method from grpc-gateway to gRPC server
+	MetadataHTTPMethod = "x-http-method"
+)
+
+// GatewayServeMuxOption register GatewayMetadata annotator for runtime.NewServeMux
+func GatewayServeMuxOption() runtime.ServeMuxOption {
+	return runtime.WithMetadata(GatewayMetadata)
+}
+
+// GatewayMetadata put HTTP route template into HTTP span and gRPC metadata
+// Should be registered via runtime.WithMetadata
+func GatewayMetadata(ctx context.Context, r *http.Request) metadata.MD {
+	route, ok := runtime.HTTPPathPattern(ctx)
+	if !ok {
+		return nil
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(semconv.HTTPRoute(route))
+
+	return metadata.Pairs(MetadataHTTPRoute, route, MetadataHTTPMethod, r.Method)
+}
+
+// GatewayDialOptions instrument connection which grpc-gateway uses to reach gRPC server,
+// e.g. via RegisterXXXHandlerFromEndpoint.
+// Gateway pass HTTP request context to the client, so client span nest under HTTP span
+// when gateway mux is wrapped by HTTP middleware, and server span nest under client span.
+//
+// NOTE: RegisterXXXHandlerServer calls server directly without any interceptors
+func GatewayDialOptions(opts ...Option) []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(UnaryClientInterceptorAll(opts...)),
+		grpc.WithChainStreamInterceptor(StreamClientInterceptor(opts...)),
+	}
+}
+
+// gatewayClientRoute expose gateway HTTP route template in client span
+// and append it to outgoing metadata if annotator wasn't registered
+func gatewayClientRoute(ctx context.Context) context.Context {
+	route, ok := runtime.HTTPPathPattern(ctx)
+	if !ok {
+		return ctx
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(semconv.HTTPRoute(route))
+
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(MetadataHTTPRoute)) > 0 {
+		return ctx
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, MetadataHTTPRoute, route)
+}
+
+// gatewayServerRoute expose HTTP route template received from gateway in server span and log
+func gatewayServerRoute(ctx context.Context) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return
+	}
+
+	routes := md.Get(MetadataHTTPRoute)
+	if len(routes) == 0 {
+		return
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(semconv.HTTPRoute(routes[0]))
+	tel.FromCtx(ctx).PutFields(tel.String("http_route", routes[0]))
+}
@@ -0,0 +1,49 @@
+package grpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const gatewayRoute = "/v1/hello/{name}"
+
+func (s *Suite) TestGatewayMetadata() {
+	mux := runtime.NewServeMux(GatewayServeMuxOption())
+	req := httptest.NewRequest(http.MethodGet, "/v1/hello/world", nil)
+
+	ctx, err := runtime.AnnotateContext(s.tel.Ctx(), mux, req, "/helloworld.Greeter/SayHello",
+		runtime.WithHTTPPathPattern(gatewayRoute))
+	s.NoError(err)
+
+	md, ok := metadata.FromOutgoingContext(ctx)
+	s.True(ok)
+	s.Equal([]string{gatewayRoute}, md.Get(MetadataHTTPRoute))
+	s.Equal([]string{http.MethodGet}, md.Get(MetadataHTTPMethod))
+}
+
+func (s *Suite) TestGatewayClientRoute() {
+	ctx := runtime.NewServerMetadataContext(s.tel.Ctx(), runtime.ServerMetadata{})
+	mux := runtime.NewServeMux()
+	req := httptest.NewRequest(http.MethodGet, "/v1/hello/world", nil)
+
+	ctx, err := runtime.AnnotateContext(ctx, mux, req, "/helloworld.Greeter/SayHello",
+		runtime.WithHTTPPathPattern(gatewayRoute))
+	s.NoError(err)
+
+	var got metadata.MD
+
+	invoker := func(ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		got, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	}
+
+	err = UnaryClientInterceptor(WithTel(&s.tel))(ctx, "/helloworld.Greeter/SayHello", nil, nil, nil, invoker)
+	s.NoError(err)
+	s.Equal([]string{gatewayRoute}, got.Get(MetadataHTTPRoute))
+}
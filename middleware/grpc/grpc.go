@@ -10,6 +10,7 @@ import (
 
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	"github.com/tel-io/instrumentation/module/otelgrpc"
+	"github.com/tel-io/instrumentation/module/telfields"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
@@ -56,6 +57,13 @@ func UnaryClientInterceptor(o ...Option) grpc.UnaryClientInterceptor {
 		invoker grpc.UnaryInvoker,
 		opts ...grpc.CallOption,
 	) (err error) {
+		// carry grpc-gateway route template if call is made by gateway
+		ctx = gatewayClientRoute(ctx)
+
+		if c.fields != nil {
+			ctx = injectFields(ctx, c.fields)
+		}
+
 		defer func(start time.Time) {
 			var (
 				rpcError = status.Convert(err)
@@ -116,6 +124,13 @@ func UnaryServerInterceptor(o ...Option) grpc.UnaryServerInterceptor {
 		// set tracing identification to log
 		tel.UpdateTraceFields(ctx)
 
+		// route template passed by grpc-gateway
+		gatewayServerRoute(ctx)
+
+		if c.fields != nil {
+			extractFields(ctx, c.fields)
+		}
+
 		defer func(start time.Time) {
 			st, _ := status.FromError(err)
 			recoveryData := recover()
@@ -151,19 +166,55 @@ func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
 
 	otmetr := otelgrpc.NewServerMetrics(c.metricsOpts...)
 
-	return grpc_middleware.ChainStreamServer(
+	interceptors := []grpc.StreamServerInterceptor{
 		otracer.StreamServerInterceptor(c.traceOpts...),
-		otmetr.StreamServerInterceptor())
+		otmetr.StreamServerInterceptor(),
+	}
+
+	if c.fields != nil {
+		interceptors = append(interceptors, streamServerFields(c))
+	}
+
+	return grpc_middleware.ChainStreamServer(interceptors...)
+}
+
+// streamServerFields puts tel log fields of stream metadata to new tel instance of stream ctx
+func streamServerFields(c *config) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := c.log.WithContext(ss.Context())
+		tel.UpdateTraceFields(ctx)
+		extractFields(ctx, c.fields)
+
+		wrapped := grpc_middleware.WrapServerStream(ss)
+		wrapped.WrappedContext = ctx
+
+		return handler(srv, wrapped)
+	}
 }
 
 func StreamClientInterceptor(opts ...Option) grpc.StreamClientInterceptor {
 	c := newConfig(opts...)
 	otmetr := otelgrpc.NewClientMetrics(c.metricsOpts...)
 
-	return grpc_middleware.ChainStreamClient(
-		otmetr.StreamClientInterceptor(),
+	interceptors := []grpc.StreamClientInterceptor{
+		// tracer goes first: metrics need span in ctx for exemplars
 		otracer.StreamClientInterceptor(c.traceOpts...),
-	)
+		otmetr.StreamClientInterceptor(),
+	}
+
+	if c.fields != nil {
+		interceptors = append(interceptors, streamClientFields(c.fields))
+	}
+
+	return grpc_middleware.ChainStreamClient(interceptors...)
+}
+
+// streamClientFields writes tel log fields of ctx to stream metadata
+func streamClientFields(f *telfields.Propagator) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+		streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(injectFields(ctx, f), desc, cc, method, opts...)
+	}
 }
 
 func grpcLogHelper(
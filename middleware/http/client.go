@@ -22,6 +22,16 @@ func NewClient(ca []byte, opts ...Option) *http.Client {
 // UpdateClient inject tracer
 func UpdateClient(c *http.Client, opts ...Option) *http.Client {
 	s := newConfig(opts...)
+
+	if s.fields != nil {
+		next := c.Transport
+		if next == nil {
+			next = http.DefaultTransport
+		}
+
+		c.Transport = &fieldsTransport{next: next, fields: s.fields}
+	}
+
 	c.Transport = otelhttp.NewTransport(c.Transport, s.otelOpts...)
 
 	return c
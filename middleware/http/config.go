@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/tel-io/instrumentation/module/telfields"
 	"github.com/tel-io/tel/v2"
 
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
@@ -73,6 +74,9 @@ type config struct {
 	readHeader         bool
 	writeResponse      bool
 	dumpPayloadOnError bool
+
+	// nil unless WithLogFields is set
+	fields *telfields.Propagator
 }
 
 // Option interface used for setting optional config properties.
@@ -0,0 +1,72 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/tel-io/instrumentation/module/telfields"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultFieldPrefix of headers carrying tel log fields
+	DefaultFieldPrefix = "Tel-Field-"
+	// DefaultFieldMaxValue max size of single field value, bytes
+	DefaultFieldMaxValue = telfields.DefaultMaxValue
+	// DefaultFieldMaxTotal max size of all propagated fields: keys and values, bytes
+	DefaultFieldMaxTotal = telfields.DefaultMaxTotal
+)
+
+func (c *config) fieldsConfig() *telfields.Propagator {
+	if c.fields == nil {
+		c.fields = telfields.NewPropagator(DefaultFieldPrefix)
+	}
+
+	return c.fields
+}
+
+// WithLogFields propagate tel log fields with listed keys via request headers:
+// client writes them from tel of request ctx, server puts them to tel of request ctx.
+// Fields are visible for client only when logger is wrapped by RecordFields or fields were restored by server.
+func WithLogFields(keys ...string) Option {
+	return optionFunc(func(c *config) {
+		f := c.fieldsConfig()
+		f.Keys = append(f.Keys, keys...)
+	})
+}
+
+// WithLogFieldPrefix of field headers
+// Default: DefaultFieldPrefix
+func WithLogFieldPrefix(prefix string) Option {
+	return optionFunc(func(c *config) {
+		c.fieldsConfig().Prefix = prefix
+	})
+}
+
+// WithLogFieldLimits skips field values longer than maxValue and fields exceeding maxTotal size of all fields
+// Default: DefaultFieldMaxValue, DefaultFieldMaxTotal
+func WithLogFieldLimits(maxValue, maxTotal int) Option {
+	return optionFunc(func(c *config) {
+		f := c.fieldsConfig()
+		f.MaxValue, f.MaxTotal = maxValue, maxTotal
+	})
+}
+
+// RecordFields wraps logger core, so fields put to tel afterwards are available for propagation.
+// It's telfields.RecordFields, loggers wrapped by nats or grpc middleware are understood as well.
+func RecordFields(l *zap.Logger) *zap.Logger {
+	return telfields.RecordFields(l)
+}
+
+// fieldsTransport writes tel log fields of request ctx to request headers
+type fieldsTransport struct {
+	next   http.RoundTripper
+	fields *telfields.Propagator
+}
+
+func (t *fieldsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTripper must not modify request
+	req = req.Clone(req.Context())
+	t.fields.Inject(req.Context(), req.Header.Set)
+
+	return t.next.RoundTrip(req)
+}
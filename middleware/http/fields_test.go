@@ -0,0 +1,52 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/tel-io/tel/v2"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func (s *Suite) TestLogFields() {
+	core, logs := observer.New(zapcore.DebugLevel)
+	consumer := tel.NewNull()
+	consumer.Logger = zap.New(core)
+
+	var header http.Header
+
+	mw := ServerMiddleware(WithTel(&consumer), WithLogFields("order_id", "tenant"))
+	ss := httptest.NewServer(mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		tel.FromCtx(r.Context()).Info("handler")
+	})))
+	defer ss.Close()
+
+	producer := tel.NewNull()
+	producer.Logger = RecordFields(producer.Logger).With(
+		zap.String("order_id", "42"),
+		zap.String("tenant", "acme corp"),
+		zap.String("secret", "pass"),
+	)
+
+	client := UpdateClient(ss.Client(), WithLogFields("order_id", "tenant"), WithLogFieldLimits(5, 100))
+
+	req, err := http.NewRequestWithContext(producer.Ctx(), http.MethodGet, ss.URL+"/orders", nil)
+	s.Require().NoError(err)
+
+	res, err := client.Do(req)
+	s.Require().NoError(err)
+	s.NoError(res.Body.Close())
+
+	s.Equal("42", header.Get("Tel-Field-Order_id"))
+	s.Empty(header.Get("Tel-Field-Tenant"), "exceeds value limit")
+	s.Empty(header.Get("Tel-Field-Secret"))
+	s.Empty(req.Header, "request of caller is modified")
+
+	entries := logs.FilterMessage("handler").All()
+	s.Require().Len(entries, 1)
+	s.Equal("42", entries[0].ContextMap()["order_id"])
+	s.NotContains(entries[0].ContextMap(), "tenant")
+}
@@ -7,6 +7,7 @@ toolchain go1.22.7
 require (
 	github.com/felixge/httpsnoop v1.0.4
 	github.com/stretchr/testify v1.9.0
+	github.com/tel-io/instrumentation/module/telfields v1.0.0
 	github.com/tel-io/tel/v2 v2.3.6
 	go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp v0.53.0
 	go.opentelemetry.io/otel v1.28.0
@@ -56,3 +57,5 @@ require (
 	google.golang.org/protobuf v1.34.2 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace github.com/tel-io/instrumentation/module/telfields => ../../module/telfields
@@ -88,6 +88,10 @@ func ServerMiddleware(opts ...Option) Middleware {
 			// set tracing identification to log
 			tel.UpdateTraceFields(ctx)
 
+			if s.fields != nil {
+				s.fields.Extract(ctx, r.Header.Get)
+			}
+
 			// we should replace reader before handler call
 			// even with readRequest == false we should have copy of body as it would be used during error
 			var reqBody []byte
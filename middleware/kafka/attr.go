@@ -0,0 +1,85 @@
+package kafka
+
+import (
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// ExtractAttributes for logs: topic, kind and known partition, offset, consumer group and lag
+func ExtractAttributes(msg Message, kind string) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		Topic.String(msg.Topic()),
+		Kind.String(kind),
+	}
+
+	if p := msg.Partition(); p >= 0 {
+		attrs = append(attrs, Partition.Int64(int64(p)))
+	}
+
+	if o := msg.Offset(); o >= 0 {
+		attrs = append(attrs, Offset.Int64(o))
+	}
+
+	if g := msg.Group(); g != "" {
+		attrs = append(attrs, Group.String(g))
+	}
+
+	if l := msg.Lag(); l >= 0 {
+		attrs = append(attrs, Lag.Int64(l))
+	}
+
+	return attrs
+}
+
+// SpanAttributes messaging semantic conventions attributes of record, kind and lag
+func SpanAttributes(msg Message, kind string) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemKafka,
+		semconv.MessagingDestinationName(msg.Topic()),
+		semconv.MessagingMessageBodySize(len(msg.Value())),
+		Kind.String(kind),
+	}
+
+	if kind == KindProduce {
+		attrs = append(attrs, semconv.MessagingOperationTypePublish, semconv.MessagingOperationName("send"))
+	} else {
+		attrs = append(attrs, semconv.MessagingOperationTypeDeliver, semconv.MessagingOperationName("process"))
+	}
+
+	if key := msg.Key(); len(key) > 0 {
+		attrs = append(attrs, semconv.MessagingKafkaMessageKey(string(key)))
+	}
+
+	if msg.Value() == nil && kind != KindProduce {
+		attrs = append(attrs, semconv.MessagingKafkaMessageTombstone(true))
+	}
+
+	attrs = append(attrs, partitionAttributes(msg)...)
+
+	if g := msg.Group(); g != "" {
+		attrs = append(attrs, semconv.MessagingKafkaConsumerGroup(g))
+	}
+
+	if l := msg.Lag(); l >= 0 {
+		attrs = append(attrs, Lag.Int64(l))
+	}
+
+	return attrs
+}
+
+// partitionAttributes partition and offset if they are known
+func partitionAttributes(msg Message) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+
+	if p := msg.Partition(); p >= 0 {
+		attrs = append(attrs, semconv.MessagingDestinationPartitionID(strconv.Itoa(int(p))))
+	}
+
+	if o := msg.Offset(); o >= 0 {
+		attrs = append(attrs, semconv.MessagingKafkaMessageOffset(int(o)))
+	}
+
+	return attrs
+}
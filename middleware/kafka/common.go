@@ -0,0 +1,48 @@
+package kafka
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	KindKey    = "kind_of"
+	PayloadKey = "payload"
+)
+
+// Attribute keys that can be added to a span, log or metric.
+const (
+	Topic     = attribute.Key("topic")
+	Partition = attribute.Key("partition")
+	Offset    = attribute.Key("offset")
+	Group     = attribute.Key("consumer_group")
+	Lag       = attribute.Key("lag")
+	Event     = attribute.Key("event")
+	IsError   = attribute.Key("error")
+	Kind      = attribute.Key(KindKey)
+	Duration  = attribute.Key("duration")
+)
+
+const (
+	KindUnk     = "UNK"
+	KindConsume = "CONSUME"
+	KindProduce = "PRODUCE"
+)
+
+// Consumer group rebalance events, see Core.RecordRebalance
+const (
+	RebalanceAssigned = "assigned"
+	RebalanceRevoked  = "revoked"
+	RebalanceLost     = "lost"
+)
+
+// Kafka metrics
+const (
+	Count         = "kafka.count"          // Consumed and produced records total
+	ContentLength = "kafka.content_length" // Consumed and produced bytes of record values total
+	Latency       = "kafka.duration"       // Handler or produce duration, milliseconds
+	EndToEnd      = "kafka.e2e.duration"   // Time since record timestamp till handler start, milliseconds
+
+	ConsumerLag        = "kafka.consumer.lag"             // Records behind high watermark of partition after last processed record
+	ConsumerOffset     = "kafka.consumer.offset"          // Last processed offset of partition
+	ConsumerRebalances = "kafka.consumer.rebalance.count" // Consumer group rebalance events: assigned, revoked, lost
+)
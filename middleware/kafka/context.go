@@ -0,0 +1,22 @@
+package kafka
+
+import (
+	"context"
+)
+
+type kindKey struct{}
+
+// WrapKindOfContext put kind of record operation into ctx: KindConsume for handlers of consumed records,
+// KindProduce for produced ones. Logs, Tracer and Metrics choose span kind, operation name and instruments by it.
+func WrapKindOfContext(ctx context.Context, kindOf string) context.Context {
+	return context.WithValue(ctx, kindKey{}, kindOf)
+}
+
+// KindOfContext returns kind put by WrapKindOfContext or KindUnk
+func KindOfContext(ctx context.Context) string {
+	if v, ok := ctx.Value(kindKey{}).(string); ok {
+		return v
+	}
+
+	return KindUnk
+}
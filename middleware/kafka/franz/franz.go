@@ -0,0 +1,162 @@
+// Package franz adapts github.com/twmb/franz-go client to kafka middleware
+package franz
+
+import (
+	"context"
+	"sync"
+
+	"github.com/tel-io/instrumentation/middleware/kafka"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// Handler of consumed record
+type Handler func(ctx context.Context, r *kgo.Record) error
+
+// Client wraps *kgo.Client: produced and consumed records go through middleware of Core
+type Client struct {
+	*kgo.Client
+
+	core  *kafka.Core
+	group string
+
+	producer kafka.MsgHandler
+
+	mx sync.Mutex
+	// closed when the last record passed to Produce is buffered by client, keeps order of records
+	buffered chan struct{}
+}
+
+// Wrap client with middleware of core, consumer group is taken from kgo.ConsumerGroup option of client
+func Wrap(core *kafka.Core, cl *kgo.Client) *Client {
+	c := &Client{
+		Client:   cl,
+		core:     core,
+		buffered: make(chan struct{}),
+	}
+
+	close(c.buffered)
+
+	if v := cl.OptValue(kgo.ConsumerGroup); v != nil {
+		c.group, _ = v.(string)
+	}
+
+	c.producer = core.ProducerHandler(c.produce)
+
+	return c
+}
+
+// Produce record asynchronously: producer middleware is called in own goroutine and completes after acknowledgement,
+// promise is called with result of middleware chain. Records are buffered by client in order of Produce calls.
+func (c *Client) Produce(ctx context.Context, r *kgo.Record, promise func(*kgo.Record, error)) {
+	c.mx.Lock()
+	prev, buffered := c.buffered, make(chan struct{})
+	c.buffered = buffered
+	c.mx.Unlock()
+
+	go func() {
+		p := &pending{prev: prev, buffered: buffered}
+		defer p.release()
+
+		err := c.producer(withPending(ctx, p), &record{r: r, lag: -1, produced: true})
+
+		if promise != nil {
+			promise(r, err)
+		}
+	}()
+}
+
+// ProduceSync produces records and waits for results of middleware chains
+func (c *Client) ProduceSync(ctx context.Context, rs ...*kgo.Record) kgo.ProduceResults {
+	var (
+		wg  sync.WaitGroup
+		res = make(kgo.ProduceResults, len(rs))
+	)
+
+	wg.Add(len(rs))
+
+	for i, r := range rs {
+		i := i
+
+		c.Produce(ctx, r, func(r *kgo.Record, err error) {
+			res[i] = kgo.ProduceResult{Record: r, Err: err}
+			wg.Done()
+		})
+	}
+
+	wg.Wait()
+
+	return res
+}
+
+// produce is the innermost producer handler: it buffers record after previous one and waits acknowledgement
+func (c *Client) produce(ctx context.Context, msg kafka.Message) error {
+	m := msg.(*record)
+	p := pendingFrom(ctx)
+
+	if p != nil {
+		<-p.prev
+	}
+
+	done := make(chan error, 1)
+	c.Client.Produce(ctx, m.r, func(_ *kgo.Record, err error) {
+		done <- err
+	})
+
+	if p != nil {
+		p.release()
+	}
+
+	err := <-done
+	m.acked = err == nil
+
+	return err
+}
+
+// ProcessFetches calls handler for every record of fetches partition by partition with consumer middleware.
+// It stops on the first handler error and returns it, so records are committed by caller only on success.
+func (c *Client) ProcessFetches(ctx context.Context, fetches kgo.Fetches, h Handler) error {
+	handler := c.core.ConsumerHandler(func(ctx context.Context, msg kafka.Message) error {
+		return h(ctx, msg.(*record).r)
+	})
+
+	var err error
+
+	fetches.EachPartition(func(p kgo.FetchTopicPartition) {
+		for _, r := range p.Records {
+			if err != nil {
+				return
+			}
+
+			lag := p.HighWatermark - r.Offset - 1
+			if lag < 0 {
+				lag = 0
+			}
+
+			err = handler(ctx, &record{r: r, group: c.group, lag: lag})
+		}
+	})
+
+	return err
+}
+
+// GroupOpts count consumer group rebalances of client by Core.RecordRebalance.
+// Client calls only the last callback of kind, so pass own ones to these options.
+func GroupOpts(core *kafka.Core, onAssigned, onRevoked, onLost func(context.Context, *kgo.Client, map[string][]int32)) []kgo.Opt {
+	hook := func(event string, next func(context.Context, *kgo.Client, map[string][]int32)) func(
+		context.Context, *kgo.Client, map[string][]int32) {
+		return func(ctx context.Context, cl *kgo.Client, partitions map[string][]int32) {
+			group, _ := cl.OptValue(kgo.ConsumerGroup).(string)
+			core.RecordRebalance(ctx, group, event, partitions)
+
+			if next != nil {
+				next(ctx, cl, partitions)
+			}
+		}
+	}
+
+	return []kgo.Opt{
+		kgo.OnPartitionsAssigned(hook(kafka.RebalanceAssigned, onAssigned)),
+		kgo.OnPartitionsRevoked(hook(kafka.RebalanceRevoked, onRevoked)),
+		kgo.OnPartitionsLost(hook(kafka.RebalanceLost, onLost)),
+	}
+}
@@ -0,0 +1,187 @@
+package franz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/tel-io/instrumentation/middleware/kafka"
+	"github.com/tel-io/tel/v2"
+	"github.com/twmb/franz-go/pkg/kfake"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const topic = "orders"
+
+type Suite struct {
+	suite.Suite
+
+	core    *kafka.Core
+	spans   *tracetest.SpanRecorder
+	reader  *sdkmetric.ManualReader
+	cluster *kfake.Cluster
+	clients []*kgo.Client
+}
+
+func (s *Suite) SetupTest() {
+	cluster, err := kfake.NewCluster(kfake.NumBrokers(1), kfake.SeedTopics(1, topic))
+	s.Require().NoError(err)
+
+	s.cluster = cluster
+	s.clients = nil
+	s.spans = tracetest.NewSpanRecorder()
+	s.reader = sdkmetric.NewManualReader()
+
+	s.core = kafka.New(
+		kafka.WithTel(tel.NewNull()),
+		kafka.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(s.spans))),
+		kafka.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(s.reader))),
+		kafka.WithPropagators(propagation.TraceContext{}),
+	)
+}
+
+func (s *Suite) TearDownTest() {
+	for _, cl := range s.clients {
+		cl.Close()
+	}
+
+	s.NoError(s.core.Close())
+	s.cluster.Close()
+}
+
+func TestFranz(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+// client connected to cluster of the test, it's closed on test teardown
+func (s *Suite) client(opts ...kgo.Opt) *Client {
+	cl, err := kgo.NewClient(append([]kgo.Opt{
+		kgo.SeedBrokers(s.cluster.ListenAddrs()...),
+		kgo.FetchMaxWait(100 * time.Millisecond),
+	}, opts...)...)
+	s.Require().NoError(err)
+
+	s.clients = append(s.clients, cl)
+
+	return Wrap(s.core, cl)
+}
+
+func (s *Suite) metric(name string) metricdata.Aggregation {
+	var rm metricdata.ResourceMetrics
+	s.Require().NoError(s.reader.Collect(context.Background(), &rm))
+
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				return m.Data
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Suite) TestClient() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	producer := s.client()
+
+	var records []*kgo.Record
+	for i := 0; i < 3; i++ {
+		records = append(records, &kgo.Record{Topic: topic, Value: []byte(fmt.Sprint(i))})
+	}
+
+	s.Require().NoError(producer.ProduceSync(ctx, records...).FirstErr())
+
+	for i, r := range records {
+		s.Equal(int64(i), r.Offset, "records are buffered in order")
+		s.NotEmpty(r.Headers)
+	}
+
+	producerSpans := s.spans.Ended()
+	s.Require().Len(producerSpans, 3)
+
+	consumer := s.client(append([]kgo.Opt{
+		kgo.ConsumerGroup("billing"),
+		kgo.ConsumeTopics(topic),
+	}, GroupOpts(s.core, nil, nil, nil)...)...)
+
+	var handled []string
+
+	for len(handled) < len(records) {
+		fetches := consumer.PollFetches(ctx)
+		s.Require().NoError(fetches.Err())
+
+		err := consumer.ProcessFetches(ctx, fetches, func(ctx context.Context, r *kgo.Record) error {
+			s.True(trace.SpanContextFromContext(ctx).IsValid())
+			handled = append(handled, string(r.Value))
+
+			return nil
+		})
+		s.Require().NoError(err)
+	}
+
+	s.Equal([]string{"0", "1", "2"}, handled)
+
+	spans := s.spans.Ended()
+	s.Require().Len(spans, 6)
+
+	parents := make(map[trace.SpanID]bool)
+	for _, span := range producerSpans {
+		parents[span.SpanContext().SpanID()] = true
+	}
+
+	for _, span := range spans[3:] {
+		s.Equal("KAFKA:CONSUME/billing/orders", span.Name())
+		s.True(parents[span.Parent().SpanID()], "consumer span is child of producer span")
+		delete(parents, span.Parent().SpanID())
+	}
+
+	lag, ok := s.metric(kafka.ConsumerLag).(metricdata.Gauge[int64])
+	s.Require().True(ok)
+	s.Require().Len(lag.DataPoints, 1)
+	s.Equal(int64(0), lag.DataPoints[0].Value)
+
+	rebalances, ok := s.metric(kafka.ConsumerRebalances).(metricdata.Sum[int64])
+	s.Require().True(ok)
+	s.Require().NotEmpty(rebalances.DataPoints)
+}
+
+func (s *Suite) TestClient_ProcessFetchesError() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	producer := s.client()
+	s.Require().NoError(producer.ProduceSync(ctx,
+		&kgo.Record{Topic: topic, Value: []byte("0")},
+		&kgo.Record{Topic: topic, Value: []byte("1")},
+	).FirstErr())
+
+	consumer := s.client(kgo.ConsumeTopics(topic))
+	errTest := errors.New("test")
+
+	var (
+		calls int
+		err   error
+	)
+
+	for err == nil {
+		err = consumer.ProcessFetches(ctx, consumer.PollFetches(ctx), func(ctx context.Context, r *kgo.Record) error {
+			calls++
+			return errTest
+		})
+	}
+
+	s.ErrorIs(err, errTest)
+	s.Equal(1, calls, "processing stops on error")
+}
@@ -0,0 +1,32 @@
+package franz
+
+import (
+	"context"
+	"sync"
+)
+
+type pendingKey struct{}
+
+// pending orders buffering of records produced concurrently by middleware chains
+type pending struct {
+	prev     <-chan struct{}
+	buffered chan struct{}
+	once     sync.Once
+}
+
+// release lets the next record to be buffered, it's called after buffering or when chain didn't reach client
+func (p *pending) release() {
+	p.once.Do(func() {
+		<-p.prev
+		close(p.buffered)
+	})
+}
+
+func withPending(ctx context.Context, p *pending) context.Context {
+	return context.WithValue(ctx, pendingKey{}, p)
+}
+
+func pendingFrom(ctx context.Context) *pending {
+	p, _ := ctx.Value(pendingKey{}).(*pending)
+	return p
+}
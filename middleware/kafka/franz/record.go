@@ -0,0 +1,88 @@
+package franz
+
+import (
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// record adapts *kgo.Record to kafka.Message
+type record struct {
+	r     *kgo.Record
+	group string
+	lag   int64
+
+	// produced record has partition and offset only after acknowledgement
+	produced bool
+	acked    bool
+}
+
+func (m *record) Topic() string {
+	return m.r.Topic
+}
+
+func (m *record) Partition() int32 {
+	if m.produced && !m.acked {
+		return -1
+	}
+
+	return m.r.Partition
+}
+
+func (m *record) Offset() int64 {
+	if m.produced && !m.acked {
+		return -1
+	}
+
+	return m.r.Offset
+}
+
+func (m *record) Key() []byte {
+	return m.r.Key
+}
+
+func (m *record) Value() []byte {
+	return m.r.Value
+}
+
+func (m *record) Timestamp() time.Time {
+	return m.r.Timestamp
+}
+
+func (m *record) Group() string {
+	return m.group
+}
+
+func (m *record) Lag() int64 {
+	return m.lag
+}
+
+func (m *record) Header(key string) string {
+	for _, h := range m.r.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+
+	return ""
+}
+
+func (m *record) SetHeader(key, value string) {
+	for i, h := range m.r.Headers {
+		if h.Key == key {
+			m.r.Headers[i].Value = []byte(value)
+			return
+		}
+	}
+
+	m.r.Headers = append(m.r.Headers, kgo.RecordHeader{Key: key, Value: []byte(value)})
+}
+
+func (m *record) HeaderKeys() []string {
+	keys := make([]string, 0, len(m.r.Headers))
+	for _, h := range m.r.Headers {
+		keys = append(keys, h.Key)
+	}
+
+	return keys
+}
@@ -0,0 +1,80 @@
+// Package kafka instruments kafka consumers and producers with tel logs, traces and metrics.
+// Middleware works with Message view of record, sub packages adapt client libraries:
+// franz for github.com/twmb/franz-go and kafkago for github.com/segmentio/kafka-go.
+package kafka
+
+import (
+	"context"
+
+	"github.com/tel-io/tel/v2"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Core keeps consumer and producer middleware chains with shared instruments and lag observer
+type Core struct {
+	*config
+
+	consumerInter Interceptor
+	producerInter Interceptor
+}
+
+// New middleware instance
+// Every instance has own configuration and instruments, Close should be called when instance is not used anymore.
+func New(opts ...Option) *Core {
+	cfg := newConfig(opts)
+
+	return &Core{
+		config:        cfg,
+		consumerInter: MiddlewareChain(cfg.consumerMiddleware()...),
+		producerInter: MiddlewareChain(cfg.producerMiddleware()...),
+	}
+}
+
+// Close unregister metric callbacks of instance
+func (c *Core) Close() error {
+	return c.metrics.lag.Close()
+}
+
+// ConsumerHandler wraps handler with consumer middleware
+// Every record is handled with own copy of tel: from ctx if it's there, tel of Core otherwise.
+func (c *Core) ConsumerHandler(next MsgHandler) MsgHandler {
+	in := c.consumerInter(next)
+
+	return func(ctx context.Context, msg Message) error {
+		t := c.tele
+		if v := tel.ContextValue(ctx); v != nil {
+			t = *v
+		}
+
+		return in(WrapKindOfContext(tel.WrapContext(ctx, &t), KindConsume), msg)
+	}
+}
+
+// ProducerHandler wraps produce function with producer middleware, next should return after acknowledgement
+func (c *Core) ProducerHandler(next MsgHandler) MsgHandler {
+	in := c.producerInter(next)
+
+	return func(ctx context.Context, msg Message) error {
+		if tel.ContextValue(ctx) == nil {
+			ctx = c.tele.WithContext(ctx)
+		}
+
+		return in(WrapKindOfContext(ctx, KindProduce), msg)
+	}
+}
+
+// RecordRebalance count consumer group rebalance event: RebalanceAssigned, RebalanceRevoked or RebalanceLost.
+// Lag of revoked and lost partitions is not reported anymore.
+func (c *Core) RecordRebalance(ctx context.Context, group, event string, partitions map[string][]int32) {
+	c.metrics.counters[ConsumerRebalances].Add(ctx, 1,
+		metric.WithAttributes(Group.String(group), Event.String(event)),
+	)
+
+	if event == RebalanceAssigned {
+		return
+	}
+
+	for topic, list := range partitions {
+		c.metrics.lag.forget(group, topic, list...)
+	}
+}
@@ -0,0 +1,214 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/tel-io/tel/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// testMessage in-memory Message
+type testMessage struct {
+	topic     string
+	partition int32
+	offset    int64
+	value     []byte
+	ts        time.Time
+	group     string
+	lag       int64
+	headers   map[string]string
+}
+
+func (m *testMessage) Topic() string        { return m.topic }
+func (m *testMessage) Partition() int32     { return m.partition }
+func (m *testMessage) Offset() int64        { return m.offset }
+func (m *testMessage) Key() []byte          { return []byte("key") }
+func (m *testMessage) Value() []byte        { return m.value }
+func (m *testMessage) Timestamp() time.Time { return m.ts }
+func (m *testMessage) Group() string        { return m.group }
+func (m *testMessage) Lag() int64           { return m.lag }
+
+func (m *testMessage) Header(key string) string { return m.headers[key] }
+
+func (m *testMessage) SetHeader(key, value string) {
+	if m.headers == nil {
+		m.headers = map[string]string{}
+	}
+
+	m.headers[key] = value
+}
+
+func (m *testMessage) HeaderKeys() []string {
+	var keys []string
+	for k := range m.headers {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+type Suite struct {
+	suite.Suite
+
+	core   *Core
+	spans  *tracetest.SpanRecorder
+	reader *sdkmetric.ManualReader
+	logs   *observer.ObservedLogs
+}
+
+func (s *Suite) SetupTest() {
+	zc, logs := observer.New(zapcore.DebugLevel)
+	tele := tel.NewNull()
+	tele.Logger = zap.New(zc)
+
+	s.spans = tracetest.NewSpanRecorder()
+	s.reader = sdkmetric.NewManualReader()
+	s.logs = logs
+
+	s.core = New(
+		WithTel(tele),
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(s.spans))),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(s.reader))),
+		WithPropagators(propagation.TraceContext{}),
+	)
+}
+
+func (s *Suite) TearDownTest() {
+	s.NoError(s.core.Close())
+}
+
+func TestKafka(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+// metric returns points of metric by name
+func (s *Suite) metric(name string) metricdata.Aggregation {
+	var rm metricdata.ResourceMetrics
+	s.Require().NoError(s.reader.Collect(context.Background(), &rm))
+
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				return m.Data
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Suite) TestCore_ProduceConsume() {
+
+	msg := &testMessage{topic: "orders", partition: -1, offset: -1, lag: -1, value: []byte("data")}
+
+	err := s.core.ProducerHandler(func(ctx context.Context, m Message) error {
+		// acknowledged
+		msg.partition, msg.offset = 2, 10
+		return nil
+	})(context.Background(), msg)
+	s.Require().NoError(err)
+	s.Require().NotEmpty(msg.Header("traceparent"))
+
+	msg.group, msg.lag, msg.ts = "billing", 5, time.Now().Add(-time.Second)
+
+	var handled context.Context
+	err = s.core.ConsumerHandler(func(ctx context.Context, m Message) error {
+		handled = ctx
+		return nil
+	})(context.Background(), msg)
+	s.Require().NoError(err)
+
+	spans := s.spans.Ended()
+	s.Require().Len(spans, 2)
+
+	producer, consumer := spans[0], spans[1]
+	s.Equal("KAFKA:PRODUCE/orders", producer.Name())
+	s.Equal(trace.SpanKindProducer, producer.SpanKind())
+	s.Contains(producer.Attributes(), attribute.String("messaging.destination.partition.id", "2"))
+	s.Contains(producer.Attributes(), attribute.Int("messaging.kafka.message.offset", 10))
+
+	s.Equal("KAFKA:CONSUME/billing/orders", consumer.Name())
+	s.Equal(trace.SpanKindConsumer, consumer.SpanKind())
+	s.Equal(producer.SpanContext().SpanID(), consumer.Parent().SpanID())
+	s.Equal(consumer.SpanContext(), trace.SpanContextFromContext(handled))
+	s.Contains(consumer.Attributes(), Lag.Int64(5))
+	s.Contains(consumer.Attributes(), attribute.String("messaging.kafka.consumer.group", "billing"))
+
+	entries := s.logs.FilterMessage("KAFKA:CONSUME/billing/orders").All()
+	s.Require().Len(entries, 1)
+	s.Equal(zapcore.DebugLevel, entries[0].Level)
+	s.Equal("10", entries[0].ContextMap()[string(Offset)])
+
+	lag, ok := s.metric(ConsumerLag).(metricdata.Gauge[int64])
+	s.Require().True(ok)
+	s.Require().Len(lag.DataPoints, 1)
+	s.Equal(int64(5), lag.DataPoints[0].Value)
+
+	count, ok := s.metric(Count).(metricdata.Sum[int64])
+	s.Require().True(ok)
+	s.Len(count.DataPoints, 2)
+
+	e2e, ok := s.metric(EndToEnd).(metricdata.Histogram[float64])
+	s.Require().True(ok)
+	s.Require().Len(e2e.DataPoints, 1)
+	s.GreaterOrEqual(e2e.DataPoints[0].Sum, float64(1000))
+}
+
+func (s *Suite) TestCore_ConsumerError() {
+
+	msg := &testMessage{topic: "orders", partition: 0, offset: 1, lag: -1, value: []byte("broken")}
+	errTest := errors.New("test")
+
+	err := s.core.ConsumerHandler(func(ctx context.Context, m Message) error {
+		return errTest
+	})(context.Background(), msg)
+	s.ErrorIs(err, errTest)
+
+	err = s.core.ConsumerHandler(func(ctx context.Context, m Message) error {
+		panic("boom")
+	})(context.Background(), msg)
+	s.ErrorContains(err, "boom")
+
+	entries := s.logs.FilterMessage("KAFKA:CONSUME//orders").All()
+	s.Require().Len(entries, 2)
+	s.Equal(zapcore.ErrorLevel, entries[0].Level)
+	s.Equal("broken", entries[0].ContextMap()[PayloadKey])
+
+	s.Nil(s.metric(ConsumerLag), "lag is unknown")
+}
+
+func (s *Suite) TestCore_RecordRebalance() {
+	handler := s.core.ConsumerHandler(func(ctx context.Context, m Message) error { return nil })
+
+	for _, p := range []int32{0, 1} {
+		s.Require().NoError(handler(context.Background(),
+			&testMessage{topic: "orders", partition: p, offset: 1, lag: 3, group: "billing"}))
+	}
+
+	s.core.RecordRebalance(context.Background(), "billing", RebalanceRevoked, map[string][]int32{"orders": {0}})
+
+	lag, ok := s.metric(ConsumerLag).(metricdata.Gauge[int64])
+	s.Require().True(ok)
+	s.Require().Len(lag.DataPoints, 1)
+
+	v, _ := lag.DataPoints[0].Attributes.Value(Partition)
+	s.Equal(int64(1), v.AsInt64())
+
+	rebalances, ok := s.metric(ConsumerRebalances).(metricdata.Sum[int64])
+	s.Require().True(ok)
+	s.Require().Len(rebalances.DataPoints, 1)
+	s.Equal(int64(1), rebalances.DataPoints[0].Value)
+}
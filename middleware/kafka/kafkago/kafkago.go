@@ -0,0 +1,187 @@
+// Package kafkago adapts github.com/segmentio/kafka-go reader and writer to kafka middleware
+package kafkago
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+	kafkamw "github.com/tel-io/instrumentation/middleware/kafka"
+)
+
+// Handler of consumed message
+type Handler func(ctx context.Context, msg kafka.Message) error
+
+// Reader wraps *kafka.Reader: messages are handled with consumer middleware of Core
+type Reader struct {
+	*kafka.Reader
+
+	core  *kafkamw.Core
+	group string
+}
+
+// NewReader wraps reader with middleware of core
+func NewReader(core *kafkamw.Core, r *kafka.Reader) *Reader {
+	return &Reader{Reader: r, core: core, group: r.Config().GroupID}
+}
+
+// Handle message fetched by reader with consumer middleware
+func (r *Reader) Handle(ctx context.Context, msg kafka.Message, h Handler) error {
+	return r.handler(h)(ctx, &message{m: &msg, group: r.group})
+}
+
+// Consume fetches messages and handles them until ctx is done or handler fails.
+// Messages of consumer group are committed after successful handling.
+func (r *Reader) Consume(ctx context.Context, h Handler) error {
+	handler := r.handler(h)
+
+	for {
+		msg, err := r.FetchMessage(ctx)
+		if err != nil {
+			return err
+		}
+
+		if err = handler(ctx, &message{m: &msg, group: r.group}); err != nil {
+			return err
+		}
+
+		if r.group == "" {
+			continue
+		}
+
+		if err = r.CommitMessages(ctx, msg); err != nil {
+			return err
+		}
+	}
+}
+
+func (r *Reader) handler(h Handler) kafkamw.MsgHandler {
+	return r.core.ConsumerHandler(func(ctx context.Context, msg kafkamw.Message) error {
+		return h(ctx, *msg.(*message).m)
+	})
+}
+
+// Writer wraps *kafka.Writer: messages are written with producer middleware of Core
+type Writer struct {
+	*kafka.Writer
+
+	core *kafkamw.Core
+}
+
+// NewWriter wraps writer with middleware of core
+func NewWriter(core *kafkamw.Core, w *kafka.Writer) *Writer {
+	return &Writer{Writer: w, core: core}
+}
+
+// WriteMessages runs producer middleware for every message and writes messages which reached the writer as one batch.
+// kafka.WriteErrors is returned if any message failed, its indexes are the same as msgs ones.
+// With Writer.Async middleware completes when messages are queued.
+func (w *Writer) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	b := &batch{
+		msgs:     make([]kafka.Message, len(msgs)),
+		queued:   make([]bool, len(msgs)),
+		writeErr: make([]error, len(msgs)),
+		written:  make(chan struct{}),
+	}
+
+	// headers are injected into copies, header slices could be shared by caller messages
+	copy(b.msgs, msgs)
+	for i := range b.msgs {
+		b.msgs[i].Headers = slices.Clone(msgs[i].Headers)
+	}
+
+	b.arrived.Add(len(msgs))
+
+	var (
+		wg   sync.WaitGroup
+		errs = make(kafka.WriteErrors, len(msgs))
+	)
+
+	wg.Add(len(msgs))
+
+	for i := range b.msgs {
+		i := i
+
+		go func() {
+			defer wg.Done()
+
+			var once sync.Once
+			arrive := func() { once.Do(b.arrived.Done) }
+			// chain failed before writer
+			defer arrive()
+
+			handler := w.core.ProducerHandler(func(ctx context.Context, _ kafkamw.Message) error {
+				b.queued[i] = true
+				arrive()
+				<-b.written
+
+				return b.writeErr[i]
+			})
+
+			errs[i] = handler(ctx, &message{m: &b.msgs[i], topic: w.Topic, written: true})
+		}()
+	}
+
+	b.arrived.Wait()
+	b.write(ctx, w.Writer)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return errs
+		}
+	}
+
+	return nil
+}
+
+// batch collects messages which reached the writer through middleware
+type batch struct {
+	msgs     []kafka.Message
+	queued   []bool
+	writeErr []error
+
+	arrived sync.WaitGroup
+	written chan struct{}
+}
+
+// write queued messages and distribute errors among them
+func (b *batch) write(ctx context.Context, w *kafka.Writer) {
+	defer close(b.written)
+
+	var (
+		list []kafka.Message
+		idx  []int
+	)
+
+	for i, ok := range b.queued {
+		if ok {
+			list = append(list, b.msgs[i])
+			idx = append(idx, i)
+		}
+	}
+
+	if len(list) == 0 {
+		return
+	}
+
+	err := w.WriteMessages(ctx, list...)
+	if err == nil {
+		return
+	}
+
+	var werr kafka.WriteErrors
+	if errors.As(err, &werr) && len(werr) == len(list) {
+		for j, i := range idx {
+			b.writeErr[i] = werr[j]
+		}
+
+		return
+	}
+
+	for _, i := range idx {
+		b.writeErr[i] = err
+	}
+}
@@ -0,0 +1,179 @@
+package kafkago
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kafkamw "github.com/tel-io/instrumentation/middleware/kafka"
+	"github.com/tel-io/tel/v2"
+	"github.com/twmb/franz-go/pkg/kfake"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const topic = "orders"
+
+func newCore(t *testing.T, opts ...kafkamw.Option) (*kafkamw.Core, *tracetest.SpanRecorder) {
+	t.Helper()
+
+	spans := tracetest.NewSpanRecorder()
+
+	core := kafkamw.New(append([]kafkamw.Option{
+		kafkamw.WithTel(tel.NewNull()),
+		kafkamw.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))),
+		kafkamw.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(sdkmetric.NewManualReader()))),
+		kafkamw.WithPropagators(propagation.TraceContext{}),
+	}, opts...)...)
+
+	t.Cleanup(func() {
+		assert.NoError(t, core.Close())
+	})
+
+	return core, spans
+}
+
+func TestWriterReader(t *testing.T) {
+	cluster, err := kfake.NewCluster(kfake.NumBrokers(1), kfake.SeedTopics(1, topic))
+	require.NoError(t, err)
+	t.Cleanup(cluster.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	core, spans := newCore(t)
+
+	w := NewWriter(core, &kafka.Writer{
+		Addr:         kafka.TCP(cluster.ListenAddrs()...),
+		Topic:        topic,
+		BatchTimeout: time.Millisecond,
+	})
+	t.Cleanup(func() { _ = w.Close() })
+
+	msgs := []kafka.Message{{Value: []byte("0")}, {Value: []byte("1")}}
+	require.NoError(t, w.WriteMessages(ctx, msgs...))
+	assert.Empty(t, msgs[0].Headers, "caller messages are not modified")
+
+	producerSpans := spans.Ended()
+	require.Len(t, producerSpans, 2)
+
+	r := NewReader(core, kafka.NewReader(kafka.ReaderConfig{
+		Brokers: cluster.ListenAddrs(),
+		Topic:   topic,
+		MaxWait: 100 * time.Millisecond,
+	}))
+	t.Cleanup(func() { _ = r.Close() })
+
+	var (
+		handled []string
+		errDone = errors.New("done")
+	)
+
+	err = r.Consume(ctx, func(ctx context.Context, msg kafka.Message) error {
+		assert.True(t, trace.SpanContextFromContext(ctx).IsValid())
+		handled = append(handled, string(msg.Value))
+
+		if len(handled) == len(msgs) {
+			return errDone
+		}
+
+		return nil
+	})
+	require.ErrorIs(t, err, errDone)
+	assert.ElementsMatch(t, []string{"0", "1"}, handled)
+
+	parents := make(map[trace.SpanID]bool)
+	for _, span := range producerSpans {
+		assert.Equal(t, "KAFKA:PRODUCE/orders", span.Name())
+		parents[span.SpanContext().SpanID()] = true
+	}
+
+	consumerSpans := spans.Ended()[2:]
+	require.Len(t, consumerSpans, 2)
+
+	for _, span := range consumerSpans {
+		assert.Equal(t, "KAFKA:CONSUME//orders", span.Name())
+		assert.True(t, parents[span.Parent().SpanID()], "consumer span is child of producer span")
+	}
+}
+
+func TestWriter_Error(t *testing.T) {
+	core, spans := newCore(t)
+
+	w := NewWriter(core, &kafka.Writer{Addr: kafka.TCP("127.0.0.1:1"), Topic: topic, MaxAttempts: 1})
+	t.Cleanup(func() { _ = w.Close() })
+
+	err := w.WriteMessages(context.Background(), kafka.Message{Value: []byte("0")}, kafka.Message{Value: []byte("1")})
+
+	var werr kafka.WriteErrors
+	require.ErrorAs(t, err, &werr)
+	require.Len(t, werr, 2)
+	assert.Error(t, werr[0])
+	assert.Error(t, werr[1])
+
+	require.Len(t, spans.Ended(), 2)
+	for _, span := range spans.Ended() {
+		assert.Equal(t, codes.Error, span.Status().Code)
+	}
+}
+
+func TestWriter_SharedHeaders(t *testing.T) {
+	cluster, err := kfake.NewCluster(kfake.NumBrokers(1), kfake.SeedTopics(1, topic))
+	require.NoError(t, err)
+	t.Cleanup(cluster.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	core, _ := newCore(t)
+
+	w := NewWriter(core, &kafka.Writer{
+		Addr:         kafka.TCP(cluster.ListenAddrs()...),
+		Topic:        topic,
+		BatchTimeout: time.Millisecond,
+	})
+	t.Cleanup(func() { _ = w.Close() })
+
+	// traceparent is overwritten in place and spare capacity is appended to by every message
+	headers := make([]kafka.Header, 1, 8)
+	headers[0] = kafka.Header{Key: "traceparent", Value: []byte("stale")}
+
+	msgs := make([]kafka.Message, 8)
+	for i := range msgs {
+		msgs[i] = kafka.Message{Value: []byte{byte(i)}, Headers: headers}
+	}
+
+	require.NoError(t, w.WriteMessages(ctx, msgs...))
+	assert.Equal(t, "stale", string(headers[0].Value), "caller headers are not modified")
+	assert.Equal(t, make([]kafka.Header, cap(headers)-1), headers[1:cap(headers)], "spare capacity is not written")
+
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: cluster.ListenAddrs(),
+		Topic:   topic,
+		MaxWait: 100 * time.Millisecond,
+	})
+	t.Cleanup(func() { _ = r.Close() })
+
+	parents := make(map[string]bool)
+
+	for range msgs {
+		msg, err := r.ReadMessage(ctx)
+		require.NoError(t, err)
+
+		for _, h := range msg.Headers {
+			if h.Key == "traceparent" {
+				parents[string(h.Value)] = true
+			}
+		}
+	}
+
+	assert.Len(t, parents, len(msgs), "every message has own trace context")
+}
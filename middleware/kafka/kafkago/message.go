@@ -0,0 +1,99 @@
+package kafkago
+
+import (
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// message adapts *kafka.Message to middleware kafka.Message
+type message struct {
+	m     *kafka.Message
+	topic string
+	group string
+
+	// written message has no partition and offset: writer doesn't report them
+	written bool
+}
+
+func (m *message) Topic() string {
+	if m.m.Topic != "" {
+		return m.m.Topic
+	}
+
+	return m.topic
+}
+
+func (m *message) Partition() int32 {
+	if m.written {
+		return -1
+	}
+
+	return int32(m.m.Partition)
+}
+
+func (m *message) Offset() int64 {
+	if m.written {
+		return -1
+	}
+
+	return m.m.Offset
+}
+
+func (m *message) Key() []byte {
+	return m.m.Key
+}
+
+func (m *message) Value() []byte {
+	return m.m.Value
+}
+
+func (m *message) Timestamp() time.Time {
+	return m.m.Time
+}
+
+func (m *message) Group() string {
+	return m.group
+}
+
+func (m *message) Lag() int64 {
+	if m.written || m.m.HighWaterMark == 0 {
+		return -1
+	}
+
+	if lag := m.m.HighWaterMark - m.m.Offset - 1; lag > 0 {
+		return lag
+	}
+
+	return 0
+}
+
+func (m *message) Header(key string) string {
+	for _, h := range m.m.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+
+	return ""
+}
+
+func (m *message) SetHeader(key, value string) {
+	for i, h := range m.m.Headers {
+		if h.Key == key {
+			m.m.Headers[i].Value = []byte(value)
+			return
+		}
+	}
+
+	m.m.Headers = append(m.m.Headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+func (m *message) HeaderKeys() []string {
+	keys := make([]string, 0, len(m.m.Headers))
+	for _, h := range m.m.Headers {
+		keys = append(keys, h.Key)
+	}
+
+	return keys
+}
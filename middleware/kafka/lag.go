@@ -0,0 +1,88 @@
+package kafka
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/metric"
+)
+
+// LagStat keeps lag and offset after the last processed record of partitions and exposes them as gauges
+type LagStat struct {
+	mx   sync.Mutex
+	last map[lagKey]lagValue
+
+	lag    metric.Int64ObservableGauge
+	offset metric.Int64ObservableGauge
+	reg    metric.Registration
+}
+
+type lagKey struct {
+	group     string
+	topic     string
+	partition int32
+}
+
+type lagValue struct {
+	lag    int64
+	offset int64
+}
+
+func newLagStat(meter metric.Meter) (*LagStat, error) {
+	s := &LagStat{last: make(map[lagKey]lagValue)}
+
+	var err error
+
+	s.lag, err = meter.Int64ObservableGauge(ConsumerLag, metric.WithUnit("{message}"))
+	if err != nil {
+		return nil, err
+	}
+
+	s.offset, err = meter.Int64ObservableGauge(ConsumerOffset)
+	if err != nil {
+		return nil, err
+	}
+
+	s.reg, err = meter.RegisterCallback(s.callback, s.lag, s.offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Close unregister metric callback
+func (s *LagStat) Close() error {
+	return s.reg.Unregister()
+}
+
+func (s *LagStat) record(group, topic string, partition int32, lag, offset int64) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	s.last[lagKey{group: group, topic: topic, partition: partition}] = lagValue{lag: lag, offset: offset}
+}
+
+// forget partitions which are not consumed anymore: revoked or lost by consumer group member
+func (s *LagStat) forget(group, topic string, partitions ...int32) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	for _, p := range partitions {
+		delete(s.last, lagKey{group: group, topic: topic, partition: p})
+	}
+}
+
+func (s *LagStat) callback(_ context.Context, o metric.Observer) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	for k, v := range s.last {
+		opt := metric.WithAttributes(Group.String(k.group), Topic.String(k.topic), Partition.Int64(int64(k.partition)))
+
+		o.ObserveInt64(s.lag, v.lag, opt)
+		o.ObserveInt64(s.offset, v.offset, opt)
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package kafka
+
+import (
+	"context"
+	"time"
+
+	"github.com/tel-io/tel/v2"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Logs writes entry per consumed or produced record with topic, partition, offset, group and lag fields,
+// record value is dumped on handler error or always with WithDump
+type Logs struct {
+	nameFn NameFn
+
+	dumpPayloadOnError bool
+	dump               bool
+}
+
+func NewLogs(fn NameFn, dumpPayloadOnError, dump bool) *Logs {
+	return &Logs{
+		nameFn:             fn,
+		dumpPayloadOnError: dumpPayloadOnError,
+		dump:               dump,
+	}
+}
+
+func (t *Logs) apply(next MsgHandler) MsgHandler {
+	return func(ctx context.Context, msg Message) (err error) {
+		defer func(start time.Time) {
+			var (
+				kind = KindOfContext(ctx)
+				tele = tel.FromCtx(ctx).Copy()
+				// produced record gets partition and offset after acknowledgement
+				l   = tele.PutAttr(ExtractAttributes(msg, kind)...).With()
+				lvl = zapcore.DebugLevel
+			)
+
+			if err != nil {
+				lvl = zapcore.ErrorLevel
+				l = l.With(zap.Error(err))
+			}
+
+			if ((t.dumpPayloadOnError && err != nil) || t.dump) && msg.Value() != nil {
+				l = l.With(zap.String(PayloadKey, string(msg.Value())))
+			}
+
+			if ce := l.Check(lvl, t.nameFn(kind, msg)); ce != nil {
+				ce.Write(tel.String(string(Duration), time.Since(start).String()))
+			}
+		}(time.Now())
+
+		return next(ctx, msg)
+	}
+}
@@ -0,0 +1,53 @@
+package kafka
+
+import (
+	"context"
+	"time"
+)
+
+// Message is view of kafka record used by middleware, adapters of client libraries implement it
+type Message interface {
+	Topic() string
+	// Partition of record, -1 if it isn't known yet: produced record before acknowledgement
+	Partition() int32
+	// Offset of record, -1 if it isn't known yet: produced record before acknowledgement
+	Offset() int64
+	Key() []byte
+	Value() []byte
+	// Timestamp of record, zero if it isn't set
+	Timestamp() time.Time
+	// Group of consumer, empty for producer and consumer without group
+	Group() string
+	// Lag of consumer after the record: high watermark of partition - offset - 1, -1 if it's unknown
+	Lag() int64
+
+	// Header returns value of the first header with key
+	Header(key string) string
+	// SetHeader replaces value of header with key
+	SetHeader(key, value string)
+	HeaderKeys() []string
+}
+
+// MsgHandler handles record viewed as Message: ctx carries tel and span of the record,
+// error marks consumed record as failed or reports produce failure returned by client
+type MsgHandler func(ctx context.Context, msg Message) error
+
+// HeaderCarrier adapts Message headers to propagation.TextMapCarrier
+type HeaderCarrier struct {
+	Msg Message
+}
+
+// Get returns the value associated with the passed key.
+func (c HeaderCarrier) Get(key string) string {
+	return c.Msg.Header(key)
+}
+
+// Set stores the key-value pair.
+func (c HeaderCarrier) Set(key, value string) {
+	c.Msg.SetHeader(key, value)
+}
+
+// Keys lists the keys stored in this carrier.
+func (c HeaderCarrier) Keys() []string {
+	return c.Msg.HeaderKeys()
+}
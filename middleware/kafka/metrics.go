@@ -0,0 +1,101 @@
+package kafka
+
+import (
+	"context"
+	"time"
+
+	"github.com/tel-io/tel/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+type metrics struct {
+	counters       map[string]metric.Int64Counter
+	valueRecorders map[string]metric.Float64Histogram
+
+	lag *LagStat
+}
+
+func createMeasures(tele tel.Telemetry, meter metric.Meter) *metrics {
+	counters := make(map[string]metric.Int64Counter)
+	valueRecorders := make(map[string]metric.Float64Histogram)
+
+	for _, name := range []string{Count, ContentLength, ConsumerRebalances} {
+		c, err := meter.Int64Counter(name)
+		if err != nil {
+			tele.Panic("kafka mw", tel.String("key", name))
+		}
+
+		counters[name] = c
+	}
+
+	for _, name := range []string{Latency, EndToEnd} {
+		h, err := meter.Float64Histogram(name, metric.WithUnit("ms"))
+		if err != nil {
+			tele.Panic("kafka mw", tel.String("key", name))
+		}
+
+		valueRecorders[name] = h
+	}
+
+	lag, err := newLagStat(meter)
+	if err != nil {
+		tele.Panic("kafka mw", tel.String("key", ConsumerLag))
+	}
+
+	return &metrics{
+		counters:       counters,
+		valueRecorders: valueRecorders,
+		lag:            lag,
+	}
+}
+
+// Metrics implement Middleware interface
+type Metrics struct {
+	*metrics
+}
+
+func NewMetrics(m *metrics) *Metrics {
+	return &Metrics{metrics: m}
+}
+
+func (t *Metrics) apply(next MsgHandler) MsgHandler {
+	return func(ctx context.Context, msg Message) (err error) {
+		kind := KindOfContext(ctx)
+
+		defer func(start time.Time) {
+			if ctx.Err() != nil {
+				err = ctx.Err()
+				ctx = tel.FromCtx(ctx).Ctx()
+			}
+
+			attr := []attribute.KeyValue{
+				IsError.Bool(err != nil),
+				Topic.String(msg.Topic()),
+				Kind.String(kind),
+			}
+
+			if g := msg.Group(); g != "" {
+				attr = append(attr, Group.String(g))
+			}
+
+			t.counters[Count].Add(ctx, 1, metric.WithAttributes(attr...))
+			t.counters[ContentLength].Add(ctx, int64(len(msg.Value())), metric.WithAttributes(attr...))
+			t.valueRecorders[Latency].Record(ctx, float64(time.Since(start).Milliseconds()),
+				metric.WithAttributes(attr...),
+			)
+
+			if kind == KindConsume && msg.Lag() >= 0 && msg.Partition() >= 0 {
+				t.lag.record(msg.Group(), msg.Topic(), msg.Partition(), msg.Lag(), msg.Offset())
+			}
+		}(time.Now())
+
+		if kind == KindConsume && !msg.Timestamp().IsZero() {
+			t.valueRecorders[EndToEnd].Record(ctx, float64(time.Since(msg.Timestamp()).Milliseconds()),
+				metric.WithAttributes(Topic.String(msg.Topic())),
+			)
+		}
+
+		return next(ctx, msg)
+	}
+}
@@ -0,0 +1,209 @@
+package kafka
+
+import (
+	"strings"
+
+	"github.com/tel-io/tel/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Option allows configuration of the kafka middleware
+type Option interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (o optionFunc) apply(c *config) {
+	o(c)
+}
+
+// NameFn names spans and log entries of record operations
+type NameFn func(kind string, msg Message) string
+
+// defaultOperationFn default name convention: KAFKA:CONSUME/group/topic, KAFKA:PRODUCE/topic
+func defaultOperationFn(kind string, msg Message) string {
+	var b strings.Builder
+
+	b.WriteString("KAFKA:")
+	b.WriteString(kind)
+
+	if kind != KindProduce {
+		b.WriteByte('/')
+		b.WriteString(msg.Group())
+	}
+
+	b.WriteByte('/')
+	b.WriteString(msg.Topic())
+
+	return b.String()
+}
+
+type config struct {
+	tele    tel.Telemetry
+	meter   metric.Meter
+	metrics *metrics
+
+	// set by WithTracerProvider and WithMeterProvider, nil means providers of tele
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	tracer         trace.Tracer
+
+	propagators propagation.TextMapPropagator
+
+	dump               bool
+	dumpPayloadOnError bool
+
+	nameFn NameFn
+
+	// middleware processors
+	notUserDefaultMW bool
+	consumerList     []Middleware
+	producerList     []Middleware
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{
+		tele:               tel.Global(),
+		propagators:        otel.GetTextMapPropagator(),
+		dumpPayloadOnError: true,
+		nameFn:             defaultOperationFn,
+	}
+
+	for _, o := range opts {
+		o.apply(c)
+	}
+
+	if c.meterProvider != nil {
+		c.meter = c.meterProvider.Meter(
+			instrumentationName,
+			metric.WithInstrumentationVersion(SemVersion()),
+		)
+	} else {
+		c.meter = c.tele.Meter(
+			instrumentationName,
+			metric.WithInstrumentationVersion(SemVersion()),
+		)
+	}
+
+	if c.tracerProvider != nil {
+		c.tracer = c.tracerProvider.Tracer(
+			instrumentationName,
+			trace.WithInstrumentationVersion(SemVersion()),
+		)
+	}
+
+	c.metrics = createMeasures(c.tele, c.meter)
+
+	return c
+}
+
+// DefaultMiddleware recovery, logs, tracer and metrics, the last is the outermost
+func (c *config) DefaultMiddleware() []Middleware {
+	return []Middleware{
+		NewRecovery(),
+		NewLogs(c.nameFn, c.dumpPayloadOnError, c.dump),
+		&Tracer{
+			tracer:      c.tracer,
+			nameFn:      c.nameFn,
+			propagators: c.propagators,
+		},
+		NewMetrics(c.metrics),
+	}
+}
+
+func (c *config) consumerMiddleware() []Middleware {
+	if c.notUserDefaultMW {
+		return c.consumerList
+	}
+
+	return append(c.DefaultMiddleware(), c.consumerList...)
+}
+
+func (c *config) producerMiddleware() []Middleware {
+	if c.notUserDefaultMW {
+		return c.producerList
+	}
+
+	return append(c.DefaultMiddleware(), c.producerList...)
+}
+
+// WithTel used for records without tel in ctx, its meter and tracer are used unless providers are overridden
+//
+// Default: tel.Global()
+func WithTel(t tel.Telemetry) Option {
+	return optionFunc(func(c *config) {
+		c.tele = t
+	})
+}
+
+// WithTracerProvider start producer and consumer spans with tp instead of tel tracer provider
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return optionFunc(func(c *config) {
+		c.tracerProvider = tp
+	})
+}
+
+// WithMeterProvider create record and lag instruments with mp instead of tel meter provider
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return optionFunc(func(c *config) {
+		c.meterProvider = mp
+	})
+}
+
+// WithPropagators of trace context and baggage via record headers
+//
+// Default: otel.GetTextMapPropagator()
+func WithPropagators(p propagation.TextMapPropagator) Option {
+	return optionFunc(func(c *config) {
+		if p != nil {
+			c.propagators = p
+		}
+	})
+}
+
+// WithDump dump record value as plain text to log
+func WithDump(enable bool) Option {
+	return optionFunc(func(c *config) {
+		c.dump = enable
+	})
+}
+
+// WithDumpPayloadOnError write dump of record value on faults
+//
+// Default: true
+func WithDumpPayloadOnError(enable bool) Option {
+	return optionFunc(func(c *config) {
+		c.dumpPayloadOnError = enable
+	})
+}
+
+func WithNameFunction(fn NameFn) Option {
+	return optionFunc(func(c *config) {
+		c.nameFn = fn
+	})
+}
+
+// WithConsumerMiddleware for consumer handlers
+func WithConsumerMiddleware(list ...Middleware) Option {
+	return optionFunc(func(c *config) {
+		c.consumerList = append(c.consumerList, list...)
+	})
+}
+
+// WithProducerMiddleware for producers
+func WithProducerMiddleware(list ...Middleware) Option {
+	return optionFunc(func(c *config) {
+		c.producerList = append(c.producerList, list...)
+	})
+}
+
+// WithDisableDefaultMiddleware leaves only middleware of WithConsumerMiddleware and WithProducerMiddleware
+func WithDisableDefaultMiddleware() Option {
+	return optionFunc(func(c *config) {
+		c.notUserDefaultMW = true
+	})
+}
@@ -0,0 +1,23 @@
+package kafka
+
+import (
+	"context"
+
+	"github.com/tel-io/instrumentation/module/telmsg"
+)
+
+// Recovery turns panic of record handler into error: franz ProcessFetches and kafkago Consume stop on it
+// without committing the record instead of crashing the poll goroutine, Logs, Tracer and Metrics report it as failed
+type Recovery struct{}
+
+func NewRecovery() *Recovery {
+	return &Recovery{}
+}
+
+func (t *Recovery) apply(next MsgHandler) MsgHandler {
+	return func(ctx context.Context, msg Message) (err error) {
+		defer telmsg.Recover(ctx, &err)
+
+		return next(ctx, msg)
+	}
+}
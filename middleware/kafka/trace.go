@@ -0,0 +1,65 @@
+package kafka
+
+import (
+	"context"
+
+	"github.com/tel-io/instrumentation/module/telmsg"
+	"github.com/tel-io/tel/v2"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracer starts span per record: producer span injects trace context into record headers before the record
+// is handed to client, consumer span is child of span extracted from headers of fetched record.
+// Partition and offset of produced record are added to span after broker acknowledgement.
+type Tracer struct {
+	// set by WithTracerProvider, nil means tel tracer
+	tracer trace.Tracer
+
+	nameFn      NameFn
+	propagators propagation.TextMapPropagator
+}
+
+func NewTracer(fn NameFn, propagators propagation.TextMapPropagator) *Tracer {
+	return &Tracer{nameFn: fn, propagators: propagators}
+}
+
+func (t *Tracer) apply(next MsgHandler) MsgHandler {
+	return func(ctx context.Context, msg Message) error {
+		var (
+			kind    = KindOfContext(ctx)
+			carrier = HeaderCarrier{Msg: msg}
+		)
+
+		spanKind := trace.SpanKindConsumer
+		if kind == KindProduce {
+			spanKind = trace.SpanKindProducer
+		} else {
+			// baggage and remote span of producer
+			ctx = t.propagators.Extract(ctx, carrier)
+		}
+
+		span, ctx := telmsg.StartSpan(ctx, t.tracer, t.nameFn(kind, msg),
+			trace.WithSpanKind(spanKind),
+			trace.WithAttributes(SpanAttributes(msg, kind)...),
+		)
+		defer span.End()
+
+		tel.FromCtx(ctx).PutAttr(Topic.String(msg.Topic()), Kind.String(kind))
+		tel.UpdateTraceFields(ctx)
+
+		if kind == KindProduce {
+			t.propagators.Inject(ctx, carrier)
+		}
+
+		err := next(ctx, msg)
+		telmsg.SetStatus(span, err)
+
+		if kind == KindProduce {
+			// known after acknowledgement
+			span.SetAttributes(partitionAttributes(msg)...)
+		}
+
+		return err
+	}
+}
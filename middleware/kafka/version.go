@@ -0,0 +1,16 @@
+package kafka
+
+const (
+	instrumentationName = "github.com/tel-io/instrumentation/middleware/kafka"
+)
+
+// Version is the current release version of the kafka instrumentation.
+func Version() string {
+	return "1.0.0"
+	// This string is updated by the pre_release.sh script during release
+}
+
+// SemVersion is the semantic version to be supplied to tracer/meter creation.
+func SemVersion() string {
+	return "semver:" + Version()
+}
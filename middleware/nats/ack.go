@@ -0,0 +1,326 @@
+package nats
+
+import (
+	"context"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/pkg/errors"
+	"github.com/tel-io/tel/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attribute keys of JetStream message metadata
+const (
+	JSStream       = attribute.Key("js.stream")
+	JSConsumer     = attribute.Key("js.consumer")
+	JSStreamSeq    = attribute.Key("js.sequence.stream")
+	JSConsumerSeq  = attribute.Key("js.sequence.consumer")
+	JSNumDelivered = attribute.Key("js.num_delivered")
+	JSNumPending   = attribute.Key("js.num_pending")
+	JSAckAction    = attribute.Key("js.ack")
+	JSNakDelay     = attribute.Key("js.nak.delay")
+)
+
+// JetStream acknowledgement actions
+const (
+	AckActionAck        = "ack"
+	AckActionNak        = "nak"
+	AckActionTerm       = "term"
+	AckActionInProgress = "in_progress"
+)
+
+// ErrPermanent marks handler error which never succeed on redelivery, such messages are terminated
+var ErrPermanent = errors.New("permanent error")
+
+// Permanent wraps err, so default term policy terminates message instead of nak
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &permanentError{err: err}
+}
+
+type permanentError struct {
+	err error
+}
+
+func (p *permanentError) Error() string { return p.err.Error() }
+
+func (p *permanentError) Unwrap() error { return p.err }
+
+func (p *permanentError) Is(target error) bool { return target == ErrPermanent }
+
+// BackoffFn returns nak delay for the message delivered numDelivered times
+// zero means redeliver immediately
+type BackoffFn func(numDelivered uint64) time.Duration
+
+// TermFn reports handler error as permanent, message is terminated and never redelivered
+type TermFn func(err error) bool
+
+// ExponentialBackoff doubles nak delay on every delivery starting from min up to max
+func ExponentialBackoff(min, max time.Duration) BackoffFn {
+	return func(numDelivered uint64) time.Duration {
+		d := min
+
+		for i := uint64(1); i < numDelivered && d < max; i++ {
+			d *= 2
+		}
+
+		if d > max {
+			return max
+		}
+
+		return d
+	}
+}
+
+// AckOption configure JetStreamAck middleware
+type AckOption func(*JetStreamAck)
+
+// WithNakBackoff set nak delay policy
+//
+// Default: ExponentialBackoff(time.Second, time.Minute)
+func WithNakBackoff(fn BackoffFn) AckOption {
+	return func(a *JetStreamAck) {
+		a.backoff = fn
+	}
+}
+
+// WithTermPolicy set policy which errors terminate message
+//
+// Default: errors.Is(err, ErrPermanent)
+func WithTermPolicy(fn TermFn) AckOption {
+	return func(a *JetStreamAck) {
+		a.term = fn
+	}
+}
+
+// JetStreamAck implementing Middleware: acks JetStream message when handler returned nil,
+// naks it with backoff on error and terminates it on permanent errors.
+// Message metadata is put into span attributes, ack outcome and redeliveries are measured per stream/consumer.
+//
+// Messages which are not JetStream messages and messages already acknowledged by handler are passed as is.
+type JetStreamAck struct {
+	*metrics
+
+	backoff BackoffFn
+	term    TermFn
+}
+
+func NewJetStreamAck(m *metrics, opts ...AckOption) *JetStreamAck {
+	a := &JetStreamAck{
+		metrics: m,
+		backoff: ExponentialBackoff(time.Second, time.Minute),
+		term: func(err error) bool {
+			return errors.Is(err, ErrPermanent)
+		},
+	}
+
+	for _, o := range opts {
+		o(a)
+	}
+
+	return a
+}
+
+func (a *JetStreamAck) apply(next MsgHandler) MsgHandler {
+	return func(ctx context.Context, msg *nats.Msg) error {
+		m := newAcker(ctx, msg)
+
+		meta, err := m.metadata()
+		if err != nil {
+			// not JetStream message
+			return next(ctx, msg)
+		}
+
+		attrs := metric.WithAttributes(JSStream.String(meta.Stream), JSConsumer.String(meta.Consumer))
+
+		trace.SpanFromContext(ctx).SetAttributes(
+			JSStream.String(meta.Stream),
+			JSConsumer.String(meta.Consumer),
+			JSStreamSeq.Int64(int64(meta.Sequence.Stream)),
+			JSConsumerSeq.Int64(int64(meta.Sequence.Consumer)),
+			JSNumDelivered.Int64(int64(meta.NumDelivered)),
+			JSNumPending.Int64(int64(meta.NumPending)),
+		)
+
+		if !meta.Timestamp.IsZero() {
+			a.valueRecorders[JSSincePublish].Record(ctx, float64(time.Since(meta.Timestamp).Milliseconds()), attrs)
+		}
+
+		if meta.NumDelivered > 1 {
+			a.counters[JSRedeliveries].Add(ctx, 1, attrs)
+		}
+
+		ctx = context.WithValue(ctx, ackerKey{}, &ackState{acker: m, metrics: a.metrics, attrs: attrs})
+
+		err = next(ctx, msg)
+		a.settle(ctx, m, meta, err, attrs)
+
+		return err
+	}
+}
+
+// settle acks, terminates or naks message by handler outcome
+func (a *JetStreamAck) settle(ctx context.Context, m acker, meta *jetstream.MsgMetadata, err error,
+	attrs metric.MeasurementOption) {
+	switch {
+	case err == nil:
+		a.do(ctx, m, AckActionAck, 0, attrs)
+	case a.term(err):
+		a.do(ctx, m, AckActionTerm, 0, attrs)
+	default:
+		a.do(ctx, m, AckActionNak, a.backoff(meta.NumDelivered), attrs)
+	}
+}
+
+func (a *JetStreamAck) do(ctx context.Context, m acker, action string, delay time.Duration, attrs metric.MeasurementOption) {
+	var err error
+
+	switch action {
+	case AckActionAck:
+		err = m.ack()
+	case AckActionTerm:
+		err = m.term()
+	default:
+		err = m.nak(delay)
+	}
+
+	// handler decided by itself
+	if isAlreadyAcked(err) {
+		return
+	}
+
+	if action == AckActionNak {
+		record(ctx, a.metrics, action, err, attrs, JSNakDelay.String(delay.String()))
+		return
+	}
+
+	record(ctx, a.metrics, action, err, attrs)
+}
+
+// InProgress resets redelivery timer of JetStream message processed by ctx handler.
+// Works only with JetStreamAck middleware, returns nats.ErrNotJSMessage otherwise.
+func InProgress(ctx context.Context) error {
+	s, ok := ctx.Value(ackerKey{}).(*ackState)
+	if !ok {
+		return nats.ErrNotJSMessage
+	}
+
+	err := s.acker.inProgress()
+	record(ctx, s.metrics, AckActionInProgress, err, s.attrs)
+
+	return errors.WithStack(err)
+}
+
+// record put ack outcome into span event, metrics and log on failure
+func record(ctx context.Context, m *metrics, action string, err error, attrs metric.MeasurementOption,
+	extra ...attribute.KeyValue) {
+	ev := append([]attribute.KeyValue{JSAckAction.String(action), IsError.Bool(err != nil)}, extra...)
+	trace.SpanFromContext(ctx).AddEvent(action, trace.WithAttributes(ev...))
+
+	m.counters[ackCounters[action]].Add(ctx, 1, attrs, metric.WithAttributes(IsError.Bool(err != nil)))
+
+	if err != nil {
+		tel.FromCtx(ctx).Error("jetstream "+action, tel.Error(err))
+	}
+}
+
+var ackCounters = map[string]string{
+	AckActionAck:        JSAcks,
+	AckActionNak:        JSNaks,
+	AckActionTerm:       JSTerms,
+	AckActionInProgress: JSInProgress,
+}
+
+type ackerKey struct{}
+
+type ackState struct {
+	acker   acker
+	metrics *metrics
+	attrs   metric.MeasurementOption
+}
+
+// acker unify JetStream message of legacy and jetstream package API
+type acker interface {
+	metadata() (*jetstream.MsgMetadata, error)
+	ack() error
+	nak(delay time.Duration) error
+	term() error
+	inProgress() error
+}
+
+func newAcker(ctx context.Context, msg *nats.Msg) acker {
+	if m, ok := JetStreamMsgFromContext(ctx); ok {
+		return &jsAcker{msg: m}
+	}
+
+	return &legacyAcker{msg: msg}
+}
+
+type jsAcker struct {
+	msg jetstream.Msg
+}
+
+func (j *jsAcker) metadata() (*jetstream.MsgMetadata, error) { return j.msg.Metadata() }
+
+func (j *jsAcker) ack() error { return j.msg.Ack() }
+
+func (j *jsAcker) nak(delay time.Duration) error {
+	if delay > 0 {
+		return j.msg.NakWithDelay(delay)
+	}
+
+	return j.msg.Nak()
+}
+
+func (j *jsAcker) term() error { return j.msg.Term() }
+
+func (j *jsAcker) inProgress() error { return j.msg.InProgress() }
+
+type legacyAcker struct {
+	msg *nats.Msg
+}
+
+func (l *legacyAcker) metadata() (*jetstream.MsgMetadata, error) {
+	m, err := l.msg.Metadata()
+	if err != nil {
+		return nil, err
+	}
+
+	return &jetstream.MsgMetadata{
+		Sequence: jetstream.SequencePair{
+			Consumer: m.Sequence.Consumer,
+			Stream:   m.Sequence.Stream,
+		},
+		NumDelivered: m.NumDelivered,
+		NumPending:   m.NumPending,
+		Timestamp:    m.Timestamp,
+		Stream:       m.Stream,
+		Consumer:     m.Consumer,
+		Domain:       m.Domain,
+	}, nil
+}
+
+func (l *legacyAcker) ack() error { return l.msg.Ack() }
+
+func (l *legacyAcker) nak(delay time.Duration) error {
+	if delay > 0 {
+		return l.msg.NakWithDelay(delay)
+	}
+
+	return l.msg.Nak()
+}
+
+func (l *legacyAcker) term() error { return l.msg.Term() }
+
+func (l *legacyAcker) inProgress() error { return l.msg.InProgress() }
+
+func isAlreadyAcked(err error) bool {
+	return errors.Is(err, nats.ErrMsgAlreadyAckd) || errors.Is(err, jetstream.ErrMsgAlreadyAckd)
+}
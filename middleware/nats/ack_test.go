@@ -0,0 +1,75 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+func (s *Suite) TestJetStreamAck() {
+	conn := s.runServer()
+
+	js, err := jetstream.New(conn)
+	s.Require().NoError(err)
+
+	ctx := s.tel.Ctx()
+
+	stream, err := js.CreateStream(ctx, jetstream.StreamConfig{Name: "ACK", Subjects: []string{"ack.>"}})
+	s.Require().NoError(err)
+
+	cons, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Durable:   "worker",
+		AckPolicy: jetstream.AckExplicitPolicy,
+	})
+	s.Require().NoError(err)
+
+	for _, v := range []string{"retry", "permanent"} {
+		_, err = js.Publish(ctx, "ack.demo", []byte(v))
+		s.Require().NoError(err)
+	}
+
+	delivered := map[string]uint64{}
+
+	mw := s.newCore(WithJetStreamAck(WithNakBackoff(func(uint64) time.Duration { return 0 })))
+
+	consumer := mw.WrapConsumer(cons)
+
+	handler := func(ctx context.Context, msg jetstream.Msg) error {
+		meta, err := msg.Metadata()
+		s.Require().NoError(err)
+
+		delivered[string(msg.Data())] = meta.NumDelivered
+
+		s.NoError(InProgress(ctx))
+
+		switch {
+		case string(msg.Data()) == "permanent":
+			return Permanent(fmt.Errorf("bad payload"))
+		case meta.NumDelivered == 1:
+			return fmt.Errorf("temporary")
+		}
+
+		return nil
+	}
+
+	for i := 0; i < 3; i++ {
+		s.Require().NoError(consumer.Fetch(ctx, 1, handler, jetstream.FetchMaxWait(time.Second)))
+	}
+
+	s.Equal(map[string]uint64{"retry": 2, "permanent": 1}, delivered)
+
+	// everything is acked or terminated
+	s.Eventually(func() bool {
+		info, err := cons.Info(ctx)
+		return err == nil && info.NumAckPending == 0 && info.NumPending == 0
+	}, time.Second, 10*time.Millisecond)
+
+	// not JetStream message is passed as is
+	s.ErrorIs(InProgress(ctx), nats.ErrNotJSMessage)
+
+	next := func(ctx context.Context, msg *nats.Msg) error { return nil }
+	s.NoError(NewJetStreamAck(mw.metrics).apply(next)(ctx, &nats.Msg{Subject: "core"}))
+}
@@ -0,0 +1,331 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/pkg/errors"
+	"github.com/tel-io/instrumentation/middleware/nats/v2/natsprop"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attribute keys of batch span
+const (
+	BatchSize   = attribute.Key("batch.size")
+	BatchFailed = attribute.Key("batch.failed")
+)
+
+// BatchHandler handles batch of messages at once
+// ctx carries batch span which is linked to producer span of every message.
+// Return BatchError for partial failure, any other error fails every message of batch.
+type BatchHandler func(ctx context.Context, msgs []*nats.Msg) error
+
+// BatchError per-message outcome of batch: index of message in batch -> error
+type BatchError map[int]error
+
+func (b BatchError) Error() string {
+	idx := make([]int, 0, len(b))
+	for i := range b {
+		idx = append(idx, i)
+	}
+
+	if len(idx) == 0 {
+		return "batch: no failed messages"
+	}
+
+	sort.Ints(idx)
+
+	return fmt.Sprintf("batch: %d messages failed, message %d: %s", len(idx), idx[0], b[idx[0]])
+}
+
+// batchErr returns outcome of i message of batch
+func batchErr(err error, i int) error {
+	var be BatchError
+	if errors.As(err, &be) {
+		return be[i]
+	}
+
+	return err
+}
+
+// BatchOption configure push batching
+type BatchOption func(*batchConfig)
+
+type batchConfig struct {
+	size int
+	wait time.Duration
+}
+
+// WithBatchSize set max number of messages in batch
+//
+// Default: 100
+func WithBatchSize(n int) BatchOption {
+	return func(c *batchConfig) {
+		if n > 0 {
+			c.size = n
+		}
+	}
+}
+
+// WithBatchWait set max time batch is collected since its first message
+//
+// Default: time.Second
+func WithBatchWait(d time.Duration) BatchOption {
+	return func(c *batchConfig) {
+		if d > 0 {
+			c.wait = d
+		}
+	}
+}
+
+func newBatchConfig(opts []BatchOption) *batchConfig {
+	c := &batchConfig{size: 100, wait: time.Second}
+
+	for _, o := range opts {
+		o(c)
+	}
+
+	return c
+}
+
+// SubscribeBatch will express interest in the given subject, messages are collected into batches by size or time
+// and processed by cb. See QueueSubscribeBatch
+func (c *CommonSubscribe) SubscribeBatch(subj string, cb BatchHandler, opts ...BatchOption) (*nats.Subscription, error) {
+	return c.QueueSubscribeBatch(subj, "", cb, opts...)
+}
+
+// QueueSubscribeBatch creates queue subscription, messages are collected into batches by size or time
+// and processed by cb in single goroutine. Rest of messages are processed when subscription is closed.
+//
+// Subscription callback blocks while batch is handled, so incoming messages wait in pending buffer
+// of subscription limited by nats.Subscription.SetPendingLimits rather than batch size.
+func (c *CommonSubscribe) QueueSubscribeBatch(subj, queue string, cb BatchHandler, opts ...BatchOption) (*nats.Subscription, error) {
+	cfg := newBatchConfig(opts)
+
+	var (
+		ch   = make(chan *nats.Msg)
+		done = make(chan struct{})
+	)
+
+	sub, err := c.subMeter.Hook(c.conn.QueueSubscribe(subj, queue, func(msg *nats.Msg) {
+		select {
+		case ch <- msg:
+		case <-done:
+		}
+	}))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	closed := sub.StatusChanged(nats.SubscriptionClosed)
+
+	go func() {
+		defer close(done)
+
+		c.batchLoop(sub, ch, closed, cfg, cb)
+	}()
+
+	return sub, nil
+}
+
+// batchLoop collects messages of push subscription into batches until subscription is closed
+func (c *Core) batchLoop(sub *nats.Subscription, ch chan *nats.Msg, closed <-chan nats.SubStatus,
+	cfg *batchConfig, cb BatchHandler) {
+	var (
+		items   = make([]batchItem, 0, cfg.size)
+		timeout <-chan time.Time
+	)
+
+	flush := func() {
+		timeout = nil
+
+		if len(items) == 0 {
+			return
+		}
+
+		_ = c.handleBatch(c.config.tele.Ctx(), sub.Subject, items, cb)
+		items = make([]batchItem, 0, cfg.size)
+	}
+
+	add := func(msg *nats.Msg) {
+		if len(items) == 0 {
+			timeout = time.After(cfg.wait)
+		}
+
+		items = append(items, batchItem{msg: msg, acker: &legacyAcker{msg: msg}})
+
+		if len(items) >= cfg.size {
+			flush()
+		}
+	}
+
+	for {
+		select {
+		case msg := <-ch:
+			add(msg)
+		case <-timeout:
+			flush()
+		case <-closed:
+			for {
+				select {
+				case msg := <-ch:
+					add(msg)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// FetchBatchHandler pulls a batch of messages from a stream for a pull consumer and process all of them
+// with single cb call. Batch span is child of fetch span.
+// Returns fetch error, handler errors are handled by middleware.
+//
+// ctx should contain tel context, deadline of ctx is used as fetch deadline when it's set
+// and opts have neither nats.MaxWait nor nats.Context.
+func (p *PullSubscription) FetchBatchHandler(ctx context.Context, batch int, cb BatchHandler, opts ...nats.PullOpt) error {
+	f := p.startFetch(ctx, batch)
+
+	start := time.Now()
+	msgs, err := p.Subscription.Fetch(batch, pullOpts(ctx, opts)...)
+	f.wait = time.Since(start)
+
+	items := make([]batchItem, 0, len(msgs))
+	for _, msg := range msgs {
+		items = append(items, batchItem{msg: msg, acker: &legacyAcker{msg: msg}})
+	}
+
+	_ = p.core.handleBatch(trace.ContextWithSpan(ctx, f.span), p.Subject, items, cb)
+
+	f.end(p.core.metrics, len(msgs), err)
+
+	return err
+}
+
+// FetchBatchHandler receives up to batch messages and process all of them with single cb call.
+// Batch span is child of fetch span.
+// Returns fetch error, handler errors are handled by middleware.
+func (c *Consumer) FetchBatchHandler(ctx context.Context, batch int, cb BatchHandler, opts ...jetstream.FetchOpt) error {
+	f := startFetch(ctx, c.core, &nats.Msg{Subject: c.subject()}, batch)
+
+	start := time.Now()
+	res, err := c.Consumer.Fetch(batch, opts...)
+	if err != nil {
+		f.wait = time.Since(start)
+		f.end(c.core.metrics, 0, err)
+
+		return errors.WithStack(err)
+	}
+
+	items := make([]batchItem, 0, batch)
+	for msg := range res.Messages() {
+		items = append(items, batchItem{msg: natsMsg(msg), acker: &jsAcker{msg: msg}})
+	}
+
+	f.wait = time.Since(start)
+
+	_ = c.core.handleBatch(trace.ContextWithSpan(ctx, f.span), c.subject(), items, cb)
+
+	err = res.Error()
+	f.end(c.core.metrics, len(items), err)
+
+	return errors.WithStack(err)
+}
+
+// batchItem message of batch and its acknowledgement
+type batchItem struct {
+	msg   *nats.Msg
+	acker acker
+}
+
+// handleBatch process items with cb through subscriber middleware chain as single message of batch subject
+// without payload: the chain creates batch span, logs and metrics. Span is linked to producer span of every message.
+// Outcome of every message is measured, JetStream messages are acknowledged when WithJetStreamAck is enabled.
+func (c *Core) handleBatch(ctx context.Context, subject string, items []batchItem, cb BatchHandler) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	msgs := make([]*nats.Msg, 0, len(items))
+	links := make([]trace.Link, 0, len(items))
+
+	for _, it := range items {
+		msgs = append(msgs, it.msg)
+
+		// don't fall back to ctx span: only producer spans are linked
+		if _, _, sc := natsprop.Extract(context.Background(), it.msg, c.propOpts...); sc.IsValid() {
+			links = append(links, trace.Link{
+				SpanContext: sc,
+				Attributes:  []attribute.KeyValue{Kind.String(KindPub)},
+			})
+		}
+	}
+
+	in := c.subInter(func(ctx context.Context, msg *nats.Msg) error {
+		start := time.Now()
+		err := cb(ctx, msgs)
+		latency := time.Since(start)
+
+		var ack *JetStreamAck
+		if c.ackOpts != nil {
+			ack = NewJetStreamAck(c.metrics, c.ackOpts...)
+		}
+
+		subj := Subject.String(decreaseSubjectCardinality(subject))
+
+		failed := 0
+		for i, it := range items {
+			merr := batchErr(err, i)
+			if merr != nil {
+				failed++
+			}
+
+			c.metrics.counters[BatchMessages].Add(ctx, 1, metric.WithAttributes(subj, IsError.Bool(merr != nil)))
+
+			if ack == nil {
+				continue
+			}
+
+			if meta, e := it.acker.metadata(); e == nil {
+				ack.settle(ctx, it.acker, meta, merr,
+					metric.WithAttributes(JSStream.String(meta.Stream), JSConsumer.String(meta.Consumer)))
+			}
+		}
+
+		trace.SpanFromContext(ctx).SetAttributes(BatchSize.Int(len(items)), BatchFailed.Int(failed))
+
+		attrs := metric.WithAttributes(subj, IsError.Bool(err != nil))
+		c.metrics.sizeRecorders[BatchLength].Record(ctx, int64(len(items)), attrs)
+		c.metrics.valueRecorders[BatchLatency].Record(ctx, float64(latency.Milliseconds()), attrs)
+
+		return err
+	})
+
+	ctx = WithSpanLinks(WrapKindOfContext(withBatchLen(ctx, len(items)), KindBatch), links...)
+
+	return in(ctx, &nats.Msg{Subject: subject, Sub: items[0].msg.Sub})
+}
+
+type batchLenKey struct{}
+
+// withBatchLen put number of messages handled by single chain call, see batchLen
+func withBatchLen(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, batchLenKey{}, int64(n))
+}
+
+// batchLen returns number of messages handled by chain call: batch length or 1
+func batchLen(ctx context.Context) int64 {
+	if v, ok := ctx.Value(batchLenKey{}).(int64); ok {
+		return v
+	}
+
+	return 1
+}
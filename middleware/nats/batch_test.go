@@ -0,0 +1,188 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+func (s *Suite) TestSubscribeBatch() {
+	conn := s.runServer()
+	mw := s.newCore().Use(conn)
+
+	var (
+		mx      sync.Mutex
+		batches [][]string
+	)
+
+	sub, err := mw.SubscribeBatch("batch.demo", func(ctx context.Context, msgs []*nats.Msg) error {
+		mx.Lock()
+		defer mx.Unlock()
+
+		var b []string
+		for _, msg := range msgs {
+			b = append(b, string(msg.Data))
+		}
+
+		batches = append(batches, b)
+
+		return nil
+	}, WithBatchSize(2), WithBatchWait(50*time.Millisecond))
+	s.Require().NoError(err)
+
+	for _, v := range []string{"a", "b", "c"} {
+		s.Require().NoError(mw.PublishWithContext(s.tel.Ctx(), "batch.demo", []byte(v)))
+	}
+
+	// full batch by size, rest by time
+	s.Eventually(func() bool {
+		mx.Lock()
+		defer mx.Unlock()
+
+		return len(batches) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	s.Equal([][]string{{"a", "b"}, {"c"}}, batches)
+
+	s.Require().NoError(sub.Unsubscribe())
+	s.Require().NoError(conn.Drain())
+	s.Eventually(conn.IsClosed, time.Second, 10*time.Millisecond)
+
+	s.Contains(s.buf.String(), "NATS:BATCH//batch.demo")
+}
+
+func (s *Suite) TestFetchBatchHandler() {
+	conn := s.runServer()
+
+	js, err := jetstream.New(conn)
+	s.Require().NoError(err)
+
+	ctx := s.tel.Ctx()
+
+	stream, err := js.CreateStream(ctx, jetstream.StreamConfig{Name: "BATCH", Subjects: []string{"batch.>"}})
+	s.Require().NoError(err)
+
+	cons, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Durable:   "worker",
+		AckPolicy: jetstream.AckExplicitPolicy,
+	})
+	s.Require().NoError(err)
+
+	for _, v := range []string{"ok", "broken", "ok"} {
+		_, err = js.Publish(ctx, "batch.js", []byte(v))
+		s.Require().NoError(err)
+	}
+
+	mw := s.newCore(WithJetStreamAck(WithNakBackoff(func(uint64) time.Duration { return 0 })))
+	consumer := mw.WrapConsumer(cons)
+
+	var sizes []int
+
+	handler := func(ctx context.Context, msgs []*nats.Msg) error {
+		sizes = append(sizes, len(msgs))
+
+		res := BatchError{}
+		for i, msg := range msgs {
+			if string(msg.Data) == "broken" {
+				res[i] = fmt.Errorf("broken")
+			}
+		}
+
+		if len(res) == 0 {
+			return nil
+		}
+
+		return res
+	}
+
+	s.Require().NoError(consumer.FetchBatchHandler(ctx, 10, handler, jetstream.FetchMaxWait(time.Second)))
+	// only failed message is redelivered
+	s.Require().NoError(consumer.FetchBatchHandler(ctx, 10, handler, jetstream.FetchMaxWait(time.Second)))
+
+	s.Equal([]int{3, 1}, sizes)
+	s.Contains(s.buf.String(), "batch: 1 messages failed, message 1: broken")
+}
+
+func (s *Suite) TestSubscribeBatch_SlowHandler() {
+	conn := s.runServer()
+	mw := s.newCore().Use(conn)
+
+	var (
+		mx       sync.Mutex
+		received int
+		release  = make(chan struct{})
+	)
+
+	sub, err := mw.SubscribeBatch("batch.slow", func(ctx context.Context, msgs []*nats.Msg) error {
+		<-release
+
+		mx.Lock()
+		defer mx.Unlock()
+
+		received += len(msgs)
+
+		return nil
+	}, WithBatchSize(2), WithBatchWait(10*time.Millisecond))
+	s.Require().NoError(err)
+
+	// messages published while handler is busy wait in pending buffer of subscription
+	for i := 0; i < 50; i++ {
+		s.Require().NoError(mw.PublishWithContext(s.tel.Ctx(), "batch.slow", []byte("v")))
+	}
+
+	s.Require().NoError(conn.Flush())
+	close(release)
+
+	s.Eventually(func() bool {
+		mx.Lock()
+		defer mx.Unlock()
+
+		return received == 50
+	}, 5*time.Second, 10*time.Millisecond)
+
+	dropped, err := sub.Dropped()
+	s.Require().NoError(err)
+	s.Zero(dropped)
+}
+
+func (s *Suite) TestFetchBatchHandler_Chain() {
+	conn := s.runServer()
+
+	js, err := conn.JetStream()
+	s.Require().NoError(err)
+
+	_, err = js.AddStream(&nats.StreamConfig{Name: "CHAIN", Subjects: []string{"chain.>"}})
+	s.Require().NoError(err)
+
+	for i := 0; i < 3; i++ {
+		_, err = js.Publish("chain.a", []byte("v"))
+		s.Require().NoError(err)
+	}
+
+	var kinds []string
+
+	mw := s.newCore(WithSubMiddleware(middlewareFunc(func(next MsgHandler) MsgHandler {
+		return func(ctx context.Context, msg *nats.Msg) error {
+			kinds = append(kinds, extractBaggageKind(ctx))
+			return next(ctx, msg)
+		}
+	}))).Use(conn)
+
+	mjs, err := mw.JetStream()
+	s.Require().NoError(err)
+
+	sub, err := mjs.PullSubscribeWithHandler("chain.a", "worker", func(context.Context, *nats.Msg) error { return nil })
+	s.Require().NoError(err)
+
+	// panic of batch handler is recovered by the chain
+	s.Require().NoError(sub.FetchBatchHandler(s.tel.Ctx(), 10, func(ctx context.Context, msgs []*nats.Msg) error {
+		panic("boom")
+	}, nats.MaxWait(time.Second)))
+
+	s.Equal([]string{KindBatch}, kinds, "batch passes the chain once")
+	s.Contains(s.buf.String(), "recovery info: boom")
+}
@@ -11,6 +11,9 @@ const (
 	PayloadKey = "payload"
 )
 
+// HeaderPublishTime publish time of message stamped by producer, unix nanoseconds
+const HeaderPublishTime = "Nats-Publish-Time"
+
 // Attribute keys that can be added to a span.
 const (
 	Subject  = attribute.Key("subject")
@@ -27,6 +30,9 @@ const (
 	KindRequest = "REQUEST"
 	KindRespond = "RESPOND"
 	KindReply   = "REPLY"
+	KindFetch   = "FETCH"
+	KindPubAck  = "PUBACK"
+	KindBatch   = "BATCH"
 )
 
 // Server NATS metrics
@@ -34,16 +40,66 @@ const (
 	Count         = "nats.count"          // Incoming request count total
 	ContentLength = "nats.content_length" // Incoming request bytes total
 	Latency       = "nats.duration"       // Incoming end to end duration, microseconds
+	EndToEnd      = "nats.e2e.duration"   // Time since message was published till handler start, milliseconds
 
 	SubscriptionsPendingCount = "nats.subscriptions.pending.msgs"
 	SubscriptionsPendingBytes = "nats.subscriptions.pending.bytes"
 	SubscriptionsDroppedMsgs  = "nats.subscriptions.dropped.count"
-	SubscriptionCountMsgs     = "nats.subscriptions.send.count"
+	SubscriptionDeliveredMsgs = "nats.subscriptions.delivered.count" // Messages delivered to subscription
+
+	SubscriptionsPendingLimitMsgs   = "nats.subscriptions.pending.limit.msgs"  // Pending messages limit, negative for unlimited
+	SubscriptionsPendingLimitBytes  = "nats.subscriptions.pending.limit.bytes" // Pending bytes limit, negative for unlimited
+	SubscriptionsPendingUtilization = "nats.subscriptions.pending.utilization" // Max of pending messages and bytes to limit ratio
+
+	// Deprecated: delivered messages are reported as SubscriptionDeliveredMsgs
+	SubscriptionCountMsgs = "nats.subscriptions.send.count"
+
+	FetchLatency   = "nats.fetch.duration"    // Pull fetch duration, milliseconds
+	FetchBatchSize = "nats.fetch.batch.size"  // Number of messages received by single fetch
+	FetchEmpty     = "nats.fetch.empty.count" // Fetches which received nothing
+
+	ConnEvents     = "nats.conn.events.count" // Connection events: disconnect, reconnect, slow consumer etc
+	ConnInMsgs     = "nats.conn.in.msgs"      // Messages received by connection
+	ConnOutMsgs    = "nats.conn.out.msgs"     // Messages sent by connection
+	ConnInBytes    = "nats.conn.in.bytes"     // Bytes received by connection
+	ConnOutBytes   = "nats.conn.out.bytes"    // Bytes sent by connection
+	ConnReconnects = "nats.conn.reconnects"   // Reconnects of connection
+	ConnServer     = "nats.conn.server"       // Connected server, 1 with server id and url attributes
+	ConnRTT        = "nats.conn.rtt"          // Round trip time to connected server, milliseconds
+
+	KVLatency     = "nats.kv.duration"     // KeyValue operation duration, milliseconds
+	KVWatchLag    = "nats.kv.watch.lag"    // Number of updates KeyValue watcher is behind the latest value
+	ObjectLatency = "nats.object.duration" // ObjectStore operation duration, milliseconds
+
+	MicroRequests          = "nats.micro.requests"                // Requests handled by micro service endpoint
+	MicroErrors            = "nats.micro.errors"                  // Error responses of micro service endpoint
+	MicroProcessingTime    = "nats.micro.processing_time"         // Total processing time of micro service endpoint, milliseconds
+	MicroAvgProcessingTime = "nats.micro.processing_time.average" // Average processing time of micro service endpoint, milliseconds
+	MicroInfo              = "nats.micro.info"                    // 1 with micro service name, id and version attributes
+
+	JSAcks         = "nats.js.ack.count"              // Acknowledged JetStream messages
+	JSNaks         = "nats.js.nak.count"              // Negatively acknowledged JetStream messages
+	JSTerms        = "nats.js.term.count"             // Terminated JetStream messages
+	JSInProgress   = "nats.js.in_progress.count"      // JetStream in progress acknowledgements
+	JSRedeliveries = "nats.js.redelivery.count"       // JetStream messages delivered more than once
+	JSSincePublish = "nats.js.since_publish.duration" // Time since message was stored in stream, milliseconds
+
+	BatchLength   = "nats.batch.size"           // Number of messages processed by single batch handler call
+	BatchLatency  = "nats.batch.duration"       // Batch handler duration, milliseconds
+	BatchMessages = "nats.batch.messages.count" // Messages processed by batch handler with per-message outcome
+
+	RetryCount = "nats.retry.count" // Handler retries after failed attempt
+	DLQCount   = "nats.dlq.count"   // Messages republished to dead-letter subject
 )
 
+// extractBaggageKind returns kind put by WrapKindOfContext
+// kind_of baggage is still supported for contexts created by previous versions
 func extractBaggageKind(ctx context.Context) string {
-	b := baggage.FromContext(ctx)
-	if v := b.Member(KindKey).Value(); v != "" {
+	if v, ok := ctx.Value(kindKey{}).(string); ok && v != "" {
+		return v
+	}
+
+	if v := baggage.FromContext(ctx).Member(KindKey).Value(); v != "" {
 		return v
 	}
 
@@ -1,6 +1,8 @@
 package nats
 
 import (
+	"context"
+
 	"github.com/nats-io/nats.go"
 	"github.com/pkg/errors"
 )
@@ -35,3 +37,25 @@ func (c *ConnContext) JetStream(opts ...nats.JSOpt) (*JetStreamContext, error) {
 		Core: c.Core,
 	}, nil
 }
+
+// Respond sends data as reply on request msg via producer middleware chain with KindReply.
+// Trace information is injected into reply headers, so requester could link its span with replier one.
+func (c *ConnContext) Respond(ctx context.Context, msg *nats.Msg, data []byte) error {
+	return c.RespondMsg(ctx, msg, &nats.Msg{Data: data})
+}
+
+// RespondMsg sends res as reply on request msg via producer middleware chain with KindReply.
+// Subject of res is replaced with reply subject of msg.
+func (c *ConnContext) RespondMsg(ctx context.Context, msg *nats.Msg, res *nats.Msg) error {
+	if msg.Reply == "" {
+		return errors.WithStack(nats.ErrMsgNoReply)
+	}
+
+	res.Subject = msg.Reply
+
+	ctx = WrapKindOfContext(ctx, KindReply)
+
+	return c.pubInter(func(ctx context.Context, res *nats.Msg) error {
+		return errors.WithStack(c.conn.PublishMsg(res))
+	})(ctx, res)
+}
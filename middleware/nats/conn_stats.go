@@ -0,0 +1,314 @@
+package nats
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+	"github.com/tel-io/tel/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+)
+
+// Attribute keys of connection telemetry
+const (
+	ConnName  = attribute.Key("conn.name")
+	ServerID  = attribute.Key("server.id")
+	ServerURL = attribute.Key("server.url")
+	Event     = attribute.Key("event")
+)
+
+// Connection events
+const (
+	EventDisconnect        = "disconnect"
+	EventReconnect         = "reconnect"
+	EventClosed            = "closed"
+	EventDiscoveredServers = "discovered_servers"
+	EventLameDuck          = "lame_duck"
+	EventError             = "error"
+	EventSlowConsumer      = "slow_consumer"
+)
+
+// DefaultConnRTTInterval of round trip time measurement, see WithConnRTTInterval
+const DefaultConnRTTInterval = 10 * time.Second
+
+// ConnStatMetric installs connection event handlers and exposes nats.Conn statistics
+type ConnStatMetric struct {
+	tele tel.Telemetry
+
+	// interval of RTT measurement, 0 disables it
+	rttInterval time.Duration
+
+	// *nats.Conn -> *connState
+	list   sync.Map
+	events metric.Int64Counter
+	gauges map[string]metric.Int64ObservableGauge
+	rtt    metric.Float64ObservableGauge
+
+	reg metric.Registration
+}
+
+// connState of registered connection
+type connState struct {
+	conn *nats.Conn
+
+	// the last measured RTT, 0 if it's not measured yet
+	rtt atomic.Int64
+
+	stop chan struct{}
+	once sync.Once
+}
+
+func (c *connState) close() {
+	c.once.Do(func() { close(c.stop) })
+}
+
+func newConnStatMetrics(tele tel.Telemetry, meter metric.Meter, rttInterval time.Duration) (*ConnStatMetric, error) {
+	res := &ConnStatMetric{
+		tele:        tele,
+		rttInterval: rttInterval,
+		gauges:      make(map[string]metric.Int64ObservableGauge),
+	}
+
+	var err error
+
+	if res.events, err = meter.Int64Counter(ConnEvents); err != nil {
+		return nil, errors.WithMessagef(err, "create %s", ConnEvents)
+	}
+
+	observables := make([]metric.Observable, 0, 7)
+
+	for _, name := range []string{ConnInMsgs, ConnOutMsgs, ConnInBytes, ConnOutBytes, ConnReconnects, ConnServer} {
+		var opts []metric.Int64ObservableGaugeOption
+		if name == ConnInBytes || name == ConnOutBytes {
+			opts = append(opts, metric.WithUnit("By"))
+		}
+
+		g, err := meter.Int64ObservableGauge(name, opts...)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "create %s", name)
+		}
+
+		res.gauges[name] = g
+		observables = append(observables, g)
+	}
+
+	if res.rtt, err = meter.Float64ObservableGauge(ConnRTT, metric.WithUnit("ms")); err != nil {
+		return nil, errors.WithMessagef(err, "create %s", ConnRTT)
+	}
+
+	observables = append(observables, res.rtt)
+
+	if res.reg, err = meter.RegisterCallback(res.callback, observables...); err != nil {
+		return nil, errors.WithMessagef(err, "reggister callback")
+	}
+
+	return res, nil
+}
+
+// Register connection for statistics and chain its event handlers with telemetry ones.
+// RTT is measured in background till connection close. Connection is registered only once.
+func (s *ConnStatMetric) Register(conn *nats.Conn) {
+	state := &connState{conn: conn, stop: make(chan struct{})}
+
+	if _, loaded := s.list.LoadOrStore(conn, state); loaded {
+		return
+	}
+
+	if s.rttInterval > 0 {
+		go s.measureRTT(state)
+	}
+
+	disconnect := conn.DisconnectErrHandler()
+	conn.SetDisconnectErrHandler(func(conn *nats.Conn, err error) {
+		s.event(conn, EventDisconnect, err)
+
+		if disconnect != nil {
+			disconnect(conn, err)
+		}
+	})
+
+	reconnect := conn.ReconnectHandler()
+	conn.SetReconnectHandler(func(conn *nats.Conn) {
+		s.event(conn, EventReconnect, nil)
+
+		if reconnect != nil {
+			reconnect(conn)
+		}
+	})
+
+	discovered := conn.DiscoveredServersHandler()
+	conn.SetDiscoveredServersHandler(func(conn *nats.Conn) {
+		s.event(conn, EventDiscoveredServers, nil, tel.Strings("servers", conn.DiscoveredServers()))
+
+		if discovered != nil {
+			discovered(conn)
+		}
+	})
+
+	closed := conn.ClosedHandler()
+	conn.SetClosedHandler(func(conn *nats.Conn) {
+		s.event(conn, EventClosed, conn.LastError())
+		s.forget(conn)
+
+		if closed != nil {
+			closed(conn)
+		}
+	})
+
+	asyncErr := conn.ErrorHandler()
+	conn.SetErrorHandler(func(conn *nats.Conn, sub *nats.Subscription, err error) {
+		s.asyncError(conn, sub, err)
+
+		if asyncErr != nil {
+			asyncErr(conn, sub, err)
+		}
+	})
+}
+
+// LameDuckModeHandler wraps next with telemetry handler, next could be nil
+func (s *ConnStatMetric) LameDuckModeHandler(next nats.ConnHandler) nats.ConnHandler {
+	return func(conn *nats.Conn) {
+		s.event(conn, EventLameDuck, nil)
+
+		if next != nil {
+			next(conn)
+		}
+	}
+}
+
+// Close unregister metric callback and forget all connections
+func (s *ConnStatMetric) Close() error {
+	s.list.Range(func(key, _ interface{}) bool {
+		s.forget(key)
+		return true
+	})
+
+	return errors.WithStack(s.reg.Unregister())
+}
+
+// forget connection and stop its RTT measurement
+func (s *ConnStatMetric) forget(conn interface{}) {
+	if v, ok := s.list.LoadAndDelete(conn); ok {
+		v.(*connState).close()
+	}
+}
+
+// measureRTT of connection by interval, metric callback observes the last value:
+// PING/PONG of stuck connection doesn't block collection
+func (s *ConnStatMetric) measureRTT(state *connState) {
+	ticker := time.NewTicker(s.rttInterval)
+	defer ticker.Stop()
+
+	for {
+		if state.conn.IsClosed() {
+			s.forget(state.conn)
+			return
+		}
+
+		if state.conn.IsConnected() {
+			if rtt, err := state.conn.RTT(); err == nil {
+				state.rtt.Store(int64(rtt))
+			}
+		}
+
+		select {
+		case <-state.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *ConnStatMetric) asyncError(conn *nats.Conn, sub *nats.Subscription, err error) {
+	event := EventError
+	if errors.Is(err, nats.ErrSlowConsumer) {
+		event = EventSlowConsumer
+	}
+
+	if sub == nil {
+		s.event(conn, event, err)
+		return
+	}
+
+	// attribute error to the offending subscription
+	s.event(conn, event, err,
+		tel.String(string(Subject), sub.Subject),
+		tel.String("queue", sub.Queue),
+	)
+}
+
+// event log and count connection event, subject field is used as metric attribute
+func (s *ConnStatMetric) event(conn *nats.Conn, event string, err error, fields ...zap.Field) {
+	attrs := []attribute.KeyValue{
+		ConnName.String(conn.Opts.Name),
+		Event.String(event),
+	}
+
+	for _, f := range fields {
+		if f.Key == string(Subject) {
+			attrs = append(attrs, Subject.String(decreaseSubjectCardinality(f.String)))
+		}
+	}
+
+	s.events.Add(s.tele.Ctx(), 1, metric.WithAttributes(attrs...))
+
+	fields = append(fields,
+		tel.String(string(Event), event),
+		tel.String(string(ConnName), conn.Opts.Name),
+		tel.String(string(ServerURL), conn.ConnectedUrlRedacted()),
+	)
+
+	switch {
+	case err != nil:
+		s.tele.Error("nats connection", append(fields, tel.Error(err))...)
+	case event == EventReconnect || event == EventDiscoveredServers:
+		s.tele.Info("nats connection", fields...)
+	default:
+		s.tele.Warn("nats connection", fields...)
+	}
+}
+
+func (s *ConnStatMetric) callback(_ context.Context, o metric.Observer) error {
+	s.list.Range(func(_, value interface{}) bool {
+		state, ok := value.(*connState)
+		if !ok {
+			return true
+		}
+
+		conn := state.conn
+
+		stats := conn.Stats()
+		attrs := metric.WithAttributes(ConnName.String(conn.Opts.Name))
+
+		o.ObserveInt64(s.gauges[ConnInMsgs], int64(stats.InMsgs), attrs)
+		o.ObserveInt64(s.gauges[ConnOutMsgs], int64(stats.OutMsgs), attrs)
+		o.ObserveInt64(s.gauges[ConnInBytes], int64(stats.InBytes), attrs)
+		o.ObserveInt64(s.gauges[ConnOutBytes], int64(stats.OutBytes), attrs)
+		o.ObserveInt64(s.gauges[ConnReconnects], int64(stats.Reconnects), attrs)
+
+		if !conn.IsConnected() {
+			return true
+		}
+
+		server := metric.WithAttributes(
+			ConnName.String(conn.Opts.Name),
+			ServerID.String(conn.ConnectedServerId()),
+			ServerURL.String(conn.ConnectedUrlRedacted()),
+		)
+
+		o.ObserveInt64(s.gauges[ConnServer], 1, server)
+
+		if rtt := state.rtt.Load(); rtt > 0 {
+			o.ObserveFloat64(s.rtt, float64(rtt)/float64(time.Millisecond), server)
+		}
+
+		return true
+	})
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package nats
+
+import (
+	"context"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func (s *Suite) TestConnTelemetry() {
+	core := s.newCore(WithConnTelemetry(true))
+
+	var (
+		closed   = make(chan struct{})
+		asyncErr = make(chan error, 1)
+	)
+
+	conn, err := nats.Connect(s.runServer().ConnectedUrl(),
+		nats.Name("edge"),
+		nats.ClosedHandler(func(*nats.Conn) { close(closed) }),
+		nats.ErrorHandler(func(_ *nats.Conn, _ *nats.Subscription, err error) {
+			select {
+			case asyncErr <- err:
+			default:
+			}
+		}),
+		core.ConnOption(),
+	)
+	s.Require().NoError(err)
+
+	mw := core.Use(conn)
+	// second usage doesn't chain handlers twice
+	mw = core.Use(conn)
+
+	sub, err := conn.SubscribeSync("slow.demo")
+	s.Require().NoError(err)
+	s.Require().NoError(sub.SetPendingLimits(1, 1024))
+
+	for i := 0; i < 10; i++ {
+		s.Require().NoError(conn.Publish("slow.demo", []byte("data")))
+	}
+
+	// user handler is chained
+	select {
+	case err := <-asyncErr:
+		s.ErrorIs(err, nats.ErrSlowConsumer)
+	case <-time.After(5 * time.Second):
+		s.FailNow("no slow consumer")
+	}
+
+	mw.Conn().Close()
+
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		s.FailNow("not closed")
+	}
+
+	s.Contains(s.buf.String(), EventSlowConsumer)
+	s.Contains(s.buf.String(), "slow.demo")
+	s.Contains(s.buf.String(), EventClosed)
+	s.Contains(s.buf.String(), "edge")
+}
+
+func (s *Suite) TestConnTelemetry_RTT() {
+	reader := sdkmetric.NewManualReader()
+
+	core := s.newCore(
+		WithConnTelemetry(true),
+		WithConnRTTInterval(10*time.Millisecond),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+	)
+
+	conn := s.runServer()
+	core.Use(conn)
+
+	rtt := func() int {
+		var rm metricdata.ResourceMetrics
+		s.Require().NoError(reader.Collect(context.Background(), &rm))
+
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				if m.Name == ConnRTT {
+					return len(m.Data.(metricdata.Gauge[float64]).DataPoints)
+				}
+			}
+		}
+
+		return 0
+	}
+
+	// measured in background, collection only observes the last value
+	s.Eventually(func() bool { return rtt() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	conn.Close()
+
+	s.Eventually(func() bool { return rtt() == 0 }, 5*time.Second, 10*time.Millisecond)
+}
@@ -2,18 +2,62 @@ package nats
 
 import (
 	"context"
-	"go.opentelemetry.io/otel/baggage"
+
+	"github.com/tel-io/tel/v2"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// WrapKindOfContext create baggage which update context subMiddleware related info about kind of event
-// return ctx contained baggage with KindKey
-// or just return none touched source ctx
+type linksKey struct{}
+
+type kindKey struct{}
+
+// WrapKindOfContext put kind of event into ctx for middleware
+// Kind is carried by private context key, so it's never propagated to downstream services.
 func WrapKindOfContext(ctx context.Context, kindOf string) context.Context {
-	if member, e := baggage.NewMember(KindKey, kindOf); e == nil {
-		if b, ee := baggage.New(member); ee == nil {
-			return baggage.ContextWithBaggage(ctx, b)
-		}
+	return context.WithValue(ctx, kindKey{}, kindOf)
+}
+
+// WithSpanLinks put links which Tracer attach to the next created span, for example link to fetch span
+func WithSpanLinks(ctx context.Context, links ...trace.Link) context.Context {
+	if len(links) == 0 {
+		return ctx
 	}
 
-	return ctx
+	return context.WithValue(ctx, linksKey{}, append(SpanLinks(ctx), links...))
+}
+
+// SpanLinks returns links put by WithSpanLinks
+func SpanLinks(ctx context.Context) []trace.Link {
+	if v, ok := ctx.Value(linksKey{}).([]trace.Link); ok {
+		// copy prevent races on shared backing array
+		return append([]trace.Link(nil), v...)
+	}
+
+	return nil
+}
+
+// startSpan starts span with tracer set by WithTracerProvider, tel tracer is used when it's nil.
+// Returned ctx carries tel instance with the span the same as tel.StartSpanFromContext does.
+func startSpan(ctx context.Context, tracer trace.Tracer, name string, opts ...trace.SpanStartOption) (
+	trace.Span, context.Context) {
+	if tracer == nil {
+		return tel.StartSpanFromContext(ctx, name, opts...)
+	}
+
+	t := tel.FromCtx(ctx)
+
+	// continue active span of tel instance
+	if !trace.SpanContextFromContext(ctx).IsValid() && t.Span() != nil && t.Span().IsRecording() {
+		ctx = trace.ContextWithSpan(ctx, t.Span())
+	}
+
+	ctx, span := tracer.Start(ctx, name, opts...)
+
+	tele := t.WithSpan(span)
+	tele.PutSpan(span)
+
+	ctx = tel.WrapContext(ctx, tele)
+	tel.UpdateTraceFields(ctx)
+
+	return span, ctx
 }
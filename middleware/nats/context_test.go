@@ -0,0 +1,57 @@
+package nats
+
+import (
+	"context"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/tel-io/instrumentation/middleware/nats/v2/natsprop"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func (s *Suite) TestKindNotPropagated() {
+	prev := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	defer otel.SetTextMapPropagator(prev)
+
+	conn := s.runServer()
+	mw := s.newCore(WithPropagation(natsprop.WithBaggageAllowList("tenant"))).Use(conn)
+
+	sub, err := conn.SubscribeSync("kind.demo")
+	s.Require().NoError(err)
+
+	tenant, _ := baggage.NewMember("tenant", "a")
+	secret, _ := baggage.NewMember("secret", "b")
+	bag, _ := baggage.New(tenant, secret)
+
+	s.Require().NoError(mw.PublishWithContext(baggage.ContextWithBaggage(s.tel.Ctx(), bag), "kind.demo", nil))
+
+	msg, err := sub.NextMsg(time.Second)
+	s.Require().NoError(err)
+
+	s.Equal("tenant=a", propagation.HeaderCarrier(msg.Header).Get("baggage"))
+
+	// user baggage survives kind marker
+	var got string
+
+	handler := mw.BuildWrappedHandler(func(ctx context.Context, msg *nats.Msg) error {
+		got = baggage.FromContext(ctx).String()
+		return nil
+	})
+	handler(msg)
+
+	s.Equal("tenant=a", got)
+}
+
+func (s *Suite) TestExtractBaggageKind() {
+	s.Equal(KindUnk, extractBaggageKind(context.Background()))
+	s.Equal(KindPub, extractBaggageKind(WrapKindOfContext(context.Background(), KindPub)))
+
+	// kind_of baggage of previous versions
+	m, _ := baggage.NewMember(KindKey, KindSub)
+	b, _ := baggage.New(m)
+	s.Equal(KindSub, extractBaggageKind(baggage.ContextWithBaggage(context.Background(), b)))
+}
@@ -8,8 +8,9 @@ replace github.com/tel-io/instrumentation/middleware/nats/v2 => ../
 
 require (
 	github.com/joho/godotenv v1.4.0
-	github.com/nats-io/nats.go v1.18.0
-	github.com/tel-io/instrumentation/middleware/nats/v2 v2.0.8
+	github.com/nats-io/nats.go v1.37.0
+	github.com/tel-io/instrumentation/middleware/nats/v2 v2.1.0
+	github.com/tel-io/instrumentation/module/telfields v1.0.0 // indirect
 	github.com/tel-io/tel/v2 v2.3.6
 )
 
@@ -51,15 +52,16 @@ require (
 	github.com/jackc/pgpassfile v1.0.0 // indirect
 	github.com/jackc/pgservicefile v0.0.0-20221227161230-091c0ba34f0a // indirect
 	github.com/jackc/pgx/v5 v5.6.0 // indirect
-	github.com/klauspost/compress v1.15.11 // indirect
-	github.com/minio/highwayhash v1.0.2 // indirect
-	github.com/nats-io/jwt/v2 v2.3.0 // indirect
-	github.com/nats-io/nkeys v0.3.0 // indirect
+	github.com/klauspost/compress v1.17.9 // indirect
+	github.com/nats-io/nkeys v0.4.7 // indirect
 	github.com/nats-io/nuid v1.0.1 // indirect
 	github.com/shirou/gopsutil/v4 v4.24.6 // indirect
 	github.com/shoenig/go-m1cpu v0.1.6 // indirect
-	golang.org/x/crypto v0.24.0 // indirect
-	golang.org/x/sys v0.21.0 // indirect
-	golang.org/x/time v0.0.0-20220922220347-f3bd1da661af // indirect
+	github.com/vmihailenco/msgpack/v5 v5.4.1 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	golang.org/x/crypto v0.25.0 // indirect
+	golang.org/x/sys v0.22.0 // indirect
 	google.golang.org/protobuf v1.34.2 // indirect
 )
+
+replace github.com/tel-io/instrumentation/module/telfields => ../../../module/telfields
@@ -8,13 +8,24 @@ import (
 )
 
 // ReplyFn reply helper which send reply with wrapping trace information
+// Reply isn't passed through middleware chain, use ConnContext.Respond instead.
 func ReplyFn(ctx context.Context, msg *nats.Msg, data []byte) error {
 	if msg.Reply == "" {
 		return nil
 	}
 
 	resMsg := &nats.Msg{Data: data}
-	natsprop.Inject(ctx, msg)
+	natsprop.Inject(ctx, resMsg)
 
 	return errors.WithStack(msg.RespondMsg(resMsg))
 }
+
+// cloneHeader returns deep copy of h, never nil
+func cloneHeader(h nats.Header) nats.Header {
+	res := make(nats.Header, len(h))
+	for k, v := range h {
+		res[k] = append([]string(nil), v...)
+	}
+
+	return res
+}
@@ -61,3 +61,18 @@ func (j *JetStreamContext) PullSubscribe(subj, durable string, opts ...nats.SubO
 		j.js.PullSubscribe(subj, durable, opts...),
 	)
 }
+
+// PullSubscribeWithHandler creates a pull Subscription which process every fetched message with cb
+// wrapped by middleware chain. See PullSubscribe
+func (j *JetStreamContext) PullSubscribeWithHandler(subj, durable string, cb MsgHandler, opts ...nats.SubOpt) (*PullSubscription, error) {
+	sub, err := j.PullSubscribe(subj, durable, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PullSubscription{
+		Subscription: sub,
+		core:         j.Core,
+		handler:      j.subHandler(cb),
+	}, nil
+}
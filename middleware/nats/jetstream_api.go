@@ -0,0 +1,362 @@
+package nats
+
+import (
+	"context"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/pkg/errors"
+	"github.com/tel-io/tel/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attribute keys of JetStream PubAck
+const (
+	PubAckStream    = attribute.Key("stream")
+	PubAckSequence  = attribute.Key("sequence")
+	PubAckDuplicate = attribute.Key("duplicate")
+	PubAckDomain    = attribute.Key("domain")
+)
+
+// DefaultPubAckTimeout of async publish, see WithPubAckTimeout
+const DefaultPubAckTimeout = 30 * time.Second
+
+// ErrPubAckTimeout PubAck of async publish is not received within timeout
+var ErrPubAckTimeout = errors.New("nats: pub ack timeout")
+
+// JetStreamMsgHandler handler of messages received via jetstream package API
+// Original message is available in ctx for middlewares: see JetStreamMsgFromContext
+type JetStreamMsgHandler func(ctx context.Context, msg jetstream.Msg) error
+
+type jsMsgKey struct{}
+
+// JetStreamMsgFromContext returns original message of jetstream package API.
+// Middleware chain works with *nats.Msg representation of it, which doesn't allow ack/nak.
+func JetStreamMsgFromContext(ctx context.Context) (jetstream.Msg, bool) {
+	msg, ok := ctx.Value(jsMsgKey{}).(jetstream.Msg)
+	return msg, ok
+}
+
+// JetStream wrapper for github.com/nats-io/nats.go/jetstream API
+// Management functions are used as is, publish functions are instrumented.
+// Consumers should be wrapped via WrapConsumer to process messages with middleware chain.
+type JetStream struct {
+	jetstream.JetStream
+
+	*Core
+
+	// closed when connection is closed, stops waiting of PubAck
+	closed <-chan struct{}
+}
+
+// NewJetStream returns instrumented jetstream.JetStream
+func (c *ConnContext) NewJetStream(opts ...jetstream.JetStreamOpt) (*JetStream, error) {
+	js, err := jetstream.New(c.conn, opts...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &JetStream{
+		JetStream: js,
+		Core:      c.Core,
+		closed:    c.connClosed(c.conn),
+	}, nil
+}
+
+// connClosed returns channel closed with conn.
+// Single watcher per connection is shared by all wrappers, so creating wrappers repeatedly doesn't add watchers.
+func (c *Core) connClosed(conn *nats.Conn) <-chan struct{} {
+	if v, ok := c.closedConns.Load(conn); ok {
+		return v.(chan struct{})
+	}
+
+	done := make(chan struct{})
+	if v, loaded := c.closedConns.LoadOrStore(conn, done); loaded {
+		return v.(chan struct{})
+	}
+
+	status := conn.StatusChanged(nats.CLOSED)
+
+	if conn.IsClosed() {
+		c.closedConns.Delete(conn)
+		close(done)
+
+		return done
+	}
+
+	go func() {
+		<-status
+		c.closedConns.Delete(conn)
+		close(done)
+	}()
+
+	return done
+}
+
+// JS unwrap
+func (j *JetStream) JS() jetstream.JetStream {
+	return j.JetStream
+}
+
+// Publish performs a synchronous publish to a stream and waits for ack from server.
+// PubAck is put into span attributes.
+func (j *JetStream) Publish(ctx context.Context, subj string, data []byte, opts ...jetstream.PublishOpt) (*jetstream.PubAck, error) {
+	return j.PublishMsg(ctx, &nats.Msg{Subject: subj, Data: data}, opts...)
+}
+
+// PublishMsg performs a synchronous publish to a stream and waits for ack from server.
+// PubAck is put into span attributes.
+func (j *JetStream) PublishMsg(ctx context.Context, msg *nats.Msg, opts ...jetstream.PublishOpt) (ack *jetstream.PubAck, err error) {
+	ctx = WrapKindOfContext(ctx, KindPub)
+
+	err = j.pubInter(func(ctx context.Context, msg *nats.Msg) error {
+		ack, err = j.JetStream.PublishMsg(ctx, msg, opts...)
+		if err != nil {
+			return err
+		}
+
+		trace.SpanFromContext(ctx).SetAttributes(pubAckAttributes(ack)...)
+
+		return nil
+	})(ctx, msg)
+
+	return ack, err
+}
+
+// PublishAsync performs an asynchronous publish to a stream. See PublishMsgAsyncWithContext
+func (j *JetStream) PublishAsync(subj string, data []byte, opts ...jetstream.PublishOpt) (jetstream.PubAckFuture, error) {
+	return j.PublishMsgAsyncWithContext(j.config.tele.Ctx(), &nats.Msg{Subject: subj, Data: data}, opts...)
+}
+
+// PublishMsgAsync performs an asynchronous publish to a stream. See PublishMsgAsyncWithContext
+func (j *JetStream) PublishMsgAsync(msg *nats.Msg, opts ...jetstream.PublishOpt) (jetstream.PubAckFuture, error) {
+	return j.PublishMsgAsyncWithContext(j.config.tele.Ctx(), msg, opts...)
+}
+
+// PublishAsyncWithContext performs an asynchronous publish to a stream. See PublishMsgAsyncWithContext
+func (j *JetStream) PublishAsyncWithContext(ctx context.Context, subj string, data []byte, opts ...jetstream.PublishOpt) (jetstream.PubAckFuture, error) {
+	return j.PublishMsgAsyncWithContext(ctx, &nats.Msg{Subject: subj, Data: data}, opts...)
+}
+
+// PublishMsgAsyncWithContext performs an asynchronous publish to a stream.
+// Publish goes through middleware chain, PubAck is awaited in background within own span,
+// which is child of publish span and contains PubAck attributes or error.
+func (j *JetStream) PublishMsgAsyncWithContext(ctx context.Context, msg *nats.Msg, opts ...jetstream.PublishOpt) (fut jetstream.PubAckFuture, err error) {
+	ctx = WrapKindOfContext(ctx, KindPub)
+
+	err = j.pubInter(func(ctx context.Context, msg *nats.Msg) error {
+		fut, err = j.JetStream.PublishMsgAsync(msg, opts...)
+		if err != nil {
+			return err
+		}
+
+		j.observePubAck(ctx, msg, fut)
+
+		return nil
+	})(ctx, msg)
+
+	return fut, err
+}
+
+// observePubAck waits PubAck in background till timeout or connection close
+func (j *JetStream) observePubAck(ctx context.Context, msg *nats.Msg, fut jetstream.PubAckFuture) {
+	span, ctx := startSpan(ctx, j.tracer, j.nameFn(KindPubAck, msg),
+		trace.WithSpanKind(convSpanToKind(KindPubAck)),
+		trace.WithAttributes(Subject.String(msg.Subject), Kind.String(KindPubAck)),
+	)
+
+	go func(start time.Time) {
+		defer span.End()
+
+		var timeout <-chan time.Time
+
+		if d := j.config.pubAckTimeout; d > 0 {
+			timer := time.NewTimer(d)
+			defer timer.Stop()
+
+			timeout = timer.C
+		}
+
+		var err error
+
+		select {
+		case ack := <-fut.Ok():
+			span.SetAttributes(pubAckAttributes(ack)...)
+			span.SetStatus(codes.Ok, "")
+
+			return
+		case err = <-fut.Err():
+		case <-timeout:
+			err = ErrPubAckTimeout
+		case <-j.closed:
+			err = nats.ErrConnectionClosed
+		}
+
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+
+		tel.FromCtx(ctx).Error(j.nameFn(KindPubAck, msg),
+			tel.String(string(Subject), msg.Subject),
+			tel.String(string(Duration), time.Since(start).String()),
+			tel.Error(err),
+		)
+	}(time.Now())
+}
+
+// WrapConsumer returns consumer which process messages with middleware chain.
+// Use it for consumers created by JetStream or jetstream.Stream: Consumer, CreateOrUpdateConsumer, OrderedConsumer etc.
+func (c *Core) WrapConsumer(cons jetstream.Consumer) *Consumer {
+	return &Consumer{
+		Consumer: cons,
+		core:     c,
+	}
+}
+
+// Consumer wrapper of jetstream.Consumer
+type Consumer struct {
+	jetstream.Consumer
+
+	core *Core
+}
+
+// Consume continuously receives messages and process them with handler wrapped by middleware chain.
+func (c *Consumer) Consume(cb JetStreamMsgHandler, opts ...jetstream.PullConsumeOpt) (jetstream.ConsumeContext, error) {
+	handler := c.core.jsHandler(cb)
+
+	res, err := c.Consumer.Consume(func(msg jetstream.Msg) {
+		_ = handler(c.core.config.tele.Ctx(), msg)
+	}, opts...)
+
+	return res, errors.WithStack(err)
+}
+
+// Messages returns iterator, every message obtained by MessagesContext.Next is processed with handler
+// wrapped by middleware chain.
+func (c *Consumer) Messages(cb JetStreamMsgHandler, opts ...jetstream.PullMessagesOpt) (*MessagesContext, error) {
+	it, err := c.Consumer.Messages(opts...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &MessagesContext{
+		MessagesContext: it,
+		core:            c.core,
+		handler:         c.core.jsHandler(cb),
+	}, nil
+}
+
+// Fetch receives up to batch messages and process them with handler wrapped by middleware chain
+// as soon as they are received. Fetch has own span linked to consumer span of every message.
+// Returns fetch error, handler errors are handled by middleware.
+func (c *Consumer) Fetch(ctx context.Context, batch int, cb JetStreamMsgHandler, opts ...jetstream.FetchOpt) error {
+	handler := c.core.jsHandler(cb)
+
+	f := startFetch(ctx, c.core, &nats.Msg{Subject: c.subject()}, batch)
+
+	start := time.Now()
+	res, err := c.Consumer.Fetch(batch, opts...)
+	if err != nil {
+		f.wait = time.Since(start)
+		f.end(c.core.metrics, 0, err)
+
+		return errors.WithStack(err)
+	}
+
+	received := 0
+	for msg := range res.Messages() {
+		// count only time spent for waiting messages
+		f.wait += time.Since(start)
+		received++
+
+		_ = handler(f.msgCtx(c.core), msg)
+
+		start = time.Now()
+	}
+
+	f.wait += time.Since(start)
+	err = res.Error()
+	f.end(c.core.metrics, received, err)
+
+	return errors.WithStack(err)
+}
+
+// subject filter subject of consumer or stream name
+func (c *Consumer) subject() string {
+	info := c.CachedInfo()
+	if info == nil {
+		return ""
+	}
+
+	if info.Config.FilterSubject != "" {
+		return info.Config.FilterSubject
+	}
+
+	return info.Stream
+}
+
+// MessagesContext wrapper of jetstream.MessagesContext
+type MessagesContext struct {
+	jetstream.MessagesContext
+
+	core    *Core
+	handler func(ctx context.Context, msg jetstream.Msg) error
+}
+
+// Handle retrieves next message and process it with handler.
+// Returns iteration error only, handler errors are handled by middleware.
+func (m *MessagesContext) Handle() error {
+	msg, err := m.Next()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_ = m.handler(m.core.config.tele.Ctx(), msg)
+
+	return nil
+}
+
+// jsHandler adapt handler of jetstream package API to middleware chain
+func (c *Core) jsHandler(next JetStreamMsgHandler) func(ctx context.Context, msg jetstream.Msg) error {
+	in := c.subHandler(func(ctx context.Context, _ *nats.Msg) error {
+		msg, _ := JetStreamMsgFromContext(ctx)
+
+		return next(ctx, msg)
+	})
+
+	return func(ctx context.Context, msg jetstream.Msg) error {
+		ctx = context.WithValue(ctx, jsMsgKey{}, msg)
+
+		return in(ctx, natsMsg(msg))
+	}
+}
+
+// natsMsg representation of jetstream.Msg for middleware chain
+func natsMsg(msg jetstream.Msg) *nats.Msg {
+	return &nats.Msg{
+		Subject: msg.Subject(),
+		Reply:   msg.Reply(),
+		Header:  msg.Headers(),
+		Data:    msg.Data(),
+	}
+}
+
+func pubAckAttributes(ack *jetstream.PubAck) []attribute.KeyValue {
+	if ack == nil {
+		return nil
+	}
+
+	attrs := []attribute.KeyValue{
+		PubAckStream.String(ack.Stream),
+		PubAckSequence.Int64(int64(ack.Sequence)),
+		PubAckDuplicate.Bool(ack.Duplicate),
+	}
+
+	if ack.Domain != "" {
+		attrs = append(attrs, PubAckDomain.String(ack.Domain))
+	}
+
+	return attrs
+}
@@ -0,0 +1,141 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+func (s *Suite) TestJetStreamAPI() {
+	conn := s.runServer()
+
+	mw := s.newCore().Use(conn)
+
+	js, err := mw.NewJetStream()
+	s.Require().NoError(err)
+
+	ctx := s.tel.Ctx()
+
+	stream, err := js.CreateStream(ctx, jetstream.StreamConfig{Name: "API", Subjects: []string{"api.>"}})
+	s.Require().NoError(err)
+
+	ack, err := js.Publish(ctx, "api.demo", []byte("0"))
+	s.Require().NoError(err)
+	s.Equal(uint64(1), ack.Sequence)
+
+	fut, err := js.PublishAsyncWithContext(ctx, "api.demo", []byte("1"))
+	s.Require().NoError(err)
+
+	select {
+	case ack := <-fut.Ok():
+		s.Equal(uint64(2), ack.Sequence)
+	case err := <-fut.Err():
+		s.FailNow(err.Error())
+	case <-time.After(5 * time.Second):
+		s.FailNow("no puback")
+	}
+
+	_, err = js.Publish(ctx, "api.demo", []byte("2"))
+	s.Require().NoError(err)
+
+	cons, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{Durable: "worker"})
+	s.Require().NoError(err)
+
+	var got []string
+
+	consumer := mw.WrapConsumer(cons)
+
+	err = consumer.Fetch(ctx, 2, func(ctx context.Context, msg jetstream.Msg) error {
+		got = append(got, string(msg.Data()))
+
+		// original message is available for middlewares
+		orig, ok := JetStreamMsgFromContext(ctx)
+		s.True(ok)
+		s.Equal(msg, orig)
+
+		return msg.Ack()
+	}, jetstream.FetchMaxWait(time.Second))
+	s.Require().NoError(err)
+	s.Equal([]string{"0", "1"}, got)
+
+	it, err := consumer.Messages(func(ctx context.Context, msg jetstream.Msg) error {
+		got = append(got, string(msg.Data()))
+
+		return fmt.Errorf("some error")
+	})
+	s.Require().NoError(err)
+
+	s.NoError(it.Handle())
+	it.Stop()
+
+	s.Equal([]string{"0", "1", "2"}, got)
+
+	// handler error is logged by middleware
+	s.Contains(s.buf.String(), "some error")
+	s.Contains(s.buf.String(), "api.demo")
+}
+
+func (s *Suite) TestJetStreamAPI_PubAckLost() {
+	conn := s.runServer()
+
+	// subscriber without reply: PubAck never arrives
+	_, err := conn.Subscribe("lost.>", func(*nats.Msg) {})
+	s.Require().NoError(err)
+
+	js, err := s.newCore(WithPubAckTimeout(100 * time.Millisecond)).Use(conn).NewJetStream()
+	s.Require().NoError(err)
+
+	_, err = js.PublishAsyncWithContext(s.tel.Ctx(), "lost.timeout", []byte("1"))
+	s.Require().NoError(err)
+
+	s.Eventually(func() bool {
+		return strings.Contains(s.buf.String(), ErrPubAckTimeout.Error())
+	}, 5*time.Second, 10*time.Millisecond)
+
+	js, err = s.newCore(WithPubAckTimeout(0)).Use(conn).NewJetStream()
+	s.Require().NoError(err)
+
+	_, err = js.PublishAsyncWithContext(s.tel.Ctx(), "lost.closed", []byte("1"))
+	s.Require().NoError(err)
+
+	conn.Close()
+
+	s.Eventually(func() bool {
+		return strings.Contains(s.buf.String(), nats.ErrConnectionClosed.Error())
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func (s *Suite) TestJetStreamAPI_SharedCloseWatcher() {
+	conn := s.runServer()
+	mw := s.newCore().Use(conn)
+
+	first, err := mw.NewJetStream()
+	s.Require().NoError(err)
+
+	before := runtime.NumGoroutine()
+
+	for range 100 {
+		js, err := mw.NewJetStream()
+		s.Require().NoError(err)
+		s.Equal(first.closed, js.closed)
+	}
+
+	s.Less(runtime.NumGoroutine()-before, 10, "wrappers share watcher of connection")
+
+	conn.Close()
+
+	select {
+	case <-first.closed:
+	case <-time.After(5 * time.Second):
+		s.FailNow("watcher isn't closed with connection")
+	}
+
+	js, err := mw.NewJetStream()
+	s.Require().NoError(err)
+	s.NotEqual(first.closed, js.closed, "closed connection isn't watched")
+}
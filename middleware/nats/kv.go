@@ -0,0 +1,242 @@
+package nats
+
+import (
+	"context"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// KVWatchHandler handler of KeyValue watcher updates of legacy API
+type KVWatchHandler func(ctx context.Context, entry nats.KeyValueEntry) error
+
+// JetStreamKVWatchHandler handler of KeyValue watcher updates of jetstream package API
+type JetStreamKVWatchHandler func(ctx context.Context, entry jetstream.KeyValueEntry) error
+
+// KVHandlerWatcher watcher of WatchWithHandler. Updates are consumed by handler, so watcher can only be stopped.
+type KVHandlerWatcher interface {
+	// Stop watching, handler isn't called afterwards
+	Stop() error
+}
+
+// KeyValue wrapper of legacy nats.KeyValue: operations accept ctx and have own span, metrics and logs.
+// Other functions are used as is.
+type KeyValue struct {
+	nats.KeyValue
+
+	core *Core
+}
+
+// WrapKeyValue returns instrumented nats.KeyValue
+func (c *Core) WrapKeyValue(kv nats.KeyValue) *KeyValue {
+	return &KeyValue{KeyValue: kv, core: c}
+}
+
+// KeyValue binds to existing bucket and returns instrumented wrapper
+func (j *JetStreamContext) KeyValue(bucket string) (*KeyValue, error) {
+	kv, err := j.js.KeyValue(bucket)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return j.WrapKeyValue(kv), nil
+}
+
+// CreateKeyValue creates bucket and returns instrumented wrapper
+func (j *JetStreamContext) CreateKeyValue(cfg *nats.KeyValueConfig) (*KeyValue, error) {
+	kv, err := j.js.CreateKeyValue(cfg)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return j.WrapKeyValue(kv), nil
+}
+
+// KV unwrap
+func (k *KeyValue) KV() nats.KeyValue {
+	return k.KeyValue
+}
+
+// Get returns the latest value for the key
+func (k *KeyValue) Get(ctx context.Context, key string) (entry nats.KeyValueEntry, err error) {
+	err = k.core.storeOp(ctx, KindKVGet, KVLatency, k.Bucket(), key, func(context.Context) ([]attribute.KeyValue, error) {
+		entry, err = k.KeyValue.Get(key)
+		if err != nil {
+			return nil, err
+		}
+
+		return []attribute.KeyValue{Revision.Int64(int64(entry.Revision()))}, nil
+	})
+
+	return entry, err
+}
+
+// Put will place the new value for the key into the store
+func (k *KeyValue) Put(ctx context.Context, key string, value []byte) (revision uint64, err error) {
+	err = k.core.storeOp(ctx, KindKVPut, KVLatency, k.Bucket(), key, func(context.Context) ([]attribute.KeyValue, error) {
+		revision, err = k.KeyValue.Put(key, value)
+		return revisionAttrs(revision), err
+	})
+
+	return revision, err
+}
+
+// Create will add the key/value pair if it does not exist
+func (k *KeyValue) Create(ctx context.Context, key string, value []byte) (revision uint64, err error) {
+	err = k.core.storeOp(ctx, KindKVCreate, KVLatency, k.Bucket(), key, func(context.Context) ([]attribute.KeyValue, error) {
+		revision, err = k.KeyValue.Create(key, value)
+		return revisionAttrs(revision), err
+	})
+
+	return revision, err
+}
+
+// Update will update the value if the latest revision matches
+func (k *KeyValue) Update(ctx context.Context, key string, value []byte, last uint64) (revision uint64, err error) {
+	err = k.core.storeOp(ctx, KindKVUpdate, KVLatency, k.Bucket(), key, func(context.Context) ([]attribute.KeyValue, error) {
+		revision, err = k.KeyValue.Update(key, value, last)
+		return revisionAttrs(revision), err
+	})
+
+	return revision, err
+}
+
+// Delete will place a delete marker and leave all revisions
+func (k *KeyValue) Delete(ctx context.Context, key string, opts ...nats.DeleteOpt) error {
+	return k.core.storeOp(ctx, KindKVDelete, KVLatency, k.Bucket(), key, func(context.Context) ([]attribute.KeyValue, error) {
+		return nil, k.KeyValue.Delete(key, opts...)
+	})
+}
+
+// Purge will place a delete marker and remove all previous revisions
+func (k *KeyValue) Purge(ctx context.Context, key string, opts ...nats.DeleteOpt) error {
+	return k.core.storeOp(ctx, KindKVPurge, KVLatency, k.Bucket(), key, func(context.Context) ([]attribute.KeyValue, error) {
+		return nil, k.KeyValue.Purge(key, opts...)
+	})
+}
+
+// WatchWithHandler watches keys and process every update with cb within consumer span.
+// Watcher is bound to ctx, stop it via returned watcher or ctx cancellation.
+func (k *KeyValue) WatchWithHandler(ctx context.Context, keys string, cb KVWatchHandler, opts ...nats.WatchOpt) (KVHandlerWatcher, error) {
+	w, err := k.KeyValue.Watch(keys, append(opts[:len(opts):len(opts)], nats.Context(ctx))...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	watch(k.core, ctx, k.Bucket(), w.Updates(), func(e nats.KeyValueEntry) string {
+		return e.Operation().String()
+	}, cb)
+
+	return handlerWatcher{stop: w.Stop}, nil
+}
+
+// JetStreamKeyValue wrapper of jetstream.KeyValue: operations have own span, metrics and logs.
+// Other functions are used as is.
+type JetStreamKeyValue struct {
+	jetstream.KeyValue
+
+	core *Core
+}
+
+// WrapJetStreamKeyValue returns instrumented jetstream.KeyValue
+func (c *Core) WrapJetStreamKeyValue(kv jetstream.KeyValue) *JetStreamKeyValue {
+	return &JetStreamKeyValue{KeyValue: kv, core: c}
+}
+
+// Get returns the latest value for the key
+func (k *JetStreamKeyValue) Get(ctx context.Context, key string) (entry jetstream.KeyValueEntry, err error) {
+	err = k.core.storeOp(ctx, KindKVGet, KVLatency, k.Bucket(), key, func(ctx context.Context) ([]attribute.KeyValue, error) {
+		entry, err = k.KeyValue.Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+
+		return []attribute.KeyValue{Revision.Int64(int64(entry.Revision()))}, nil
+	})
+
+	return entry, err
+}
+
+// Put will place the new value for the key into the store
+func (k *JetStreamKeyValue) Put(ctx context.Context, key string, value []byte) (revision uint64, err error) {
+	err = k.core.storeOp(ctx, KindKVPut, KVLatency, k.Bucket(), key, func(ctx context.Context) ([]attribute.KeyValue, error) {
+		revision, err = k.KeyValue.Put(ctx, key, value)
+		return revisionAttrs(revision), err
+	})
+
+	return revision, err
+}
+
+// PutString will place the string for the key into the store
+func (k *JetStreamKeyValue) PutString(ctx context.Context, key string, value string) (uint64, error) {
+	return k.Put(ctx, key, []byte(value))
+}
+
+// Create will add the key/value pair if it does not exist
+func (k *JetStreamKeyValue) Create(ctx context.Context, key string, value []byte) (revision uint64, err error) {
+	err = k.core.storeOp(ctx, KindKVCreate, KVLatency, k.Bucket(), key, func(ctx context.Context) ([]attribute.KeyValue, error) {
+		revision, err = k.KeyValue.Create(ctx, key, value)
+		return revisionAttrs(revision), err
+	})
+
+	return revision, err
+}
+
+// Update will update the value if the latest revision matches
+func (k *JetStreamKeyValue) Update(ctx context.Context, key string, value []byte, last uint64) (revision uint64, err error) {
+	err = k.core.storeOp(ctx, KindKVUpdate, KVLatency, k.Bucket(), key, func(ctx context.Context) ([]attribute.KeyValue, error) {
+		revision, err = k.KeyValue.Update(ctx, key, value, last)
+		return revisionAttrs(revision), err
+	})
+
+	return revision, err
+}
+
+// Delete will place a delete marker and leave all revisions
+func (k *JetStreamKeyValue) Delete(ctx context.Context, key string, opts ...jetstream.KVDeleteOpt) error {
+	return k.core.storeOp(ctx, KindKVDelete, KVLatency, k.Bucket(), key, func(ctx context.Context) ([]attribute.KeyValue, error) {
+		return nil, k.KeyValue.Delete(ctx, key, opts...)
+	})
+}
+
+// Purge will place a delete marker and remove all previous revisions
+func (k *JetStreamKeyValue) Purge(ctx context.Context, key string, opts ...jetstream.KVDeleteOpt) error {
+	return k.core.storeOp(ctx, KindKVPurge, KVLatency, k.Bucket(), key, func(ctx context.Context) ([]attribute.KeyValue, error) {
+		return nil, k.KeyValue.Purge(ctx, key, opts...)
+	})
+}
+
+// WatchWithHandler watches keys and process every update with cb within consumer span.
+// Watcher is bound to ctx, stop it via returned watcher or ctx cancellation.
+func (k *JetStreamKeyValue) WatchWithHandler(ctx context.Context, keys string, cb JetStreamKVWatchHandler,
+	opts ...jetstream.WatchOpt) (KVHandlerWatcher, error) {
+	w, err := k.KeyValue.Watch(ctx, keys, opts...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	watch(k.core, ctx, k.Bucket(), w.Updates(), func(e jetstream.KeyValueEntry) string {
+		return e.Operation().String()
+	}, cb)
+
+	return handlerWatcher{stop: w.Stop}, nil
+}
+
+// handlerWatcher hides watcher of WatchWithHandler: reading its updates races with handler and loses entries
+type handlerWatcher struct {
+	stop func() error
+}
+
+func (w handlerWatcher) Stop() error {
+	return w.stop()
+}
+
+func revisionAttrs(revision uint64) []attribute.KeyValue {
+	if revision == 0 {
+		return nil
+	}
+
+	return []attribute.KeyValue{Revision.Int64(int64(revision))}
+}
@@ -15,6 +15,9 @@ type Logs struct {
 
 	dumpPayloadOnError bool
 	dump               bool
+
+	// decodes payload for dump, raw payload is dumped when nil
+	payload *PayloadRegistry
 }
 
 func NewLogs(fn NameFn, dumpPayloadOnError, dumpRequest bool) *Logs {
@@ -42,7 +45,7 @@ func (t *Logs) apply(next MsgHandler) MsgHandler {
 			}
 
 			if ((t.dumpPayloadOnError && err != nil) || t.dump) && msg.Data != nil {
-				l = l.With(zap.String(PayloadKey, string(msg.Data)))
+				l = l.With(zap.String(PayloadKey, dumpPayload(t.payload, msg)))
 			}
 
 			l.Check(lvl, t.nameFn(kind, msg)).Write(
@@ -53,3 +56,12 @@ func (t *Logs) apply(next MsgHandler) MsgHandler {
 		return next(ctx, msg)
 	}
 }
+
+// dumpPayload returns payload decoded by registry or raw payload without it
+func dumpPayload(r *PayloadRegistry, msg *nats.Msg) string {
+	if r == nil {
+		return string(msg.Data)
+	}
+
+	return r.Dump(msg)
+}
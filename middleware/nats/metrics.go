@@ -2,17 +2,22 @@ package nats
 
 import (
 	"context"
+	"strconv"
 	"time"
 
 	"github.com/nats-io/nats.go"
 	"github.com/tel-io/tel/v2"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type metrics struct {
 	counters       map[string]metric.Int64Counter
 	valueRecorders map[string]metric.Float64Histogram
+	sizeRecorders  map[string]metric.Int64Histogram
+
+	semConv SemConv
 }
 
 func createMeasures(tele tel.Telemetry, meter metric.Meter) *metrics {
@@ -34,13 +39,78 @@ func createMeasures(tele tel.Telemetry, meter metric.Meter) *metrics {
 		tele.Panic("nats mw", tel.String("key", Latency))
 	}
 
+	fetchLatency, err := meter.Float64Histogram(FetchLatency, metric.WithUnit("ms"))
+	if err != nil {
+		tele.Panic("nats mw", tel.String("key", FetchLatency))
+	}
+
+	fetchBatchSize, err := meter.Int64Histogram(FetchBatchSize)
+	if err != nil {
+		tele.Panic("nats mw", tel.String("key", FetchBatchSize))
+	}
+
+	batchLength, err := meter.Int64Histogram(BatchLength)
+	if err != nil {
+		tele.Panic("nats mw", tel.String("key", BatchLength))
+	}
+
+	fetchEmpty, err := meter.Int64Counter(FetchEmpty)
+	if err != nil {
+		tele.Panic("nats mw", tel.String("key", FetchEmpty))
+	}
+
+	for _, name := range []string{JSAcks, JSNaks, JSTerms, JSInProgress, JSRedeliveries, RetryCount, DLQCount, BatchMessages} {
+		c, err := meter.Int64Counter(name)
+		if err != nil {
+			tele.Panic("nats mw", tel.String("key", name))
+		}
+
+		counters[name] = c
+	}
+
+	sincePublish, err := meter.Float64Histogram(JSSincePublish, metric.WithUnit("ms"))
+	if err != nil {
+		tele.Panic("nats mw", tel.String("key", JSSincePublish))
+	}
+
+	for _, name := range []string{KVLatency, ObjectLatency, BatchLatency, EndToEnd} {
+		h, err := meter.Float64Histogram(name, metric.WithUnit("ms"))
+		if err != nil {
+			tele.Panic("nats mw", tel.String("key", name))
+		}
+
+		valueRecorders[name] = h
+	}
+
+	for _, name := range []string{MessagingPublishDuration, MessagingReceiveDuration, MessagingProcessDuration} {
+		h, err := meter.Float64Histogram(name, metric.WithUnit("s"))
+		if err != nil {
+			tele.Panic("nats mw", tel.String("key", name))
+		}
+
+		valueRecorders[name] = h
+	}
+
+	for _, name := range []string{MessagingPublishMessages, MessagingReceiveMessages, MessagingProcessMessages} {
+		c, err := meter.Int64Counter(name, metric.WithUnit("{message}"))
+		if err != nil {
+			tele.Panic("nats mw", tel.String("key", name))
+		}
+
+		counters[name] = c
+	}
+
 	counters[Count] = counter
 	counters[ContentLength] = requestBytesCounter
+	counters[FetchEmpty] = fetchEmpty
 	valueRecorders[Latency] = serverLatencyMeasure
+	valueRecorders[FetchLatency] = fetchLatency
+	valueRecorders[JSSincePublish] = sincePublish
 
 	return &metrics{
 		counters:       counters,
 		valueRecorders: valueRecorders,
+		sizeRecorders:  map[string]metric.Int64Histogram{FetchBatchSize: fetchBatchSize, BatchLength: batchLength},
 	}
 }
 
@@ -63,6 +133,12 @@ func (t *SubMetrics) apply(next MsgHandler) MsgHandler {
 				ctx = tel.FromCtx(ctx).Ctx()
 			}
 
+			t.recordMessaging(ctx, kind, msg, time.Since(start), batchLen(ctx), err)
+
+			if !t.semConv.legacy() {
+				return
+			}
+
 			attr := []attribute.KeyValue{
 				IsError.Bool(err != nil),
 				Subject.String(decreaseSubjectCardinality(msg.Subject)),
@@ -83,6 +159,27 @@ func (t *SubMetrics) apply(next MsgHandler) MsgHandler {
 
 		}(time.Now())
 
+		t.recordEndToEnd(ctx, msg)
+
 		return next(ctx, msg)
 	}
 }
+
+// recordEndToEnd records time since message was stamped by producer, see HeaderPublishTime
+func (t *SubMetrics) recordEndToEnd(ctx context.Context, msg *nats.Msg) {
+	if convSpanToKind(extractBaggageKind(ctx)) != trace.SpanKindConsumer {
+		return
+	}
+
+	v, err := strconv.ParseInt(msg.Header.Get(HeaderPublishTime), 10, 64)
+	if err != nil {
+		return
+	}
+
+	// clock of producer could be ahead of consumer one
+	d := max(time.Since(time.Unix(0, v)), 0)
+
+	t.valueRecorders[EndToEnd].Record(ctx, float64(d.Milliseconds()),
+		metric.WithAttributes(Subject.String(decreaseSubjectCardinality(msg.Subject))),
+	)
+}
@@ -3,48 +3,104 @@ package nats
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 
 	"github.com/nats-io/nats.go"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
 )
 
+// Attribute keys of subscription statistics
+const (
+	Queue          = attribute.Key("queue")
+	SubscriptionID = attribute.Key("subscription.id")
+)
+
 // SubscriptionStatMetric hook provide important subscription statistics
+// Subscriptions are forgotten when they are closed by Unsubscribe, Drain or connection close.
 type SubscriptionStatMetric struct {
 	list     sync.Map
 	counters map[string]metric.Int64ObservableGauge
+	ratio    metric.Float64ObservableGauge
+
+	// report series per subscription instead of aggregation by subject and queue
+	perSub bool
+	seq    atomic.Int64
+
+	done chan struct{}
+	once sync.Once
+
+	reg metric.Registration
+}
+
+// subStat registered subscription
+type subStat struct {
+	sub *nats.Subscription
+	id  int64
 }
 
+// NewSubscriptionStatMetrics creates subscription statistics hook, callback should be unregistered via Close
 func NewSubscriptionStatMetrics(opts ...Option) (*SubscriptionStatMetric, error) {
-	conf := newConfig(opts)
+	c := newConfig(opts)
+
+	return newSubscriptionStatMetrics(c.meter, c.subSeries)
+}
 
+func newSubscriptionStatMetrics(meter metric.Meter, perSub bool) (*SubscriptionStatMetric, error) {
 	c := make(map[string]metric.Int64ObservableGauge)
 
-	msgs, _ := conf.meter.Int64ObservableGauge(SubscriptionsPendingCount)
-	bs, _ := conf.meter.Int64ObservableGauge(SubscriptionsPendingBytes,
+	msgs, _ := meter.Int64ObservableGauge(SubscriptionsPendingCount)
+	bs, _ := meter.Int64ObservableGauge(SubscriptionsPendingBytes,
 		metric.WithUnit("By"),
 	)
 
-	dd, _ := conf.meter.Int64ObservableGauge(SubscriptionsDroppedMsgs)
-	cc, _ := conf.meter.Int64ObservableGauge(SubscriptionCountMsgs)
+	dd, _ := meter.Int64ObservableGauge(SubscriptionsDroppedMsgs)
+	cc, _ := meter.Int64ObservableGauge(SubscriptionDeliveredMsgs)
+
+	lm, _ := meter.Int64ObservableGauge(SubscriptionsPendingLimitMsgs)
+	lb, _ := meter.Int64ObservableGauge(SubscriptionsPendingLimitBytes,
+		metric.WithUnit("By"),
+	)
+
+	ratio, _ := meter.Float64ObservableGauge(SubscriptionsPendingUtilization)
 
 	c[SubscriptionsPendingCount] = msgs
 	c[SubscriptionsPendingBytes] = bs
 	c[SubscriptionsDroppedMsgs] = dd
-	c[SubscriptionCountMsgs] = cc
+	c[SubscriptionDeliveredMsgs] = cc
+	c[SubscriptionsPendingLimitMsgs] = lm
+	c[SubscriptionsPendingLimitBytes] = lb
 
 	res := &SubscriptionStatMetric{
 		counters: c,
+		ratio:    ratio,
+		perSub:   perSub,
+		done:     make(chan struct{}),
 	}
 
-	_, err := conf.meter.RegisterCallback(res.callback, []metric.Observable{msgs, bs, dd, cc}...)
+	reg, err := meter.RegisterCallback(res.callback, []metric.Observable{msgs, bs, dd, cc, lm, lb, ratio}...)
 	if err != nil {
 		return nil, errors.WithMessagef(err, "reggister callback")
 	}
 
+	res.reg = reg
+
 	return res, nil
 }
 
+// Close unregister metric callback and forget all subscriptions
+func (s *SubscriptionStatMetric) Close() error {
+	s.once.Do(func() { close(s.done) })
+
+	s.list.Range(func(key, _ interface{}) bool {
+		s.list.Delete(key)
+		return true
+	})
+
+	return errors.WithStack(s.reg.Unregister())
+}
+
 func (s *SubscriptionStatMetric) Hook(sub *nats.Subscription, err error) (*nats.Subscription, error) {
 	if err != nil {
 		return nil, err
@@ -55,69 +111,116 @@ func (s *SubscriptionStatMetric) Hook(sub *nats.Subscription, err error) (*nats.
 	return sub, nil
 }
 
+// Register subscriptions, they are removed when closed
 func (s *SubscriptionStatMetric) Register(sub ...*nats.Subscription) {
 	for _, v := range sub {
-		s.list.Store(v, v)
+		if _, loaded := s.list.LoadOrStore(v, &subStat{sub: v, id: s.seq.Add(1)}); loaded {
+			continue
+		}
+
+		go s.watch(v, v.StatusChanged(nats.SubscriptionClosed))
 	}
 }
 
+// watch forget subscription when it's closed
+func (s *SubscriptionStatMetric) watch(sub *nats.Subscription, closed <-chan nats.SubStatus) {
+	select {
+	case <-closed:
+		s.list.Delete(sub)
+	case <-s.done:
+	}
+}
+
+type subAggregate struct {
+	msgs       int64
+	bytes      int64
+	dropped    int64
+	count      int64
+	limitMsgs  int64
+	limitBytes int64
+	ratio      float64
+}
+
 func (s *SubscriptionStatMetric) callback(ctx context.Context, o metric.Observer) error {
 	// we could have multi subscriptions with the same subject
 	// we should set total number of that
-	data := make(map[string]struct {
-		msgs    int64
-		bytes   int64
-		dropped int64
-		count   int64
-	})
+	data := make(map[attribute.Set]subAggregate)
 
 	s.list.Range(func(key, value interface{}) bool {
-		v, ok := value.(*nats.Subscription)
+		v, ok := value.(*subStat)
 		if !ok {
 			return true
 		}
 
-		pMsg, pBytes, _ := v.Pending()
-		dropped, _ := v.Dropped()
-		count, _ := v.Delivered()
+		// closed subscription which status change was missed
+		if !v.sub.IsValid() {
+			s.list.Delete(key)
+			return true
+		}
+
+		pMsg, pBytes, _ := v.sub.Pending()
+		lMsg, lBytes, _ := v.sub.PendingLimits()
+		dropped, _ := v.sub.Dropped()
+		count, _ := v.sub.Delivered()
+
+		attrs := []attribute.KeyValue{
+			Subject.String(decreaseSubjectCardinality(v.sub.Subject)),
+			Queue.String(v.sub.Queue),
+		}
+
+		if s.perSub {
+			attrs = append(attrs, SubscriptionID.Int64(v.id))
+		}
 
-		subject := decreaseSubjectCardinality(v.Subject)
+		set := attribute.NewSet(attrs...)
 
-		vc := data[subject]
+		vc := data[set]
 		vc.msgs += int64(pMsg)
 		vc.bytes += int64(pBytes)
 		vc.dropped += int64(dropped)
 		vc.count += count
+		vc.limitMsgs = addLimit(vc.limitMsgs, lMsg)
+		vc.limitBytes = addLimit(vc.limitBytes, lBytes)
+		vc.ratio = max(vc.ratio, utilization(pMsg, lMsg), utilization(pBytes, lBytes))
 
-		data[subject] = vc
+		data[set] = vc
 		return true
 	})
 
 	for k, v := range data {
-		o.ObserveInt64(
-			s.counters[SubscriptionsPendingCount],
-			v.msgs,
-			metric.WithAttributes(Subject.String(k)),
-		)
-
-		o.ObserveInt64(
-			s.counters[SubscriptionsPendingBytes],
-			v.bytes,
-			metric.WithAttributes(Subject.String(k)),
-		)
-
-		o.ObserveInt64(
-			s.counters[SubscriptionsDroppedMsgs],
-			v.dropped,
-			metric.WithAttributes(Subject.String(k)),
-		)
-
-		o.ObserveInt64(
-			s.counters[SubscriptionCountMsgs],
-			v.count,
-			metric.WithAttributes(Subject.String(k)),
-		)
+		attrs := metric.WithAttributeSet(k)
+
+		o.ObserveInt64(s.counters[SubscriptionsPendingCount], v.msgs, attrs)
+		o.ObserveInt64(s.counters[SubscriptionsPendingBytes], v.bytes, attrs)
+		o.ObserveInt64(s.counters[SubscriptionsDroppedMsgs], v.dropped, attrs)
+		o.ObserveInt64(s.counters[SubscriptionDeliveredMsgs], v.count, attrs)
+		o.ObserveInt64(s.counters[SubscriptionsPendingLimitMsgs], v.limitMsgs, attrs)
+		o.ObserveInt64(s.counters[SubscriptionsPendingLimitBytes], v.limitBytes, attrs)
+		o.ObserveFloat64(s.ratio, v.ratio, attrs)
 	}
 
 	return nil
 }
+
+// addLimit sums limits of limited subscriptions: negative limit means unlimited,
+// so aggregate is negative only when all its subscriptions are unlimited
+func addLimit(sum int64, limit int) int64 {
+	if limit <= 0 {
+		if sum > 0 {
+			return sum
+		}
+
+		return -1
+	}
+
+	return max(sum, 0) + int64(limit)
+}
+
+// utilization pending to limit ratio, zero for unlimited
+func utilization(pending, limit int) float64 {
+	if limit <= 0 {
+		return 0
+	}
+
+	return float64(pending) / float64(limit)
+}
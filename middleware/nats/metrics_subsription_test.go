@@ -0,0 +1,182 @@
+package nats
+
+import (
+	"context"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// collectSubStats returns data points of metric name by queue and subscription.id attributes
+func (s *Suite) collectSubStats(reader sdkmetric.Reader, name string) map[string]metricdata.DataPoint[float64] {
+	var rm metricdata.ResourceMetrics
+	s.Require().NoError(reader.Collect(context.Background(), &rm))
+
+	res := map[string]metricdata.DataPoint[float64]{}
+
+	for _, sm := range rm.ScopeMetrics {
+		for _, mt := range sm.Metrics {
+			if mt.Name != name {
+				continue
+			}
+
+			switch data := mt.Data.(type) {
+			case metricdata.Gauge[int64]:
+				for _, dp := range data.DataPoints {
+					res[dp.Attributes.Encoded(attribute.DefaultEncoder())] = metricdata.DataPoint[float64]{
+						Attributes: dp.Attributes,
+						Value:      float64(dp.Value),
+					}
+				}
+			case metricdata.Gauge[float64]:
+				for _, dp := range data.DataPoints {
+					res[dp.Attributes.Encoded(attribute.DefaultEncoder())] = dp
+				}
+			}
+		}
+	}
+
+	return res
+}
+
+func (s *Suite) TestSubscriptionStatLifecycle() {
+	conn := s.runServer()
+
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	stats, err := newSubscriptionStatMetrics(provider.Meter("test"), false)
+	s.Require().NoError(err)
+
+	defer stats.Close()
+
+	a, err := stats.Hook(conn.QueueSubscribeSync("stats.demo", "workers"))
+	s.Require().NoError(err)
+	s.Require().NoError(a.SetPendingLimits(10, 1024))
+
+	b, err := stats.Hook(conn.QueueSubscribeSync("stats.demo", "workers"))
+	s.Require().NoError(err)
+	s.Require().NoError(b.SetPendingLimits(10, 1024))
+
+	_, err = stats.Hook(conn.SubscribeSync("stats.demo"))
+	s.Require().NoError(err)
+
+	for range 3 {
+		s.Require().NoError(conn.Publish("stats.demo", []byte("x")))
+	}
+
+	s.Require().NoError(conn.Flush())
+
+	// queue group and plain subscription are separate series
+	s.Eventually(func() bool {
+		pending := s.collectSubStats(reader, SubscriptionsPendingCount)
+
+		var total float64
+		for _, dp := range pending {
+			total += dp.Value
+		}
+
+		return len(pending) == 2 && total == 6
+	}, time.Second, 10*time.Millisecond)
+
+	limits := s.collectSubStats(reader, SubscriptionsPendingLimitMsgs)
+	s.Len(limits, 2)
+
+	for _, dp := range limits {
+		if q, _ := dp.Attributes.Value(Queue); q.AsString() == "workers" {
+			s.Equal(float64(20), dp.Value)
+		}
+	}
+
+	// max of queue members: 3 messages are spread between 2 subscriptions with limit 10
+	for _, dp := range s.collectSubStats(reader, SubscriptionsPendingUtilization) {
+		if q, _ := dp.Attributes.Value(Queue); q.AsString() == "workers" {
+			s.Greater(dp.Value, 0.1)
+			s.LessOrEqual(dp.Value, 0.3)
+		}
+	}
+
+	// closed subscriptions are forgotten
+	s.Require().NoError(a.Unsubscribe())
+
+	// drain completes when pending messages are consumed
+	s.Require().NoError(b.Drain())
+
+	for {
+		if _, err := b.NextMsg(10 * time.Millisecond); err != nil {
+			break
+		}
+	}
+
+	s.Eventually(func() bool {
+		return len(s.collectSubStats(reader, SubscriptionDeliveredMsgs)) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	conn.Close()
+
+	s.Eventually(func() bool {
+		return len(s.collectSubStats(reader, SubscriptionDeliveredMsgs)) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func (s *Suite) TestSubscriptionStatLimits() {
+	conn := s.runServer()
+
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	stats, err := newSubscriptionStatMetrics(provider.Meter("test"), false)
+	s.Require().NoError(err)
+
+	defer stats.Close()
+
+	limited, err := stats.Hook(conn.QueueSubscribeSync("limits.demo", "mixed"))
+	s.Require().NoError(err)
+	s.Require().NoError(limited.SetPendingLimits(10, 1024))
+
+	for _, queue := range []string{"mixed", "unlimited"} {
+		sub, err := stats.Hook(conn.QueueSubscribeSync("limits.demo", queue))
+		s.Require().NoError(err)
+		s.Require().NoError(sub.SetPendingLimits(-1, -1))
+	}
+
+	want := map[string][2]float64{"mixed": {10, 1024}, "unlimited": {-1, -1}}
+
+	for i, name := range []string{SubscriptionsPendingLimitMsgs, SubscriptionsPendingLimitBytes} {
+		limits := s.collectSubStats(reader, name)
+		s.Require().Len(limits, 2)
+
+		for _, dp := range limits {
+			q, _ := dp.Attributes.Value(Queue)
+			s.Equal(want[q.AsString()][i], dp.Value, name+" of "+q.AsString())
+		}
+	}
+}
+
+func (s *Suite) TestSubscriptionSeries() {
+	conn := s.runServer()
+
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	stats, err := newSubscriptionStatMetrics(provider.Meter("test"), true)
+	s.Require().NoError(err)
+
+	defer stats.Close()
+
+	for range 2 {
+		_, err = stats.Hook(conn.Subscribe("series.demo", func(*nats.Msg) {}))
+		s.Require().NoError(err)
+	}
+
+	pending := s.collectSubStats(reader, SubscriptionsPendingCount)
+	s.Len(pending, 2)
+
+	for _, dp := range pending {
+		_, ok := dp.Attributes.Value(SubscriptionID)
+		s.True(ok)
+	}
+}
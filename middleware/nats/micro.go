@@ -0,0 +1,269 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attribute keys of micro services
+const (
+	MicroService   = attribute.Key("micro.service")
+	MicroID        = attribute.Key("micro.id")
+	MicroVersion   = attribute.Key("micro.version")
+	MicroEndpoint  = attribute.Key("micro.endpoint")
+	MicroErrorCode = attribute.Key("micro.error.code")
+)
+
+// MicroHandler handler of micro service endpoint
+// Returned error is sent as error response with 500 code, if handler didn't respond by itself.
+type MicroHandler func(ctx context.Context, req micro.Request) error
+
+// MicroError error response sent by micro service endpoint via micro.Request.Error
+type MicroError struct {
+	Code        string
+	Description string
+}
+
+func (e *MicroError) Error() string {
+	return fmt.Sprintf("micro error %s: %s", e.Code, e.Description)
+}
+
+type microReqKey struct{}
+
+// MicroRequestFromContext returns original request of micro service endpoint
+func MicroRequestFromContext(ctx context.Context) (micro.Request, bool) {
+	req, ok := ctx.Value(microReqKey{}).(micro.Request)
+	return req, ok
+}
+
+// MicroHandler adapt handler of micro service to subscriber middleware chain: recovery, logs, tracer, metrics and etc.
+// Error response sent via micro.Request.Error is treated as handler error.
+func (c *Core) MicroHandler(next MicroHandler) micro.Handler {
+	in := c.subInter(func(ctx context.Context, _ *nats.Msg) error {
+		req, _ := ctx.Value(microReqKey{}).(*microRequest)
+
+		err := next(ctx, req)
+
+		switch {
+		case err != nil && !req.responded:
+			// requester shouldn't wait timeout
+			_ = req.Error("500", err.Error(), nil)
+		case err == nil && req.err != nil:
+			err = req.err
+		}
+
+		if req.err != nil {
+			trace.SpanFromContext(ctx).SetAttributes(MicroErrorCode.String(req.err.Code))
+		}
+
+		return err
+	})
+
+	return micro.HandlerFunc(func(r micro.Request) {
+		req := &microRequest{Request: r}
+
+		ctx := WrapKindOfContext(c.config.tele.Ctx(), KindReply)
+		ctx = context.WithValue(ctx, microReqKey{}, req)
+
+		_ = in(ctx, &nats.Msg{
+			Subject: r.Subject(),
+			Reply:   r.Reply(),
+			Header:  nats.Header(r.Headers()),
+			Data:    r.Data(),
+		})
+	})
+}
+
+// microRequest tracks response of handler
+type microRequest struct {
+	micro.Request
+
+	responded bool
+	err       *MicroError
+}
+
+func (r *microRequest) Respond(data []byte, opts ...micro.RespondOpt) error {
+	r.responded = true
+	return r.Request.Respond(data, opts...)
+}
+
+func (r *microRequest) RespondJSON(v any, opts ...micro.RespondOpt) error {
+	r.responded = true
+	return r.Request.RespondJSON(v, opts...)
+}
+
+func (r *microRequest) Error(code, description string, data []byte, opts ...micro.RespondOpt) error {
+	r.responded = true
+	r.err = &MicroError{Code: code, Description: description}
+
+	return r.Request.Error(code, description, data, opts...)
+}
+
+// AddMicroService creates micro service which endpoints are wrapped with subscriber middleware chain.
+// Endpoint of config is wrapped as well. Service stats and info are exposed as metrics until service is stopped.
+func (c *ConnContext) AddMicroService(cfg micro.Config) (*MicroServiceWrapper, error) {
+	if cfg.Endpoint != nil && cfg.Endpoint.Handler != nil {
+		endpoint := *cfg.Endpoint
+		endpoint.Handler = c.MicroHandler(plainMicroHandler(cfg.Endpoint.Handler))
+		cfg.Endpoint = &endpoint
+	}
+
+	srv, err := micro.AddService(c.conn, cfg)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	c.microMeter.Register(srv)
+
+	return &MicroServiceWrapper{Service: srv, core: c.Core}, nil
+}
+
+// MicroServiceWrapper wrapper of micro.Service which endpoints are wrapped with subscriber middleware chain
+type MicroServiceWrapper struct {
+	micro.Service
+
+	core *Core
+}
+
+// AddEndpoint registers endpoint with given name on a specific subject
+func (m *MicroServiceWrapper) AddEndpoint(name string, cb MicroHandler, opts ...micro.EndpointOpt) error {
+	return errors.WithStack(m.Service.AddEndpoint(name, m.core.MicroHandler(cb), opts...))
+}
+
+// AddGroup returns group which endpoints are wrapped with subscriber middleware chain
+func (m *MicroServiceWrapper) AddGroup(name string, opts ...micro.GroupOpt) *MicroGroup {
+	return &MicroGroup{Group: m.Service.AddGroup(name, opts...), core: m.core}
+}
+
+// MicroGroup wrapper of micro.Group
+type MicroGroup struct {
+	micro.Group
+
+	core *Core
+}
+
+// AddEndpoint registers endpoint with given name on a specific subject within a group
+func (g *MicroGroup) AddEndpoint(name string, cb MicroHandler, opts ...micro.EndpointOpt) error {
+	return errors.WithStack(g.Group.AddEndpoint(name, g.core.MicroHandler(cb), opts...))
+}
+
+// AddGroup creates a new group, prefixed by this group's prefix
+func (g *MicroGroup) AddGroup(name string, opts ...micro.GroupOpt) *MicroGroup {
+	return &MicroGroup{Group: g.Group.AddGroup(name, opts...), core: g.core}
+}
+
+func plainMicroHandler(h micro.Handler) MicroHandler {
+	return func(ctx context.Context, req micro.Request) error {
+		h.Handle(req)
+		return nil
+	}
+}
+
+// MicroStatMetric exposes stats and info of micro services
+type MicroStatMetric struct {
+	list sync.Map
+
+	requests   metric.Int64ObservableGauge
+	errors     metric.Int64ObservableGauge
+	processing metric.Float64ObservableGauge
+	average    metric.Float64ObservableGauge
+	info       metric.Int64ObservableGauge
+
+	reg metric.Registration
+}
+
+func newMicroStatMetrics(meter metric.Meter) (*MicroStatMetric, error) {
+	res := &MicroStatMetric{}
+
+	var err error
+
+	if res.requests, err = meter.Int64ObservableGauge(MicroRequests); err != nil {
+		return nil, errors.WithMessagef(err, "create %s", MicroRequests)
+	}
+
+	if res.errors, err = meter.Int64ObservableGauge(MicroErrors); err != nil {
+		return nil, errors.WithMessagef(err, "create %s", MicroErrors)
+	}
+
+	if res.processing, err = meter.Float64ObservableGauge(MicroProcessingTime, metric.WithUnit("ms")); err != nil {
+		return nil, errors.WithMessagef(err, "create %s", MicroProcessingTime)
+	}
+
+	if res.average, err = meter.Float64ObservableGauge(MicroAvgProcessingTime, metric.WithUnit("ms")); err != nil {
+		return nil, errors.WithMessagef(err, "create %s", MicroAvgProcessingTime)
+	}
+
+	if res.info, err = meter.Int64ObservableGauge(MicroInfo); err != nil {
+		return nil, errors.WithMessagef(err, "create %s", MicroInfo)
+	}
+
+	res.reg, err = meter.RegisterCallback(res.callback, res.requests, res.errors, res.processing, res.average, res.info)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "reggister callback")
+	}
+
+	return res, nil
+}
+
+// Register service for statistics, it's removed when service is stopped
+func (s *MicroStatMetric) Register(srv micro.Service) {
+	s.list.Store(srv, srv)
+}
+
+// Close unregister metric callback and forget all services
+func (s *MicroStatMetric) Close() error {
+	s.list.Range(func(key, _ interface{}) bool {
+		s.list.Delete(key)
+		return true
+	})
+
+	return errors.WithStack(s.reg.Unregister())
+}
+
+func (s *MicroStatMetric) callback(_ context.Context, o metric.Observer) error {
+	s.list.Range(func(key, _ interface{}) bool {
+		srv, ok := key.(micro.Service)
+		if !ok {
+			return true
+		}
+
+		if srv.Stopped() {
+			s.list.Delete(key)
+			return true
+		}
+
+		info := srv.Info()
+
+		o.ObserveInt64(s.info, 1, metric.WithAttributes(
+			MicroService.String(info.Name),
+			MicroID.String(info.ID),
+			MicroVersion.String(info.Version),
+		))
+
+		for _, e := range srv.Stats().Endpoints {
+			attrs := metric.WithAttributes(
+				MicroService.String(info.Name),
+				MicroID.String(info.ID),
+				MicroEndpoint.String(e.Name),
+				Subject.String(decreaseSubjectCardinality(e.Subject)),
+			)
+
+			o.ObserveInt64(s.requests, int64(e.NumRequests), attrs)
+			o.ObserveInt64(s.errors, int64(e.NumErrors), attrs)
+			o.ObserveFloat64(s.processing, float64(e.ProcessingTime.Microseconds())/1000, attrs)
+			o.ObserveFloat64(s.average, float64(e.AverageProcessingTime.Microseconds())/1000, attrs)
+		}
+
+		return true
+	})
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go/micro"
+)
+
+func (s *Suite) TestMicroService() {
+	conn := s.runServer()
+	mw := s.newCore().Use(conn)
+
+	srv, err := mw.AddMicroService(micro.Config{
+		Name:    "greeter",
+		Version: "1.0.0",
+		Endpoint: &micro.EndpointConfig{
+			Subject: "greeter.plain",
+			Handler: micro.HandlerFunc(func(req micro.Request) {
+				_ = req.Respond([]byte("plain"))
+			}),
+		},
+	})
+	s.Require().NoError(err)
+
+	defer func() { s.NoError(srv.Stop()) }()
+
+	grp := srv.AddGroup("greeter")
+
+	s.Require().NoError(grp.AddEndpoint("hello", func(ctx context.Context, req micro.Request) error {
+		_, ok := MicroRequestFromContext(ctx)
+		s.True(ok)
+
+		return req.Respond(append([]byte("hello "), req.Data()...))
+	}))
+
+	s.Require().NoError(grp.AddEndpoint("invalid", func(ctx context.Context, req micro.Request) error {
+		return req.Error("400", "bad request", nil)
+	}))
+
+	s.Require().NoError(grp.AddEndpoint("fail", func(ctx context.Context, req micro.Request) error {
+		return fmt.Errorf("some error")
+	}))
+
+	for subj, want := range map[string]string{
+		"greeter.plain":   "plain",
+		"greeter.hello":   "hello world",
+		"greeter.invalid": "400",
+		"greeter.fail":    "500",
+	} {
+		reply, err := conn.Request(subj, []byte("world"), time.Second)
+		s.Require().NoError(err)
+
+		if code := reply.Header.Get(micro.ErrorCodeHeader); code != "" {
+			s.Equal(want, code, subj)
+			continue
+		}
+
+		s.Equal(want, string(reply.Data), subj)
+	}
+
+	// stats are updated after handler completion
+	s.Eventually(func() bool {
+		return srv.Stats().Endpoints[2].NumErrors == 1
+	}, time.Second, 10*time.Millisecond)
+
+	s.Contains(s.buf.String(), "bad request")
+	s.Contains(s.buf.String(), "some error")
+}
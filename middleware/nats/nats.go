@@ -2,11 +2,11 @@ package nats
 
 import (
 	"context"
+	"sync"
+
 	"github.com/nats-io/nats.go"
 	"github.com/pkg/errors"
 	"github.com/tel-io/tel/v2"
-	"go.opentelemetry.io/otel/baggage"
-	"sync"
 )
 
 // PostFn callback function which got new instance of tele inside ctx
@@ -14,10 +14,10 @@ import (
 // Deprecated: legacy function, but we use it via conn wrapper: QueueSubscribeMW or SubscribeMW just for backport compatibility
 type PostFn func(ctx context.Context, sub string, data []byte) ([]byte, error)
 
+// ErrMultipleMiddleWare
+// Deprecated: multiple instances are allowed, never returned
 var ErrMultipleMiddleWare = errors.New("not allow create multiple instances")
 
-var mx sync.RWMutex
-
 // Core features for context
 type Core struct {
 	*config
@@ -25,43 +25,94 @@ type Core struct {
 	subInter Interceptor
 	pubInter Interceptor
 
-	subMeter *SubscriptionStatMetric
+	subMeter   *SubscriptionStatMetric
+	connMeter  *ConnStatMetric
+	kvWatchers *KVWatchStat
+	microMeter *MicroStatMetric
+
+	// *nats.Conn -> chan struct{} closed with connection, see connClosed
+	closedConns sync.Map
 }
 
 // New subMiddleware instance
+// Every instance has own configuration and instruments, so several connections could be wrapped with different options.
+// Close should be called when instance is not used anymore.
 func New(opts ...Option) *Core {
-	// We don't allow to create multiple instances
-	if !mx.TryLock() {
-		panic(ErrMultipleMiddleWare)
+	cfg := newConfig(opts)
+
+	sb, err := newSubscriptionStatMetrics(cfg.meter, cfg.subSeries)
+	if err != nil {
+		cfg.tele.Panic("wrap connection", tel.Error(err))
 	}
 
-	cfg := newConfig(opts)
+	cm, err := newConnStatMetrics(cfg.tele, cfg.meter, cfg.connRTTInterval)
+	if err != nil {
+		cfg.tele.Panic("wrap connection", tel.Error(err))
+	}
 
-	// create only once
-	sb, err := NewSubscriptionStatMetrics(opts...)
+	kw, err := newKVWatchStat(cfg.meter)
+	if err != nil {
+		cfg.tele.Panic("wrap connection", tel.Error(err))
+	}
+
+	mm, err := newMicroStatMetrics(cfg.meter)
 	if err != nil {
 		cfg.tele.Panic("wrap connection", tel.Error(err))
 	}
 
 	// create instances of pub mw only once
-	plist := cfg.pubMiddleware()
+	pubInter := MiddlewareChain(cfg.pubMiddleware()...)
 
 	// create instances of mw only once
-	list := cfg.subMiddleware()
+	list := cfg.subMiddleware(pubInter)
 
 	return &Core{
-		config:   cfg,
-		subMeter: sb,
-		subInter: MiddlewareChain(list...),
-		pubInter: MiddlewareChain(plist...),
+		config:     cfg,
+		subMeter:   sb,
+		connMeter:  cm,
+		kvWatchers: kw,
+		microMeter: mm,
+		subInter:   MiddlewareChain(list...),
+		pubInter:   pubInter,
+	}
+}
+
+// Close unregister metric callbacks of instance
+func (c *Core) Close() error {
+	var err error
+
+	for _, fn := range []func() error{c.subMeter.Close, c.connMeter.Close, c.kvWatchers.Close, c.microMeter.Close} {
+		if e := fn(); e != nil && err == nil {
+			err = e
+		}
+	}
+
+	return err
+}
+
+// ConnOption returns nats.Connect option which installs telemetry of handlers
+// which couldn't be set on established connection: lame duck mode.
+// It chains handler set by previous options, so pass it last.
+func (c *Core) ConnOption() nats.Option {
+	return func(o *nats.Options) error {
+		o.LameDuckModeHandler = c.connMeter.LameDuckModeHandler(o.LameDuckModeHandler)
+		return nil
 	}
 }
 
 // Use connection with subMiddleware
+// Connection event handlers are chained with telemetry ones when WithConnTelemetry is enabled
 func (c *Core) Use(conn *nats.Conn) *ConnContext {
+	if c.connTelemetry {
+		c.connMeter.Register(conn)
+	}
+
+	pub := NewCommonPublish(conn, c.pubInter)
+	pub.propOpts = c.propOpts
+
 	return &ConnContext{
 		conn:       conn,
-		Publish:    NewCommonPublish(conn, c.pubInter),
+		Publish:    pub,
 		Subscriber: NewCommonSubscriber(conn, c),
 		Core:       c,
 	}
@@ -69,23 +120,20 @@ func (c *Core) Use(conn *nats.Conn) *ConnContext {
 
 // subWrap wrapper for subscriber
 func (c *Core) subWrap(next MsgHandler) nats.MsgHandler {
-	in := c.subInter(next)
-
-	var bag *baggage.Baggage
-	if k, e := baggage.NewMember(KindKey, KindSub); e == nil {
-		if b, ee := baggage.New(k); ee == nil {
-			bag = &b
-		}
-	}
+	in := c.subHandler(next)
 
 	return func(msg *nats.Msg) {
 		// init context for instance
-		ctx := c.config.tele.Ctx()
+		_ = in(c.config.tele.Ctx(), msg)
+	}
+}
 
-		if bag != nil {
-			ctx = baggage.ContextWithBaggage(ctx, *bag)
-		}
+// subHandler wrap handler with subscriber interceptors and mark ctx as subscription kind
+// ctx allow to pass parent information, like fetch span links
+func (c *Core) subHandler(next MsgHandler) MsgHandler {
+	in := c.subInter(next)
 
-		_ = in(ctx, msg)
+	return func(ctx context.Context, msg *nats.Msg) error {
+		return in(WrapKindOfContext(ctx, KindSub), msg)
 	}
 }
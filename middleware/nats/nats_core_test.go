@@ -0,0 +1,46 @@
+package nats
+
+import (
+	"context"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+func (s *Suite) TestMultipleInstances() {
+	conn := s.runServer()
+
+	received := make(chan string, 2)
+
+	for _, name := range []string{"internal", "edge"} {
+		name := name
+
+		mw := s.newCore(WithNameFunction(func(kind string, msg *nats.Msg) string {
+			return name + ":" + kind
+		})).Use(conn)
+
+		_, err := mw.Subscribe("multi."+name, func(ctx context.Context, msg *nats.Msg) error {
+			received <- name
+			return nil
+		})
+		s.Require().NoError(err)
+	}
+
+	s.Require().NoError(conn.Publish("multi.internal", nil))
+	s.Require().NoError(conn.Publish("multi.edge", nil))
+
+	s.ElementsMatch([]string{"internal", "edge"}, []string{<-received, <-received})
+
+	// wait handlers completion
+	s.Require().NoError(conn.Drain())
+	s.Eventually(conn.IsClosed, time.Second, 10*time.Millisecond)
+
+	s.Contains(s.buf.String(), "internal:SUB")
+	s.Contains(s.buf.String(), "edge:SUB")
+}
+
+func (s *Suite) TestClose() {
+	c := New(WithTel(s.tel))
+
+	s.NoError(c.Close())
+}
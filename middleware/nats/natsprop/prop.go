@@ -2,8 +2,10 @@ package natsprop
 
 import (
 	"context"
+	"net/textproto"
 
 	"github.com/nats-io/nats.go"
+	"github.com/tel-io/instrumentation/module/telfields"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/baggage"
@@ -24,9 +26,15 @@ func (o optionFunc) apply(c *config) {
 }
 
 type config struct {
-	propagators propagation.TextMapPropagator
+	propagators   propagation.TextMapPropagator
+	baggageFilter BaggageFilter
+	// nil unless WithLogFields is set
+	fields *telfields.Propagator
 }
 
+// BaggageFilter reports whether baggage member is propagated
+type BaggageFilter func(member baggage.Member) bool
+
 func newConfig(opts []Option) *config {
 	c := &config{propagators: otel.GetTextMapPropagator()}
 	for _, o := range opts {
@@ -44,14 +52,38 @@ func WithPropagators(props propagation.TextMapPropagator) Option {
 	})
 }
 
+// WithBaggageAllowList propagate only baggage members with listed keys, others are dropped on Inject and Extract
+func WithBaggageAllowList(keys ...string) Option {
+	allow := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		allow[k] = struct{}{}
+	}
+
+	return WithBaggageFilter(func(member baggage.Member) bool {
+		_, ok := allow[member.Key()]
+		return ok
+	})
+}
+
+// WithBaggageFilter propagate only baggage members accepted by fn, others are dropped on Inject and Extract
+func WithBaggageFilter(fn BaggageFilter) Option {
+	return optionFunc(func(c *config) {
+		c.baggageFilter = fn
+	})
+}
+
 // Extract returns the Attributes, Context Entries, and SpanContext that were encoded by Inject.
 func Extract(ctx context.Context, msg *nats.Msg, opts ...Option) ([]attribute.KeyValue, baggage.Baggage, trace.SpanContext) {
 	c := newConfig(opts)
 	ctx = c.propagators.Extract(ctx, propagation.HeaderCarrier(msg.Header))
 
+	if c.fields != nil {
+		c.fields.Extract(ctx, msg.Header.Get)
+	}
+
 	attrs := NewAttributesFromNATSRequest(msg)
 
-	return attrs, baggage.FromContext(ctx), trace.SpanContextFromContext(ctx)
+	return attrs, c.filter(baggage.FromContext(ctx)), trace.SpanContextFromContext(ctx)
 }
 
 func Inject(ctx context.Context, msg *nats.Msg, opts ...Option) {
@@ -60,7 +92,41 @@ func Inject(ctx context.Context, msg *nats.Msg, opts ...Option) {
 		msg.Header = make(nats.Header)
 	}
 
+	if c.baggageFilter != nil {
+		ctx = baggage.ContextWithBaggage(ctx, c.filter(baggage.FromContext(ctx)))
+	}
+
 	c.propagators.Inject(ctx, propagation.HeaderCarrier(msg.Header))
+
+	if c.fields != nil {
+		c.fields.Inject(ctx, msg.Header.Set)
+	}
+}
+
+// Clean removes headers written by propagators from msg, e.g. headers copied from another message,
+// so stale trace context or baggage isn't forwarded when Inject has nothing to write
+func Clean(msg *nats.Msg, opts ...Option) {
+	c := newConfig(opts)
+
+	for _, f := range c.propagators.Fields() {
+		delete(msg.Header, textproto.CanonicalMIMEHeaderKey(f))
+		delete(msg.Header, f)
+	}
+}
+
+// filter drops baggage members rejected by baggageFilter
+func (c *config) filter(b baggage.Baggage) baggage.Baggage {
+	if c.baggageFilter == nil {
+		return b
+	}
+
+	for _, m := range b.Members() {
+		if !c.baggageFilter(m) {
+			b = b.DeleteMember(m.Key())
+		}
+	}
+
+	return b
 }
 
 func NewAttributesFromNATSRequest(msg *nats.Msg) []attribute.KeyValue {
@@ -7,9 +7,13 @@ import (
 
 	"github.com/nats-io/nats.go"
 	"github.com/stretchr/testify/assert"
+	"github.com/tel-io/tel/v2"
 	"go.opentelemetry.io/otel/baggage"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 // use trace propagation
@@ -69,3 +73,96 @@ func TestBaggage(t *testing.T) {
 		assert.Equal(t, mValie, member.Value())
 	})
 }
+
+func TestBaggageAllowList(t *testing.T) {
+	allowed, err := baggage.NewMember("tenant", "a")
+	assert.NoError(t, err)
+	denied, err := baggage.NewMember("kind_of", "SUB")
+	assert.NoError(t, err)
+	bag, err := baggage.New(allowed, denied)
+	assert.NoError(t, err)
+
+	ctx := baggage.ContextWithBaggage(context.Background(), bag)
+	prop := propagation.NewCompositeTextMapPropagator(propagation.Baggage{})
+
+	msg := new(nats.Msg)
+	Inject(ctx, msg, WithPropagators(prop), WithBaggageAllowList("tenant"))
+
+	_, bg, _ := Extract(context.Background(), msg, WithPropagators(prop))
+	assert.Equal(t, "a", bg.Member("tenant").Value())
+	assert.Empty(t, bg.Member("kind_of").Key())
+
+	// extract is filtered as well
+	msg = new(nats.Msg)
+	Inject(ctx, msg, WithPropagators(prop))
+
+	_, bg, _ = Extract(context.Background(), msg, WithPropagators(prop), WithBaggageAllowList("tenant"))
+	assert.Equal(t, 1, bg.Len())
+}
+
+func TestLogFields(t *testing.T) {
+	opts := []Option{
+		WithPropagators(propagation.TraceContext{}),
+		WithLogFields("order_id", "tenant", "note"),
+		WithLogFieldLimits(10, 100),
+	}
+
+	producer := tel.NewNull()
+	producer.Logger = RecordFields(producer.Logger)
+	producer.PutFields(
+		zap.String("order_id", "42"),
+		zap.String("tenant", "acme corp"),
+		zap.String("note", "too long value"),
+		zap.String("secret", "pass"),
+	)
+
+	msg := new(nats.Msg)
+	Inject(producer.Ctx(), msg, opts...)
+
+	assert.Equal(t, "42", msg.Header.Get("Tel-Field-order_id"))
+	assert.Equal(t, "acme+corp", msg.Header.Get("Tel-Field-tenant"))
+	assert.Empty(t, msg.Header.Get("Tel-Field-note"))
+	assert.Empty(t, msg.Header.Get("Tel-Field-secret"))
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	consumer := tel.NewNull()
+	consumer.Logger = zap.New(core)
+
+	ctx := consumer.Ctx()
+	Extract(ctx, msg, opts...)
+	tel.FromCtx(ctx).Info("handle")
+
+	if assert.Equal(t, 1, logs.Len()) {
+		assert.Equal(t, map[string]any{"order_id": "42", "tenant": "acme corp"},
+			logs.All()[0].ContextMap())
+	}
+
+	t.Run("forward", func(t *testing.T) {
+		next := new(nats.Msg)
+		Inject(ctx, next, WithLogFields("tenant"), WithLogFieldPrefix("X-"))
+
+		assert.Equal(t, "acme+corp", next.Header.Get("X-tenant"))
+		assert.Empty(t, next.Header.Get("X-order_id"))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		next := new(nats.Msg)
+		Inject(producer.Ctx(), next, WithPropagators(propagation.TraceContext{}))
+
+		assert.Empty(t, next.Header)
+	})
+}
+
+func TestClean(t *testing.T) {
+	prop := propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{})
+
+	msg := nats.NewMsg("clean")
+	msg.Header.Set("Traceparent", "00-03000000000000000000000000000000-0300000000000000-01")
+	msg.Header.Set("Baggage", "secret=pass")
+	msg.Header.Set("Order", "42")
+
+	Clean(msg, WithPropagators(prop))
+
+	assert.Equal(t, nats.Header{"Order": {"42"}}, msg.Header)
+}
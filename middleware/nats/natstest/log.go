@@ -0,0 +1,33 @@
+package natstest
+
+import (
+	"testing"
+
+	natsmw "github.com/tel-io/instrumentation/middleware/nats/v2"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// LogsBy log entries with subject and kind_of fields, empty value matches any
+func (e *Env) LogsBy(subject, kind string) []observer.LoggedEntry {
+	return e.Logs.Filter(func(entry observer.LoggedEntry) bool {
+		fields := entry.ContextMap()
+
+		return (subject == "" || fields[string(natsmw.Subject)] == subject) &&
+			(kind == "" || fields[string(natsmw.Kind)] == kind)
+	}).AllUntimed()
+}
+
+// AssertLog waits log entry matching subject and kind and returns the first one
+func (e *Env) AssertLog(t testing.TB, subject, kind string) observer.LoggedEntry {
+	t.Helper()
+
+	var res []observer.LoggedEntry
+	if !e.eventually(func() bool {
+		res = e.LogsBy(subject, kind)
+		return len(res) > 0
+	}) {
+		t.Fatalf("natstest: no log entry with subject %q and kind %q, got %d entries", subject, kind, e.Logs.Len())
+	}
+
+	return res[0]
+}
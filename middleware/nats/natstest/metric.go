@@ -0,0 +1,108 @@
+package natstest
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// Collect metrics recorded by Core
+func (e *Env) Collect(t testing.TB) []metricdata.Metrics {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	if err := e.Reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("natstest: collect metrics: %v", err)
+	}
+
+	var res []metricdata.Metrics
+	for _, sm := range rm.ScopeMetrics {
+		res = append(res, sm.Metrics...)
+	}
+
+	return res
+}
+
+// Value of metric points having all attrs: sum of counters and gauges, count of histogram
+// ok is false when there isn't any matched point.
+func (e *Env) Value(t testing.TB, name string, attrs ...attribute.KeyValue) (v float64, ok bool) {
+	t.Helper()
+
+	for _, m := range e.Collect(t) {
+		if m.Name != name {
+			continue
+		}
+
+		switch data := m.Data.(type) {
+		case metricdata.Sum[int64]:
+			v, ok = sumPoints(data.DataPoints, attrs, v, ok)
+		case metricdata.Sum[float64]:
+			v, ok = sumPoints(data.DataPoints, attrs, v, ok)
+		case metricdata.Gauge[int64]:
+			v, ok = sumPoints(data.DataPoints, attrs, v, ok)
+		case metricdata.Gauge[float64]:
+			v, ok = sumPoints(data.DataPoints, attrs, v, ok)
+		case metricdata.Histogram[int64]:
+			v, ok = countPoints(data.DataPoints, attrs, v, ok)
+		case metricdata.Histogram[float64]:
+			v, ok = countPoints(data.DataPoints, attrs, v, ok)
+		}
+	}
+
+	return v, ok
+}
+
+// AssertMetric waits metric point having all attrs and returns its Value
+func (e *Env) AssertMetric(t testing.TB, name string, attrs ...attribute.KeyValue) float64 {
+	t.Helper()
+
+	var (
+		v  float64
+		ok bool
+	)
+
+	if !e.eventually(func() bool {
+		v, ok = e.Value(t, name, attrs...)
+		return ok
+	}) {
+		t.Fatalf("natstest: no point of metric %q with attributes %v", name, attrs)
+	}
+
+	return v
+}
+
+func sumPoints[N int64 | float64](points []metricdata.DataPoint[N], attrs []attribute.KeyValue,
+	v float64, ok bool) (float64, bool) {
+	for _, p := range points {
+		if hasAttrs(p.Attributes, attrs) {
+			v += float64(p.Value)
+			ok = true
+		}
+	}
+
+	return v, ok
+}
+
+func countPoints[N int64 | float64](points []metricdata.HistogramDataPoint[N], attrs []attribute.KeyValue,
+	v float64, ok bool) (float64, bool) {
+	for _, p := range points {
+		if hasAttrs(p.Attributes, attrs) {
+			v += float64(p.Count)
+			ok = true
+		}
+	}
+
+	return v, ok
+}
+
+func hasAttrs(set attribute.Set, attrs []attribute.KeyValue) bool {
+	for _, kv := range attrs {
+		if v, ok := set.Value(kv.Key); !ok || v != kv.Value {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,218 @@
+// Package natstest runs embedded nats-server and wires nats middleware Core to in-memory telemetry recorders.
+// It doesn't require any external service, so tests run offline.
+package natstest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	natsmw "github.com/tel-io/instrumentation/middleware/nats/v2"
+	"github.com/tel-io/instrumentation/middleware/nats/v2/natsprop"
+	"github.com/tel-io/tel/v2"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// DefaultTimeout of waiting span, metric point or log entry
+const DefaultTimeout = 5 * time.Second
+
+// Option of test environment
+type Option func(*config)
+
+type config struct {
+	jetStream   bool
+	coreOpts    []natsmw.Option
+	connOpts    []nats.Option
+	serverOptFn func(*server.Options)
+	timeout     time.Duration
+}
+
+// WithJetStream enables JetStream with storage in temporary directory of the test
+func WithJetStream() Option {
+	return func(c *config) {
+		c.jetStream = true
+	}
+}
+
+// WithCoreOptions passed to natsmw.New after telemetry options, so they could override them
+func WithCoreOptions(opts ...natsmw.Option) Option {
+	return func(c *config) {
+		c.coreOpts = append(c.coreOpts, opts...)
+	}
+}
+
+// WithConnOptions passed to nats.Connect
+func WithConnOptions(opts ...nats.Option) Option {
+	return func(c *config) {
+		c.connOpts = append(c.connOpts, opts...)
+	}
+}
+
+// WithServerOptions modifies embedded server options before start
+func WithServerOptions(fn func(*server.Options)) Option {
+	return func(c *config) {
+		c.serverOptFn = fn
+	}
+}
+
+// WithTimeout of waiting in Assert* helpers
+// Default: DefaultTimeout
+func WithTimeout(d time.Duration) Option {
+	return func(c *config) {
+		c.timeout = d
+	}
+}
+
+// Env is test environment: embedded server, connection instrumented by Core and telemetry recorders
+type Env struct {
+	Server *server.Server
+	Conn   *nats.Conn
+	Core   *natsmw.Core
+	// Ctx is Conn instrumented by Core
+	Ctx *natsmw.ConnContext
+	// Tel writes logs to Logs, spans and metrics of Core are collected by Spans and Reader
+	Tel tel.Telemetry
+
+	Spans  *tracetest.SpanRecorder
+	Reader *sdkmetric.ManualReader
+	Logs   *observer.ObservedLogs
+
+	timeout time.Duration
+}
+
+// New starts embedded server and connects to it, everything is closed on test cleanup
+func New(t testing.TB, opts ...Option) *Env {
+	t.Helper()
+
+	c := &config{timeout: DefaultTimeout}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	so := &server.Options{
+		Host:   "127.0.0.1",
+		Port:   server.RANDOM_PORT,
+		NoLog:  true,
+		NoSigs: true,
+	}
+
+	if c.jetStream {
+		so.JetStream = true
+		so.StoreDir = t.TempDir()
+	}
+
+	if c.serverOptFn != nil {
+		c.serverOptFn(so)
+	}
+
+	srv, err := server.NewServer(so)
+	if err != nil {
+		t.Fatalf("natstest: create server: %v", err)
+	}
+
+	go srv.Start()
+
+	if !srv.ReadyForConnections(c.timeout) {
+		srv.Shutdown()
+		t.Fatalf("natstest: server is not ready for connections")
+	}
+
+	t.Cleanup(func() {
+		srv.Shutdown()
+		srv.WaitForShutdown()
+	})
+
+	spans := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	t.Cleanup(func() {
+		_ = tp.Shutdown(context.Background())
+		_ = mp.Shutdown(context.Background())
+	})
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	tele := tel.NewNull()
+	tele.Logger = zap.New(core)
+
+	mw := natsmw.New(append([]natsmw.Option{
+		natsmw.WithTel(tele),
+		natsmw.WithTracerProvider(tp),
+		natsmw.WithMeterProvider(mp),
+		// spans are matched by messaging attributes, legacy metrics are kept for AssertMetric
+		natsmw.WithSemConv(natsmw.SemConvBoth),
+		// global propagator is noop unless tel is started
+		natsmw.WithPropagation(natsprop.WithPropagators(
+			propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}),
+		)),
+	}, c.coreOpts...)...)
+
+	conn, err := nats.Connect(srv.ClientURL(), append(c.connOpts, mw.ConnOption())...)
+	if err != nil {
+		_ = mw.Close()
+		t.Fatalf("natstest: connect: %v", err)
+	}
+
+	t.Cleanup(func() {
+		conn.Close()
+
+		if err := mw.Close(); err != nil {
+			t.Errorf("natstest: close core: %v", err)
+		}
+	})
+
+	return &Env{
+		Server:  srv,
+		Conn:    conn,
+		Core:    mw,
+		Ctx:     mw.Use(conn),
+		Tel:     tele,
+		Spans:   spans,
+		Reader:  reader,
+		Logs:    logs,
+		timeout: c.timeout,
+	}
+}
+
+// Context returns ctx with Tel, use it for publish calls to get log entries in Logs
+func (e *Env) Context() context.Context {
+	return e.Tel.Ctx()
+}
+
+// JetStream context of instrumented connection, requires WithJetStream
+func (e *Env) JetStream(t testing.TB, opts ...nats.JSOpt) *natsmw.JetStreamContext {
+	t.Helper()
+
+	js, err := e.Ctx.JetStream(opts...)
+	if err != nil {
+		t.Fatalf("natstest: jetstream: %v", err)
+	}
+
+	return js
+}
+
+// eventually calls fn until it returns true or timeout is reached
+func (e *Env) eventually(fn func() bool) bool {
+	deadline := time.Now().Add(e.timeout)
+
+	for {
+		if fn() {
+			return true
+		}
+
+		if time.Now().After(deadline) {
+			return false
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
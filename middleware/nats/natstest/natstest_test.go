@@ -0,0 +1,103 @@
+package natstest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	natsmw "github.com/tel-io/instrumentation/middleware/nats/v2"
+	"github.com/tel-io/instrumentation/middleware/nats/v2/natsprop"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+func TestEnv(t *testing.T) {
+	env := New(t)
+
+	done := make(chan struct{})
+	_, err := env.Ctx.Subscribe("natstest.demo", func(ctx context.Context, msg *nats.Msg) error {
+		close(done)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = env.Ctx.PublishWithContext(env.Context(), "natstest.demo", []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+
+	<-done
+
+	pub := env.AssertSpan(t, "natstest.demo", natsmw.KindPub)
+	if pub.SpanKind() != trace.SpanKindProducer {
+		t.Errorf("publish span kind %v", pub.SpanKind())
+	}
+
+	sub := env.AssertSpan(t, "natstest.demo", natsmw.KindSub)
+	if sub.Parent().SpanID() != pub.SpanContext().SpanID() {
+		t.Errorf("subscriber span isn't child of publish span")
+	}
+
+	if v := env.AssertMetric(t, natsmw.Count, natsmw.Kind.String(natsmw.KindSub)); v != 1 {
+		t.Errorf("count %v, want 1", v)
+	}
+
+	if entry := env.AssertLog(t, "natstest.demo", natsmw.KindSub); entry.ContextMap()["payload"] != nil {
+		t.Errorf("payload is dumped: %v", entry.ContextMap())
+	}
+
+	if n := len(env.SpansBy("natstest.other", "")); n != 0 {
+		t.Errorf("unexpected spans %d", n)
+	}
+}
+
+func TestEnv_JetStream(t *testing.T) {
+	env := New(t, WithJetStream())
+	js := env.JetStream(t)
+
+	if _, err := js.JS().AddStream(&nats.StreamConfig{Name: "DEMO", Subjects: []string{"natstest.js.>"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	sub, err := js.PullSubscribe("natstest.js.demo", "demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = js.JS().Publish("natstest.js.demo", []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+
+	msgs, err := sub.Fetch(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(msgs) != 1 {
+		t.Fatalf("fetched %d messages", len(msgs))
+	}
+}
+
+func TestEnv_LogFields(t *testing.T) {
+	env := New(t, WithCoreOptions(natsmw.WithPropagation(natsprop.WithLogFields("order_id"))))
+
+	_, err := env.Ctx.Subscribe("natstest.fields", func(ctx context.Context, msg *nats.Msg) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	producer := env.Tel
+	producer.Logger = natsprop.RecordFields(producer.Logger).With(zap.String("order_id", "42"))
+
+	if err = env.Ctx.PublishWithContext(producer.Ctx(), "natstest.fields", []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+
+	entry := env.AssertLog(t, "natstest.fields", natsmw.KindSub)
+	if v := entry.ContextMap()["order_id"]; v != "42" {
+		t.Errorf("order_id %v, want 42", v)
+	}
+}
@@ -0,0 +1,75 @@
+package natstest
+
+import (
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	natsmw "github.com/tel-io/instrumentation/middleware/nats/v2"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// SpansBy ended spans matching subject and kind, empty value matches any
+// Subject is matched by subject or messaging.destination.name attributes,
+// kind by kind_of or messaging.operation.name attributes.
+func (e *Env) SpansBy(subject, kind string) []sdktrace.ReadOnlySpan {
+	var res []sdktrace.ReadOnlySpan
+
+	for _, span := range e.Spans.Ended() {
+		if matchAttrs(span.Attributes(), subject, kind) {
+			res = append(res, span)
+		}
+	}
+
+	return res
+}
+
+// AssertSpan waits ended span matching subject and kind and returns the first one
+func (e *Env) AssertSpan(t testing.TB, subject, kind string) sdktrace.ReadOnlySpan {
+	t.Helper()
+
+	var res []sdktrace.ReadOnlySpan
+	if !e.eventually(func() bool {
+		res = e.SpansBy(subject, kind)
+		return len(res) > 0
+	}) {
+		t.Fatalf("natstest: no span with subject %q and kind %q, got %d spans", subject, kind, len(e.Spans.Ended()))
+	}
+
+	return res[0]
+}
+
+// matchAttrs reports whether attrs contain subject and kind, empty value matches any
+func matchAttrs(attrs []attribute.KeyValue, subject, kind string) bool {
+	subjectOk, kindOk := subject == "", kind == ""
+	operation := operationName(kind)
+
+	for _, kv := range attrs {
+		switch kv.Key {
+		case natsmw.Subject, semconv.MessagingDestinationNameKey:
+			subjectOk = subjectOk || kv.Value.Emit() == subject
+		case natsmw.Kind:
+			kindOk = kindOk || kv.Value.Emit() == kind
+		case semconv.MessagingOperationNameKey:
+			kindOk = kindOk || kv.Value.Emit() == operation
+		}
+	}
+
+	return subjectOk && kindOk
+}
+
+// operationName messaging.operation.name of spans of kind
+func operationName(kind string) string {
+	if kind == "" {
+		return ""
+	}
+
+	for _, kv := range natsmw.MessagingAttributes(&nats.Msg{}, kind) {
+		if kv.Key == semconv.MessagingOperationNameKey {
+			return kv.Value.Emit()
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,191 @@
+package nats
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ObjectStore wrapper of legacy nats.ObjectStore: put, get and delete accept ctx and have own span, metrics and logs.
+// Span of Get covers obtaining of object info only, reading of result is not covered.
+// Other functions are used as is.
+type ObjectStore struct {
+	nats.ObjectStore
+
+	core   *Core
+	bucket string
+}
+
+// WrapObjectStore returns instrumented nats.ObjectStore
+func (c *Core) WrapObjectStore(bucket string, obs nats.ObjectStore) *ObjectStore {
+	return &ObjectStore{ObjectStore: obs, core: c, bucket: bucket}
+}
+
+// ObjectStore binds to existing bucket and returns instrumented wrapper
+func (j *JetStreamContext) ObjectStore(bucket string) (*ObjectStore, error) {
+	obs, err := j.js.ObjectStore(bucket)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return j.WrapObjectStore(bucket, obs), nil
+}
+
+// CreateObjectStore creates bucket and returns instrumented wrapper
+func (j *JetStreamContext) CreateObjectStore(cfg *nats.ObjectStoreConfig) (*ObjectStore, error) {
+	obs, err := j.js.CreateObjectStore(cfg)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return j.WrapObjectStore(cfg.Bucket, obs), nil
+}
+
+// OBS unwrap
+func (o *ObjectStore) OBS() nats.ObjectStore {
+	return o.ObjectStore
+}
+
+// Put will place the contents from the reader into the store
+func (o *ObjectStore) Put(ctx context.Context, meta *nats.ObjectMeta, r io.Reader, opts ...nats.ObjectOpt) (info *nats.ObjectInfo, err error) {
+	err = o.core.storeOp(ctx, KindObjPut, ObjectLatency, o.bucket, meta.Name, func(context.Context) ([]attribute.KeyValue, error) {
+		info, err = o.ObjectStore.Put(meta, r, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		return []attribute.KeyValue{ObjectSize.Int64(int64(info.Size))}, nil
+	})
+
+	return info, err
+}
+
+// PutBytes is convenience function to put a byte slice into this object store
+func (o *ObjectStore) PutBytes(ctx context.Context, name string, data []byte, opts ...nats.ObjectOpt) (*nats.ObjectInfo, error) {
+	return o.Put(ctx, &nats.ObjectMeta{Name: name}, bytes.NewReader(data), opts...)
+}
+
+// Get will pull the named object from the object store
+func (o *ObjectStore) Get(ctx context.Context, name string, opts ...nats.GetObjectOpt) (res nats.ObjectResult, err error) {
+	err = o.core.storeOp(ctx, KindObjGet, ObjectLatency, o.bucket, name, func(context.Context) ([]attribute.KeyValue, error) {
+		res, err = o.ObjectStore.Get(name, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		return objectAttrs(res.Info())
+	})
+
+	return res, err
+}
+
+// GetBytes is convenience function to pull an object from this object store and return it as a byte slice
+func (o *ObjectStore) GetBytes(ctx context.Context, name string, opts ...nats.GetObjectOpt) (data []byte, err error) {
+	err = o.core.storeOp(ctx, KindObjGet, ObjectLatency, o.bucket, name, func(context.Context) ([]attribute.KeyValue, error) {
+		data, err = o.ObjectStore.GetBytes(name, opts...)
+		return []attribute.KeyValue{ObjectSize.Int(len(data))}, err
+	})
+
+	return data, err
+}
+
+// Delete will delete the named object
+func (o *ObjectStore) Delete(ctx context.Context, name string) error {
+	return o.core.storeOp(ctx, KindObjDelete, ObjectLatency, o.bucket, name, func(context.Context) ([]attribute.KeyValue, error) {
+		return nil, o.ObjectStore.Delete(name)
+	})
+}
+
+// JetStreamObjectStore wrapper of jetstream.ObjectStore: put, get and delete have own span, metrics and logs.
+// Span of Get covers obtaining of object info only, reading of result is not covered.
+// Other functions are used as is.
+type JetStreamObjectStore struct {
+	jetstream.ObjectStore
+
+	core   *Core
+	bucket string
+}
+
+// WrapJetStreamObjectStore returns instrumented jetstream.ObjectStore
+func (c *Core) WrapJetStreamObjectStore(bucket string, obs jetstream.ObjectStore) *JetStreamObjectStore {
+	return &JetStreamObjectStore{ObjectStore: obs, core: c, bucket: bucket}
+}
+
+// Put will place the contents from the reader into the store
+func (o *JetStreamObjectStore) Put(ctx context.Context, meta jetstream.ObjectMeta, r io.Reader) (info *jetstream.ObjectInfo, err error) {
+	err = o.core.storeOp(ctx, KindObjPut, ObjectLatency, o.bucket, meta.Name, func(ctx context.Context) ([]attribute.KeyValue, error) {
+		info, err = o.ObjectStore.Put(ctx, meta, r)
+		if err != nil {
+			return nil, err
+		}
+
+		return []attribute.KeyValue{ObjectSize.Int64(int64(info.Size))}, nil
+	})
+
+	return info, err
+}
+
+// PutBytes is convenience function to put a byte slice into this object store
+func (o *JetStreamObjectStore) PutBytes(ctx context.Context, name string, data []byte) (*jetstream.ObjectInfo, error) {
+	return o.Put(ctx, jetstream.ObjectMeta{Name: name}, bytes.NewReader(data))
+}
+
+// PutString is convenience function to put a string into this object store
+func (o *JetStreamObjectStore) PutString(ctx context.Context, name string, data string) (*jetstream.ObjectInfo, error) {
+	return o.PutBytes(ctx, name, []byte(data))
+}
+
+// Get will pull the named object from the object store
+func (o *JetStreamObjectStore) Get(ctx context.Context, name string, opts ...jetstream.GetObjectOpt) (res jetstream.ObjectResult, err error) {
+	err = o.core.storeOp(ctx, KindObjGet, ObjectLatency, o.bucket, name, func(ctx context.Context) ([]attribute.KeyValue, error) {
+		res, err = o.ObjectStore.Get(ctx, name, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		info, err := res.Info()
+		if err != nil {
+			return nil, err
+		}
+
+		return []attribute.KeyValue{ObjectSize.Int64(int64(info.Size))}, nil
+	})
+
+	return res, err
+}
+
+// GetBytes is convenience function to pull an object from this object store and return it as a byte slice
+func (o *JetStreamObjectStore) GetBytes(ctx context.Context, name string, opts ...jetstream.GetObjectOpt) (data []byte, err error) {
+	err = o.core.storeOp(ctx, KindObjGet, ObjectLatency, o.bucket, name, func(ctx context.Context) ([]attribute.KeyValue, error) {
+		data, err = o.ObjectStore.GetBytes(ctx, name, opts...)
+		return []attribute.KeyValue{ObjectSize.Int(len(data))}, err
+	})
+
+	return data, err
+}
+
+// GetString is convenience function to pull an object from this object store and return it as a string
+func (o *JetStreamObjectStore) GetString(ctx context.Context, name string, opts ...jetstream.GetObjectOpt) (string, error) {
+	data, err := o.GetBytes(ctx, name, opts...)
+	return string(data), err
+}
+
+// Delete will delete the named object
+func (o *JetStreamObjectStore) Delete(ctx context.Context, name string) error {
+	return o.core.storeOp(ctx, KindObjDelete, ObjectLatency, o.bucket, name, func(ctx context.Context) ([]attribute.KeyValue, error) {
+		return nil, o.ObjectStore.Delete(ctx, name)
+	})
+}
+
+func objectAttrs(info *nats.ObjectInfo, err error) ([]attribute.KeyValue, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return []attribute.KeyValue{ObjectSize.Int64(int64(info.Size))}, nil
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/nats-io/nats.go"
+	"github.com/tel-io/instrumentation/middleware/nats/v2/natsprop"
 	"github.com/tel-io/tel/v2"
 	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
@@ -85,11 +88,37 @@ type config struct {
 	meter   metric.Meter
 	metrics *metrics
 
+	// override providers of tele, nil if not set
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	tracer         trace.Tracer
+
 	dump               bool
 	dumpPayloadOnError bool
+	connTelemetry      bool
+	connRTTInterval    time.Duration
+	consumerNewRoot    bool
+	publishTime        bool
+	subSeries          bool
+
+	// bound of async PubAck waiting, 0 is unlimited
+	pubAckTimeout time.Duration
+
+	semConv SemConv
+
+	// decodes payload dumps, nil if disabled
+	payload *PayloadRegistry
+
+	// options of trace context and baggage propagation
+	propOpts []natsprop.Option
 
 	nameFn NameFn
 
+	// JetStream auto acknowledgement, nil if disabled
+	ackOpts []AckOption
+	// Retry middleware, nil if disabled
+	retryOpts []RetryOption
+
 	// subMiddleware processors
 	notUserDefaultMW bool
 	pubList          []Middleware
@@ -100,18 +129,35 @@ func newConfig(opts []Option) *config {
 	c := &config{
 		tele:               tel.Global(),
 		dumpPayloadOnError: true,
+		connRTTInterval:    DefaultConnRTTInterval,
+		pubAckTimeout:      DefaultPubAckTimeout,
 		notUserDefaultMW:   false,
 		nameFn:             defaultOperationFn,
 	}
 
 	c.apply(opts)
 
-	c.meter = c.tele.Meter(
-		instrumentationName,
-		metric.WithInstrumentationVersion(SemVersion()),
-	)
+	if c.meterProvider != nil {
+		c.meter = c.meterProvider.Meter(
+			instrumentationName,
+			metric.WithInstrumentationVersion(SemVersion()),
+		)
+	} else {
+		c.meter = c.tele.Meter(
+			instrumentationName,
+			metric.WithInstrumentationVersion(SemVersion()),
+		)
+	}
+
+	if c.tracerProvider != nil {
+		c.tracer = c.tracerProvider.Tracer(
+			instrumentationName,
+			trace.WithInstrumentationVersion(SemVersion()),
+		)
+	}
 
 	c.metrics = createMeasures(c.tele, c.meter)
+	c.metrics.semConv = c.semConv
 
 	return c
 }
@@ -126,18 +172,67 @@ func (c *config) apply(opts []Option) {
 func (c *config) DefaultMiddleware() []Middleware {
 	return []Middleware{
 		NewRecovery(),
-		NewLogs(c.nameFn, c.dumpPayloadOnError, c.dump),
-		NewTracer(c.nameFn),
+		&Logs{
+			nameFn:             c.nameFn,
+			dumpPayloadOnError: c.dumpPayloadOnError,
+			dump:               c.dump,
+			payload:            c.payload,
+		},
+		&Tracer{
+			tracer:      c.tracer,
+			nameFn:      c.nameFn,
+			semConv:     c.semConv,
+			propOpts:    c.propOpts,
+			newRoot:     c.consumerNewRoot,
+			publishTime: c.publishTime,
+
+			payload:            c.payload,
+			dump:               c.dump,
+			dumpPayloadOnError: c.dumpPayloadOnError,
+		},
 		NewMetrics(c.metrics),
 	}
 }
 
-func (c *config) subMiddleware() []Middleware {
+// subMiddleware list, pub is publisher chain used by Retry for dead-lettered messages
+func (c *config) subMiddleware(pub Interceptor) []Middleware {
 	if c.notUserDefaultMW {
-		return c.subList
+		return c.withRetry(c.withAck(c.subList, 0), 0, pub)
+	}
+
+	// inside tracer to put metadata into span, outside of recovery to nak on panic
+	// retry is inside ack, so message is acked by outcome of the final attempt
+	return append(c.withRetry(c.withAck(c.DefaultMiddleware(), 1), 1, pub), c.subList...)
+}
+
+// withRetry insert Retry into list at pos when it's enabled
+func (c *config) withRetry(list []Middleware, pos int, pub Interceptor) []Middleware {
+	if c.retryOpts == nil {
+		return list
+	}
+
+	r := NewRetry(c.metrics, c.retryOpts...)
+	r.pubInter = pub
+	r.propOpts = c.propOpts
+
+	res := make([]Middleware, 0, len(list)+1)
+	res = append(res, list[:pos]...)
+	res = append(res, r)
+
+	return append(res, list[pos:]...)
+}
+
+// withAck insert JetStreamAck into list at pos when it's enabled
+func (c *config) withAck(list []Middleware, pos int) []Middleware {
+	if c.ackOpts == nil {
+		return list
 	}
 
-	return append(c.DefaultMiddleware(), c.subList...)
+	res := make([]Middleware, 0, len(list)+1)
+	res = append(res, list[:pos]...)
+	res = append(res, NewJetStreamAck(c.metrics, c.ackOpts...))
+
+	return append(res, list[pos:]...)
 }
 
 func (c *config) pubMiddleware() []Middleware {
@@ -155,6 +250,20 @@ func WithTel(t tel.Telemetry) Option {
 	})
 }
 
+// WithTracerProvider use tp instead of tel tracer provider, e.g. in-memory span recorder in tests
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return optionFunc(func(c *config) {
+		c.tracerProvider = tp
+	})
+}
+
+// WithMeterProvider use mp instead of tel meter provider, e.g. manual reader in tests
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return optionFunc(func(c *config) {
+		c.meterProvider = mp
+	})
+}
+
 // WithDump dump request as plain text to log and trace
 // i guess we can go further and perform option with encoding requests
 func WithDump(enable bool) Option {
@@ -163,6 +272,14 @@ func WithDump(enable bool) Option {
 	})
 }
 
+// WithPayloadRegistry decode payload dumps with registry: logs get decoded and redacted payload,
+// Tracer adds it as span event. See WithDump and WithDumpPayloadOnError
+func WithPayloadRegistry(r *PayloadRegistry) Option {
+	return optionFunc(func(c *config) {
+		c.payload = r
+	})
+}
+
 // WithDumpPayloadOnError write dump request and response on faults
 //
 // Default: true
@@ -172,6 +289,85 @@ func WithDumpPayloadOnError(enable bool) Option {
 	})
 }
 
+// WithConnTelemetry install connection event handlers on Use and expose connection statistics.
+// Handlers set on connection are chained with telemetry ones.
+//
+// Default: false
+func WithConnTelemetry(enable bool) Option {
+	return optionFunc(func(c *config) {
+		c.connTelemetry = enable
+	})
+}
+
+// WithConnRTTInterval measure round trip time of connections with connection telemetry by interval
+// in background, 0 disables it
+//
+// Default: DefaultConnRTTInterval
+func WithConnRTTInterval(d time.Duration) Option {
+	return optionFunc(func(c *config) {
+		c.connRTTInterval = d
+	})
+}
+
+// WithSubscriptionSeries report subscription statistics per subscription with subscription.id attribute
+// instead of aggregation by subject and queue. Use it for debugging: every subscription is a new series.
+//
+// Default: false
+func WithSubscriptionSeries(enable bool) Option {
+	return optionFunc(func(c *config) {
+		c.subSeries = enable
+	})
+}
+
+// WithConsumerNewRoot start consumer spans as new roots with link to producer span instead of child spans,
+// it keeps traces of fan-out subjects and queue groups small
+//
+// Default: false
+func WithConsumerNewRoot(enable bool) Option {
+	return optionFunc(func(c *config) {
+		c.consumerNewRoot = enable
+	})
+}
+
+// WithPublishTimestamp stamp HeaderPublishTime on published messages,
+// consumers record end-to-end latency nats.e2e.duration when it's present
+//
+// Default: false
+func WithPublishTimestamp(enable bool) Option {
+	return optionFunc(func(c *config) {
+		c.publishTime = enable
+	})
+}
+
+// WithPubAckTimeout bounds waiting of PubAck of JetStream async publish: PubAck span is ended with
+// ErrPubAckTimeout after d. Waiting is stopped on connection close as well. 0 means no timeout.
+//
+// Default: DefaultPubAckTimeout
+func WithPubAckTimeout(d time.Duration) Option {
+	return optionFunc(func(c *config) {
+		c.pubAckTimeout = d
+	})
+}
+
+// WithSemConv set naming scheme of span attributes and metrics.
+// SemConvMessaging puts messaging semantic conventions attributes into spans and replaces
+// nats.count, nats.content_length and nats.duration with messaging.* metrics, SemConvBoth emits both metrics.
+//
+// Default: SemConvLegacy
+func WithSemConv(s SemConv) Option {
+	return optionFunc(func(c *config) {
+		c.semConv = s
+	})
+}
+
+// WithPropagation set options of trace context and baggage propagation via message headers,
+// e.g. natsprop.WithBaggageAllowList to limit baggage sent to downstream services
+func WithPropagation(opts ...natsprop.Option) Option {
+	return optionFunc(func(c *config) {
+		c.propOpts = append(c.propOpts, opts...)
+	})
+}
+
 func WithNameFunction(fn NameFn) Option {
 	return optionFunc(func(c *config) {
 		c.nameFn = fn
@@ -192,6 +388,24 @@ func WithPubMiddleware(list ...Middleware) Option {
 	})
 }
 
+// WithJetStreamAck enable JetStreamAck middleware: JetStream messages are acked when handler returned nil,
+// naked with backoff on error and terminated on permanent errors.
+// With WithDisableDefaultMiddleware it's the innermost middleware of subscription chain.
+func WithJetStreamAck(opts ...AckOption) Option {
+	return optionFunc(func(c *config) {
+		c.ackOpts = append(make([]AckOption, 0, len(opts)), opts...)
+	})
+}
+
+// WithRetry enable Retry middleware: failed handler is called again with backoff,
+// after the final attempt message is republished to dead-letter subject when WithDeadLetter is set.
+// It's inside JetStreamAck, with WithDisableDefaultMiddleware it's the innermost middleware of subscription chain.
+func WithRetry(opts ...RetryOption) Option {
+	return optionFunc(func(c *config) {
+		c.retryOpts = append(make([]RetryOption, 0, len(opts)), opts...)
+	})
+}
+
 // WithDisableDefaultMiddleware disable default middleware usage
 func WithDisableDefaultMiddleware() Option {
 	return optionFunc(func(c *config) {
@@ -0,0 +1,400 @@
+package nats
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Headers used by PayloadRegistry to select codec
+const (
+	HeaderContentType     = "Content-Type"
+	HeaderContentEncoding = "Content-Encoding"
+)
+
+// RedactedValue replaces value of redacted field
+const RedactedValue = "[REDACTED]"
+
+// DefaultMaxDecodedSize limits decompressed payload of dump, see WithMaxDecodedSize
+const DefaultMaxDecodedSize = 1 << 20
+
+// ErrPayloadTooLarge decompressed payload exceeds limit
+var ErrPayloadTooLarge = errors.New("payload too large")
+
+// PayloadCodec decodes message payload into value which is dumped as JSON
+type PayloadCodec func(data []byte) (any, error)
+
+// JSONCodec decodes JSON payload
+func JSONCodec(data []byte) (any, error) {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return v, nil
+}
+
+// MsgpackCodec decodes msgpack payload
+func MsgpackCodec(data []byte) (any, error) {
+	var v any
+	if err := msgpack.Unmarshal(data, &v); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return v, nil
+}
+
+// ProtobufCodec decodes protobuf payload of message described by md
+func ProtobufCodec(md protoreflect.MessageDescriptor) PayloadCodec {
+	return func(data []byte) (any, error) {
+		m := dynamicpb.NewMessage(md)
+		if err := proto.Unmarshal(data, m); err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		b, err := protojson.Marshal(m)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		return JSONCodec(b)
+	}
+}
+
+// GzipCodec decompress gzip payload up to DefaultMaxDecodedSize bytes and decodes it with next
+func GzipCodec(next PayloadCodec) PayloadCodec {
+	return gzipCodec(next, DefaultMaxDecodedSize)
+}
+
+// SnappyCodec decompress snappy block payload up to DefaultMaxDecodedSize bytes and decodes it with next
+func SnappyCodec(next PayloadCodec) PayloadCodec {
+	return snappyCodec(next, DefaultMaxDecodedSize)
+}
+
+// gzipCodec fails with ErrPayloadTooLarge when decompressed payload exceeds limit
+func gzipCodec(next PayloadCodec, limit int) PayloadCodec {
+	return func(data []byte) (any, error) {
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		b, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		if len(b) > limit {
+			return nil, errors.WithStack(ErrPayloadTooLarge)
+		}
+
+		return next(b)
+	}
+}
+
+// snappyCodec fails with ErrPayloadTooLarge when decompressed payload exceeds limit
+func snappyCodec(next PayloadCodec, limit int) PayloadCodec {
+	return func(data []byte) (any, error) {
+		n, err := snappy.DecodedLen(data)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		if n > limit {
+			return nil, errors.WithStack(ErrPayloadTooLarge)
+		}
+
+		b, err := snappy.Decode(nil, data)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		return next(b)
+	}
+}
+
+// PayloadOption configure PayloadRegistry
+type PayloadOption func(*PayloadRegistry)
+
+// WithSubjectCodec decode payload of subjects matched by pattern with codec, wildcards * and > are supported.
+// Subject codecs are checked in order of registration before content type ones.
+func WithSubjectCodec(pattern string, codec PayloadCodec) PayloadOption {
+	return func(r *PayloadRegistry) {
+		r.subjects = append(r.subjects, subjectCodec{pattern: strings.Split(pattern, "."), codec: codec})
+	}
+}
+
+// WithContentTypeCodec decode payload with Content-Type header media type with codec
+func WithContentTypeCodec(contentType string, codec PayloadCodec) PayloadOption {
+	return func(r *PayloadRegistry) {
+		r.contentTypes[strings.ToLower(contentType)] = codec
+	}
+}
+
+// WithRedactFields replace values of fields with RedactedValue.
+// Field name matches field at any level, dotted path (user.email) matches only full path, names are case-insensitive.
+func WithRedactFields(fields ...string) PayloadOption {
+	return func(r *PayloadRegistry) {
+		for _, f := range fields {
+			r.redact[strings.ToLower(f)] = struct{}{}
+		}
+	}
+}
+
+// WithMaxDumpSize truncate dump to n bytes, zero means no limit
+//
+// Default: 4096
+func WithMaxDumpSize(n int) PayloadOption {
+	return func(r *PayloadRegistry) {
+		r.maxSize = n
+	}
+}
+
+// WithMaxDecodedSize limit payload decompressed by Content-Encoding, larger payloads aren't decoded
+//
+// Default: DefaultMaxDecodedSize
+func WithMaxDecodedSize(n int) PayloadOption {
+	return func(r *PayloadRegistry) {
+		if n > 0 {
+			r.maxDecoded = n
+		}
+	}
+}
+
+// PayloadRegistry selects codec by subject pattern or Content-Type header and dumps decoded payload
+// for logs and span events. Content-Encoding header gzip and snappy are decompressed before decoding.
+//
+// Registered content types: application/json, application/msgpack, application/x-msgpack and
+// application/protobuf, application/x-protobuf with proto or messageType parameter holding full message name
+// registered in protoregistry.GlobalTypes.
+type PayloadRegistry struct {
+	subjects     []subjectCodec
+	contentTypes map[string]PayloadCodec
+	redact       map[string]struct{}
+	maxSize      int
+	maxDecoded   int
+}
+
+type subjectCodec struct {
+	pattern []string
+	codec   PayloadCodec
+}
+
+func NewPayloadRegistry(opts ...PayloadOption) *PayloadRegistry {
+	r := &PayloadRegistry{
+		contentTypes: map[string]PayloadCodec{
+			"application/json":      JSONCodec,
+			"application/msgpack":   MsgpackCodec,
+			"application/x-msgpack": MsgpackCodec,
+		},
+		redact:     map[string]struct{}{},
+		maxSize:    4096,
+		maxDecoded: DefaultMaxDecodedSize,
+	}
+
+	for _, o := range opts {
+		o(r)
+	}
+
+	return r
+}
+
+// Dump returns decoded, redacted and truncated payload of msg.
+// UTF-8 payload without codec or which couldn't be decoded by it is tried as JSON.
+// Undecodable payload is dumped as is, binary one as base64, or masked when redact fields are set.
+func (r *PayloadRegistry) Dump(msg *nats.Msg) string {
+	if len(msg.Data) == 0 {
+		return ""
+	}
+
+	if codec := r.codec(msg); codec != nil {
+		if s, ok := r.dump(codec, msg.Data); ok {
+			return s
+		}
+	}
+
+	valid := utf8.Valid(msg.Data)
+
+	if valid {
+		if s, ok := r.dump(JSONCodec, msg.Data); ok {
+			return s
+		}
+	}
+
+	switch {
+	case len(r.redact) > 0:
+		return fmt.Sprintf("%s (%d bytes)", RedactedValue, len(msg.Data))
+	case valid:
+		return r.truncate(string(msg.Data))
+	default:
+		return r.truncate("base64:" + base64.StdEncoding.EncodeToString(msg.Data))
+	}
+}
+
+// dump payload decoded by codec
+func (r *PayloadRegistry) dump(codec PayloadCodec, data []byte) (string, bool) {
+	v, err := codec(data)
+	if err != nil {
+		return "", false
+	}
+
+	b, err := json.Marshal(r.redactValue("", v))
+	if err != nil {
+		return "", false
+	}
+
+	return r.truncate(string(b)), true
+}
+
+// codec returns codec of msg with decompression applied or nil
+func (r *PayloadRegistry) codec(msg *nats.Msg) PayloadCodec {
+	codec := r.subjectCodec(msg.Subject)
+	if codec == nil {
+		codec = r.contentTypeCodec(msg.Header.Get(HeaderContentType))
+	}
+
+	if codec == nil {
+		return nil
+	}
+
+	switch strings.ToLower(msg.Header.Get(HeaderContentEncoding)) {
+	case "gzip":
+		return gzipCodec(codec, r.maxDecoded)
+	case "snappy":
+		return snappyCodec(codec, r.maxDecoded)
+	default:
+		return codec
+	}
+}
+
+func (r *PayloadRegistry) subjectCodec(subject string) PayloadCodec {
+	if len(r.subjects) == 0 {
+		return nil
+	}
+
+	tokens := strings.Split(subject, ".")
+
+	for _, s := range r.subjects {
+		if matchSubject(s.pattern, tokens) {
+			return s.codec
+		}
+	}
+
+	return nil
+}
+
+func (r *PayloadRegistry) contentTypeCodec(contentType string) PayloadCodec {
+	if contentType == "" {
+		return nil
+	}
+
+	mt, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil
+	}
+
+	if codec, ok := r.contentTypes[mt]; ok {
+		return codec
+	}
+
+	if mt != "application/protobuf" && mt != "application/x-protobuf" {
+		return nil
+	}
+
+	name := params["proto"]
+	if name == "" {
+		name = params["messagetype"]
+	}
+
+	mtype, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil
+	}
+
+	return ProtobufCodec(mtype.Descriptor())
+}
+
+// redactValue replaces values of redacted fields of decoded payload
+func (r *PayloadRegistry) redactValue(path string, v any) any {
+	if len(r.redact) == 0 {
+		return v
+	}
+
+	switch val := v.(type) {
+	case map[string]any:
+		for k, item := range val {
+			p := strings.ToLower(k)
+			if path != "" {
+				p = path + "." + p
+			}
+
+			if r.redacted(k, p) {
+				val[k] = RedactedValue
+				continue
+			}
+
+			val[k] = r.redactValue(p, item)
+		}
+	case []any:
+		for i, item := range val {
+			val[i] = r.redactValue(path, item)
+		}
+	}
+
+	return v
+}
+
+func (r *PayloadRegistry) redacted(key, path string) bool {
+	if _, ok := r.redact[strings.ToLower(key)]; ok {
+		return true
+	}
+
+	_, ok := r.redact[path]
+
+	return ok
+}
+
+func (r *PayloadRegistry) truncate(s string) string {
+	if r.maxSize <= 0 || len(s) <= r.maxSize {
+		return s
+	}
+
+	// don't cut multibyte rune
+	n := r.maxSize
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return s[:n] + "...(truncated)"
+}
+
+// matchSubject reports whether subject tokens match pattern with * and > wildcards
+func matchSubject(pattern, tokens []string) bool {
+	for i, p := range pattern {
+		if p == ">" {
+			return len(tokens) > i
+		}
+
+		if i >= len(tokens) || (p != "*" && p != tokens[i]) {
+			return false
+		}
+	}
+
+	return len(pattern) == len(tokens)
+}
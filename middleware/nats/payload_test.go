@@ -0,0 +1,138 @@
+package nats
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/nats-io/nats.go"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func (s *Suite) TestPayloadRegistry() {
+	r := NewPayloadRegistry(
+		WithSubjectCodec("orders.*.snappy", SnappyCodec(MsgpackCodec)),
+		WithSubjectCodec("orders.>", JSONCodec),
+		WithRedactFields("password", "user.email"),
+		WithMaxDumpSize(80),
+	)
+
+	msg := func(subj string, data []byte, headers ...string) *nats.Msg {
+		m := nats.NewMsg(subj)
+		m.Data = data
+
+		for i := 0; i+1 < len(headers); i += 2 {
+			m.Header.Set(headers[i], headers[i+1])
+		}
+
+		return m
+	}
+
+	s.Run("subject", func() {
+		got := r.Dump(msg("orders.new", []byte(`{"user":{"email":"a@b.c","name":"n"},"password":"p"}`)))
+		s.JSONEq(`{"user":{"email":"[REDACTED]","name":"n"},"password":"[REDACTED]"}`, got)
+
+		// path rule matches only full path
+		got = r.Dump(msg("orders.new", []byte(`{"email":"a@b.c"}`)))
+		s.JSONEq(`{"email":"a@b.c"}`, got)
+	})
+
+	s.Run("snappy msgpack", func() {
+		b, err := msgpack.Marshal(map[string]any{"id": 1, "password": "p"})
+		s.Require().NoError(err)
+
+		got := r.Dump(msg("orders.1.snappy", snappy.Encode(nil, b)))
+		s.JSONEq(`{"id":1,"password":"[REDACTED]"}`, got)
+	})
+
+	s.Run("content type", func() {
+		var buf bytes.Buffer
+
+		w := gzip.NewWriter(&buf)
+		_, _ = w.Write([]byte(`{"password":"p"}`))
+		s.Require().NoError(w.Close())
+
+		got := r.Dump(msg("users", buf.Bytes(),
+			HeaderContentType, "application/json; charset=utf-8", HeaderContentEncoding, "gzip"))
+		s.JSONEq(`{"password":"[REDACTED]"}`, got)
+	})
+
+	s.Run("protobuf", func() {
+		b, err := proto.Marshal(wrapperspb.String("hello"))
+		s.Require().NoError(err)
+
+		got := r.Dump(msg("users", b, HeaderContentType, "application/x-protobuf; proto=google.protobuf.StringValue"))
+		s.Equal(`"hello"`, got)
+
+		got = NewPayloadRegistry(WithSubjectCodec("pb", ProtobufCodec(wrapperspb.String("").ProtoReflect().Descriptor()))).
+			Dump(msg("pb", b))
+		s.Equal(`"hello"`, got)
+	})
+
+	s.Run("fallback", func() {
+		plain := NewPayloadRegistry(WithMaxDumpSize(80))
+
+		s.Equal("plain", plain.Dump(msg("users", []byte("plain"))))
+		s.Equal("base64:/w==", plain.Dump(msg("users", []byte{0xff})))
+
+		long := bytes.Repeat([]byte("a"), 100)
+		s.Equal(string(long[:80])+"...(truncated)", plain.Dump(msg("users", long)))
+	})
+
+	s.Run("fallback with redact", func() {
+		// JSON without Content-Type is redacted as well
+		got := r.Dump(msg("users", []byte(`{"password":"p"}`)))
+		s.JSONEq(`{"password":"[REDACTED]"}`, got)
+
+		// undecodable payload is masked
+		s.Equal("[REDACTED] (5 bytes)", r.Dump(msg("users", []byte("plain"))))
+		s.Equal("[REDACTED] (1 bytes)", r.Dump(msg("users", []byte{0xff})))
+	})
+
+	s.Run("decompression limit", func() {
+		limited := NewPayloadRegistry(WithMaxDecodedSize(64), WithRedactFields("password"))
+		large := []byte(`{"password":"` + strings.Repeat("a", 100) + `"}`)
+
+		var buf bytes.Buffer
+
+		w := gzip.NewWriter(&buf)
+		_, _ = w.Write(large)
+		s.Require().NoError(w.Close())
+
+		s.Equal(fmt.Sprintf("[REDACTED] (%d bytes)", buf.Len()), limited.Dump(msg("users", buf.Bytes(),
+			HeaderContentType, "application/json", HeaderContentEncoding, "gzip")))
+
+		_, err := gzipCodec(JSONCodec, 64)(buf.Bytes())
+		s.ErrorIs(err, ErrPayloadTooLarge)
+
+		_, err = snappyCodec(JSONCodec, 64)(snappy.Encode(nil, large))
+		s.ErrorIs(err, ErrPayloadTooLarge)
+
+		_, err = snappyCodec(JSONCodec, 1024)(snappy.Encode(nil, large))
+		s.NoError(err)
+	})
+}
+
+func (s *Suite) TestLogsPayloadRegistry() {
+	mw := s.newCore(
+		WithPayloadRegistry(NewPayloadRegistry(WithRedactFields("password"))),
+	)
+
+	handler := mw.subHandler(func(ctx context.Context, msg *nats.Msg) error {
+		return fmt.Errorf("broken")
+	})
+
+	msg := nats.NewMsg("payload.demo")
+	msg.Header.Set(HeaderContentType, "application/json")
+	msg.Data = []byte(`{"password":"secret"}`)
+
+	s.Error(handler(s.tel.Ctx(), msg))
+
+	s.Contains(s.buf.String(), RedactedValue)
+	s.NotContains(s.buf.String(), "secret")
+}
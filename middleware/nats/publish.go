@@ -2,7 +2,11 @@ package nats
 
 import (
 	"context"
+
 	"github.com/nats-io/nats.go"
+	"github.com/tel-io/instrumentation/middleware/nats/v2/natsprop"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Publish interface {
@@ -23,6 +27,8 @@ var _ natsPublisher = &nats.Conn{}
 
 type CommonPublish struct {
 	interceptor Interceptor
+	// extract trace context of replies
+	propOpts []natsprop.Option
 
 	Conn natsPublisher
 }
@@ -68,18 +74,17 @@ func (c *CommonPublish) RequestMsgWithContext(ccx context.Context, m *nats.Msg)
 		if err != nil {
 			infRes = &nats.Msg{Subject: msg.Subject, Header: msg.Header}
 		} else {
+			// copy: response span is injected into headers of infRes, reply of caller keeps trace of replier
 			infRes = &nats.Msg{
 				Subject: msg.Subject,
-				Header:  res.Header,
+				Header:  cloneHeader(res.Header),
 				Data:    res.Data,
 			}
 
-			if infRes.Header == nil {
-				infRes.Header = nats.Header{}
-			}
-
 			// just pass reply info for some usage
 			infRes.Header.Set(KindReply, res.Subject)
+
+			linkReply(ctx, res, c.propOpts...)
 		}
 
 		// ccx - none-wrapped context
@@ -104,3 +109,20 @@ func (c *CommonPublish) infiltrateResponse(ctx context.Context, msg *nats.Msg, e
 		return err
 	})(ctx, msg)
 }
+
+// linkReply links request span with replier span when reply carries trace information
+func linkReply(ctx context.Context, res *nats.Msg, opts ...natsprop.Option) {
+	if res.Header == nil {
+		return
+	}
+
+	_, _, sc := natsprop.Extract(context.Background(), res, opts...)
+	if !sc.IsValid() {
+		return
+	}
+
+	trace.SpanFromContext(ctx).AddLink(trace.Link{
+		SpanContext: sc,
+		Attributes:  []attribute.KeyValue{Kind.String(KindReply)},
+	})
+}
@@ -0,0 +1,182 @@
+package nats
+
+import (
+	"context"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attribute keys of fetch span
+const (
+	FetchBatch    = attribute.Key("fetch.batch")
+	FetchReceived = attribute.Key("fetch.received")
+)
+
+// PullSubscription JetStream pull subscription which process every fetched message
+// via subscriber middleware chain: recovery, logs, tracer, metrics and etc.
+//
+// Each fetch creates own span, consumer span of every message is linked with it.
+type PullSubscription struct {
+	*nats.Subscription
+
+	core    *Core
+	handler MsgHandler
+}
+
+// Fetch pulls a batch of messages from a stream for a pull consumer and process them with handler.
+// Returns fetch error, handler errors are handled by middleware.
+//
+// ctx should contain tel context, deadline of ctx is used as fetch deadline when it's set
+// and opts have neither nats.MaxWait nor nats.Context.
+func (p *PullSubscription) Fetch(ctx context.Context, batch int, opts ...nats.PullOpt) error {
+	f := p.startFetch(ctx, batch)
+
+	start := time.Now()
+	msgs, err := p.Subscription.Fetch(batch, pullOpts(ctx, opts)...)
+	f.wait = time.Since(start)
+
+	for _, msg := range msgs {
+		_ = p.handler(f.msgCtx(p.core), msg)
+	}
+
+	f.end(p.core.metrics, len(msgs), err)
+
+	return err
+}
+
+// FetchBatch pulls a batch of messages from a stream for a pull consumer and process them with handler
+// as soon as they are received, unlike Fetch which handles messages after whole batch is received.
+// Returns fetch error, handler errors are handled by middleware.
+//
+// ctx should contain tel context, deadline of ctx is used as fetch deadline when it's set
+// and opts have neither nats.MaxWait nor nats.Context.
+func (p *PullSubscription) FetchBatch(ctx context.Context, batch int, opts ...nats.PullOpt) error {
+	f := p.startFetch(ctx, batch)
+
+	start := time.Now()
+	res, err := p.Subscription.FetchBatch(batch, pullOpts(ctx, opts)...)
+	if err != nil {
+		f.wait = time.Since(start)
+		f.end(p.core.metrics, 0, err)
+
+		return err
+	}
+
+	received := 0
+	for msg := range res.Messages() {
+		// count only time spent for waiting messages
+		f.wait += time.Since(start)
+		received++
+
+		_ = p.handler(f.msgCtx(p.core), msg)
+
+		start = time.Now()
+	}
+
+	f.wait += time.Since(start)
+	err = res.Error()
+	f.end(p.core.metrics, received, err)
+
+	return err
+}
+
+func (p *PullSubscription) startFetch(ctx context.Context, batch int) *fetch {
+	return startFetch(ctx, p.core, &nats.Msg{Subject: p.Subject, Sub: p.Subscription}, batch)
+}
+
+// startFetch creates fetch span, msg is used only for naming
+func startFetch(ctx context.Context, c *Core, msg *nats.Msg, batch int) *fetch {
+	opr := c.nameFn(KindFetch, msg)
+
+	span, _ := startSpan(ctx, c.tracer, opr,
+		trace.WithSpanKind(convSpanToKind(KindFetch)),
+		trace.WithAttributes(
+			Subject.String(msg.Subject),
+			Kind.String(KindFetch),
+			FetchBatch.Int(batch),
+		),
+	)
+
+	return &fetch{
+		ctx:     ctx,
+		span:    span,
+		subject: msg.Subject,
+	}
+}
+
+// fetch state of single fetch call
+type fetch struct {
+	ctx     context.Context
+	span    trace.Span
+	subject string
+	wait    time.Duration
+}
+
+// msgCtx returns fresh context for message handler linked to fetch span
+func (f *fetch) msgCtx(c *Core) context.Context {
+	return WithSpanLinks(c.config.tele.Ctx(), trace.Link{
+		SpanContext: f.span.SpanContext(),
+		Attributes:  []attribute.KeyValue{Kind.String(KindFetch)},
+	})
+}
+
+func (f *fetch) end(m *metrics, received int, err error) {
+	defer f.span.End()
+
+	empty := received == 0 && isFetchTimeout(err)
+	if empty {
+		err = nil
+	}
+
+	f.span.SetAttributes(FetchReceived.Int(received))
+
+	if err != nil {
+		f.span.SetStatus(codes.Error, err.Error())
+		f.span.RecordError(err)
+	} else {
+		f.span.SetStatus(codes.Ok, "")
+	}
+
+	attrs := metric.WithAttributes(
+		IsError.Bool(err != nil),
+		Subject.String(decreaseSubjectCardinality(f.subject)),
+	)
+
+	ctx := trace.ContextWithSpan(f.ctx, f.span)
+
+	m.valueRecorders[FetchLatency].Record(ctx, float64(f.wait.Milliseconds()), attrs)
+	m.sizeRecorders[FetchBatchSize].Record(ctx, int64(received), attrs)
+
+	if empty {
+		m.counters[FetchEmpty].Add(ctx, 1, attrs)
+	}
+
+	m.recordMessaging(ctx, KindFetch, &nats.Msg{Subject: f.subject}, f.wait, int64(received), err)
+}
+
+// pullOpts use ctx deadline as fetch deadline, nats don't allow ctx without deadline.
+// nats.MaxWait or nats.Context of caller take precedence: nats rejects both ctx and timeout.
+func pullOpts(ctx context.Context, opts []nats.PullOpt) []nats.PullOpt {
+	if _, ok := ctx.Deadline(); !ok {
+		return opts
+	}
+
+	for _, o := range opts {
+		switch o.(type) {
+		case nats.MaxWait, nats.ContextOpt:
+			return opts
+		}
+	}
+
+	return append(opts[:len(opts):len(opts)], nats.Context(ctx))
+}
+
+func isFetchTimeout(err error) bool {
+	return errors.Is(err, nats.ErrTimeout) || errors.Is(err, context.DeadlineExceeded)
+}
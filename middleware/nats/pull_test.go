@@ -0,0 +1,103 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+func (s *Suite) TestPullSubscription() {
+	conn := s.runServer()
+
+	mw := s.newCore().Use(conn)
+
+	js, err := mw.JetStream()
+	s.Require().NoError(err)
+
+	_, err = js.JS().AddStream(&nats.StreamConfig{Name: "PULL", Subjects: []string{"pull.>"}})
+	s.Require().NoError(err)
+
+	var got []string
+
+	sub, err := js.PullSubscribeWithHandler("pull.demo", "worker", func(ctx context.Context, msg *nats.Msg) error {
+		got = append(got, string(msg.Data))
+
+		if string(msg.Data) == "2" {
+			return fmt.Errorf("some error")
+		}
+
+		return msg.Ack()
+	})
+	s.Require().NoError(err)
+
+	for i := 0; i < 3; i++ {
+		_, err = js.JS().Publish("pull.demo", []byte(fmt.Sprint(i)))
+		s.Require().NoError(err)
+	}
+
+	ctx := s.tel.Ctx()
+
+	s.NoError(sub.Fetch(ctx, 2, nats.MaxWait(time.Second)))
+	s.Equal([]string{"0", "1"}, got)
+
+	s.NoError(sub.FetchBatch(ctx, 2, nats.MaxWait(100*time.Millisecond)))
+	s.Equal([]string{"0", "1", "2"}, got)
+
+	// handler error is logged by middleware
+	s.Contains(s.buf.String(), "some error")
+	s.Contains(s.buf.String(), "pull.demo")
+
+	// nothing left
+	err = sub.Fetch(ctx, 1, nats.MaxWait(100*time.Millisecond))
+	s.ErrorIs(err, nats.ErrTimeout)
+}
+
+func (s *Suite) TestPullSubscription_DeadlineAndMaxWait() {
+	conn := s.runServer()
+
+	mw := s.newCore().Use(conn)
+
+	js, err := mw.JetStream()
+	s.Require().NoError(err)
+
+	_, err = js.JS().AddStream(&nats.StreamConfig{Name: "PULLWAIT", Subjects: []string{"pullwait.>"}})
+	s.Require().NoError(err)
+
+	var got []string
+
+	sub, err := js.PullSubscribeWithHandler("pullwait.demo", "worker", func(ctx context.Context, msg *nats.Msg) error {
+		got = append(got, string(msg.Data))
+		return msg.Ack()
+	})
+	s.Require().NoError(err)
+
+	for i := 0; i < 3; i++ {
+		_, err = js.JS().Publish("pullwait.demo", []byte(fmt.Sprint(i)))
+		s.Require().NoError(err)
+	}
+
+	ctx, cancel := context.WithTimeout(s.tel.Ctx(), 5*time.Second)
+	defer cancel()
+
+	// nats rejects both ctx and timeout: MaxWait of caller wins
+	s.NoError(sub.Fetch(ctx, 1, nats.MaxWait(time.Second)))
+	s.NoError(sub.FetchBatch(ctx, 1, nats.MaxWait(time.Second)))
+	s.NoError(sub.FetchBatchHandler(ctx, 1, func(ctx context.Context, msgs []*nats.Msg) error {
+		for _, msg := range msgs {
+			got = append(got, string(msg.Data))
+			s.NoError(msg.Ack())
+		}
+
+		return nil
+	}, nats.MaxWait(time.Second)))
+
+	s.Equal([]string{"0", "1", "2"}, got)
+
+	// deadline of ctx is used without MaxWait
+	short, cancelShort := context.WithTimeout(s.tel.Ctx(), 100*time.Millisecond)
+	defer cancelShort()
+
+	s.ErrorIs(sub.Fetch(short, 1), context.DeadlineExceeded)
+}
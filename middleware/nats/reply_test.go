@@ -0,0 +1,93 @@
+package nats
+
+import (
+	"context"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/tel-io/instrumentation/middleware/nats/v2/natsprop"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func (s *Suite) TestRespond() {
+	conn := s.runServer()
+
+	spans := tracetest.NewSpanRecorder()
+	mw := s.newCore(
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))),
+		WithPropagation(natsprop.WithPropagators(propagation.TraceContext{})),
+	).Use(conn)
+
+	_, err := mw.Subscribe("reply.demo", func(ctx context.Context, msg *nats.Msg) error {
+		return mw.Respond(ctx, msg, append([]byte("hello "), msg.Data...))
+	})
+	s.Require().NoError(err)
+
+	res, err := mw.RequestWithContext(s.tel.Ctx(), "reply.demo", []byte("world"))
+	s.Require().NoError(err)
+	s.Equal("hello world", string(res.Data))
+
+	// wait handlers completion
+	s.Require().NoError(conn.Drain())
+	s.Eventually(conn.IsClosed, time.Second, 10*time.Millisecond)
+
+	s.Contains(s.buf.String(), "NATS:REPLY/:inbox:")
+
+	var request, reply sdktrace.ReadOnlySpan
+
+	for _, span := range spans.Ended() {
+		switch span.Name() {
+		case "NATS:REQUEST/reply.demo":
+			request = span
+		case "NATS:REPLY/:inbox:":
+			reply = span
+		}
+	}
+
+	s.Require().NotNil(request)
+	s.Require().NotNil(reply)
+
+	// reply carries trace context of replier span, requester span is linked to it
+	sc := trace.SpanContextFromContext(
+		propagation.TraceContext{}.Extract(context.Background(), propagation.HeaderCarrier(res.Header)))
+	s.Equal(reply.SpanContext().SpanID(), sc.SpanID())
+
+	s.Require().Len(request.Links(), 1)
+	s.Equal(reply.SpanContext().SpanID(), request.Links()[0].SpanContext.SpanID())
+
+	s.ErrorIs(mw.Respond(s.tel.Ctx(), &nats.Msg{Subject: "no.reply"}, nil), nats.ErrMsgNoReply)
+}
+
+func (s *Suite) TestReplyFnInjectIntoResponse() {
+	prev := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	defer otel.SetTextMapPropagator(prev)
+
+	conn := s.runServer()
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+	})
+
+	_, err := conn.Subscribe("reply.legacy", func(msg *nats.Msg) {
+		_ = ReplyFn(trace.ContextWithSpanContext(context.Background(), sc), msg, []byte("pong"))
+	})
+	s.Require().NoError(err)
+
+	msg := nats.NewMsg("reply.legacy")
+
+	res, err := conn.RequestMsg(msg, time.Second)
+	s.Require().NoError(err)
+	s.Equal("pong", string(res.Data))
+
+	// request is untouched, reply carries trace
+	s.Empty(propagation.HeaderCarrier(msg.Header).Get("traceparent"))
+	s.Contains(propagation.HeaderCarrier(res.Header).Get("traceparent"), sc.TraceID().String())
+}
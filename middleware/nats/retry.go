@@ -0,0 +1,315 @@
+package nats
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+	"github.com/tel-io/instrumentation/middleware/nats/v2/natsprop"
+	"github.com/tel-io/tel/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Headers of retried and dead-lettered messages
+const (
+	HeaderAttempt    = "Nats-Retry-Attempt"        // number of attempts of dead-lettered message, continued by Retry
+	HeaderDLQError   = "Nats-Dlq-Error"            // error of the last attempt
+	HeaderDLQSubject = "Nats-Dlq-Original-Subject" // subject message was received from
+	HeaderDLQTime    = "Nats-Dlq-Time"             // time when message was dead-lettered, RFC3339
+)
+
+// Attribute keys of retry span events
+const (
+	RetryAttempt = attribute.Key("retry.attempt")
+	RetryDelay   = attribute.Key("retry.delay")
+	DLQSubject   = attribute.Key("dlq.subject")
+)
+
+// DeadLetterPublisher publishes dead-lettered messages, *nats.Conn satisfies it
+type DeadLetterPublisher interface {
+	PublishMsg(msg *nats.Msg) error
+}
+
+// DeadLetterSubjectFn returns dead-letter subject for original subject
+type DeadLetterSubjectFn func(subject string) string
+
+// DeadLetterPrefix puts dead-lettered messages to prefix + original subject
+func DeadLetterPrefix(prefix string) DeadLetterSubjectFn {
+	return func(subject string) string {
+		return prefix + subject
+	}
+}
+
+// RetryOption configure Retry middleware
+type RetryOption func(*Retry)
+
+// WithMaxAttempts set number of handler calls including the first one
+//
+// Default: 3
+func WithMaxAttempts(n int) RetryOption {
+	return func(r *Retry) {
+		if n > 0 {
+			r.maxAttempts = n
+		}
+	}
+}
+
+// WithRetryBackoff set delay before attempt, fn receives number of failed attempts
+//
+// Default: ExponentialBackoff(100*time.Millisecond, 5*time.Second)
+func WithRetryBackoff(fn BackoffFn) RetryOption {
+	return func(r *Retry) {
+		r.backoff = fn
+	}
+}
+
+// WithRetryTermPolicy set policy which errors are not retried, such messages go to dead-letter immediately
+//
+// Default: errors.Is(err, ErrPermanent)
+func WithRetryTermPolicy(fn TermFn) RetryOption {
+	return func(r *Retry) {
+		r.term = fn
+	}
+}
+
+// WithRetryRedelivery retry JetStream messages by redelivery instead of in-process sleep, which blocks delivery
+// of subscription: handler error is returned to JetStreamAck, which naks message with WithNakBackoff delay.
+// Attempt is delivery count of message, messages of NATS Core are retried in-process.
+//
+// Default: false
+func WithRetryRedelivery(enable bool) RetryOption {
+	return func(r *Retry) {
+		r.redelivery = enable
+	}
+}
+
+// WithDeadLetter republish messages failed after the final attempt to subject returned by fn
+// Without dead-letter error of the final attempt is returned as is.
+func WithDeadLetter(pub DeadLetterPublisher, fn DeadLetterSubjectFn) RetryOption {
+	return func(r *Retry) {
+		r.dlq = pub
+		r.dlqSubject = fn
+	}
+}
+
+// Retry implementing Middleware: calls handler again with backoff while it returns error,
+// current attempt is available via RetryAttemptFromContext and every retry is recorded as span event.
+// Attempt count is continued from HeaderAttempt of incoming message, so republished messages keep their count.
+//
+// Backoff sleeps within subscription callback, so it blocks delivery of subscription till the next attempt.
+// Sleep is interrupted when subscription is closed: Unsubscribe, Drain or connection close.
+// Use WithRetryRedelivery for JetStream subscriptions.
+//
+// After the final attempt message is republished to dead-letter subject with error, original subject and trace context
+// in headers and Permanent error is returned, so JetStreamAck terminates it instead of redelivery.
+// Retry of Core publishes dead-lettered message through publisher middleware chain with WithPropagation options.
+// When dead-letter publish fails original error is returned.
+type Retry struct {
+	*metrics
+
+	maxAttempts int
+	backoff     BackoffFn
+	term        TermFn
+	redelivery  bool
+
+	dlq        DeadLetterPublisher
+	dlqSubject DeadLetterSubjectFn
+
+	// set by Core: dead-lettered message is published through publisher chain with propagation options
+	pubInter Interceptor
+	propOpts []natsprop.Option
+}
+
+func NewRetry(m *metrics, opts ...RetryOption) *Retry {
+	r := &Retry{
+		metrics:     m,
+		maxAttempts: 3,
+		backoff:     ExponentialBackoff(100*time.Millisecond, 5*time.Second),
+		term: func(err error) bool {
+			return errors.Is(err, ErrPermanent)
+		},
+	}
+
+	for _, o := range opts {
+		o(r)
+	}
+
+	return r
+}
+
+func (r *Retry) apply(next MsgHandler) MsgHandler {
+	return func(ctx context.Context, msg *nats.Msg) error {
+		// outcome of batch is per message, batch isn't retried as a whole
+		if extractBaggageKind(ctx) == KindBatch {
+			return next(ctx, msg)
+		}
+
+		attempt := 1
+		if v, err := strconv.Atoi(msg.Header.Get(HeaderAttempt)); err == nil && v > 0 {
+			attempt = v
+		}
+
+		attrs := metric.WithAttributes(Subject.String(decreaseSubjectCardinality(msg.Subject)))
+
+		if r.redelivery {
+			if meta, err := msg.Metadata(); err == nil {
+				return r.redeliver(ctx, msg, int(meta.NumDelivered), next, attrs)
+			}
+		}
+
+		for {
+			err := next(withRetryAttempt(ctx, attempt), msg)
+			if err == nil {
+				return nil
+			}
+
+			if attempt >= r.maxAttempts || r.term(err) {
+				return r.deadLetter(ctx, msg, attempt, err, attrs)
+			}
+
+			delay := r.backoff(uint64(attempt))
+			r.retry(ctx, msg, attempt, delay, err, attrs)
+
+			if !sleep(ctx, msg.Sub, delay) {
+				return err
+			}
+
+			attempt++
+		}
+	}
+}
+
+// redeliver calls handler once, failed message is retried by redelivery of JetStream
+func (r *Retry) redeliver(ctx context.Context, msg *nats.Msg, attempt int, next MsgHandler,
+	attrs metric.MeasurementOption) error {
+	err := next(withRetryAttempt(ctx, attempt), msg)
+	if err == nil {
+		return nil
+	}
+
+	if attempt >= r.maxAttempts || r.term(err) {
+		return r.deadLetter(ctx, msg, attempt, err, attrs)
+	}
+
+	r.retry(ctx, msg, attempt, 0, err, attrs)
+
+	return err
+}
+
+// retry record failed attempt, delay is 0 for redelivery
+func (r *Retry) retry(ctx context.Context, msg *nats.Msg, attempt int, delay time.Duration, err error,
+	attrs metric.MeasurementOption) {
+	trace.SpanFromContext(ctx).AddEvent("retry", trace.WithAttributes(
+		RetryAttempt.Int(attempt),
+		RetryDelay.String(delay.String()),
+		IsError.Bool(true),
+		attribute.String("error.message", err.Error()),
+	))
+
+	r.counters[RetryCount].Add(ctx, 1, attrs)
+
+	tel.FromCtx(ctx).Warn("nats retry",
+		tel.String(string(Subject), msg.Subject),
+		tel.Int(string(RetryAttempt), attempt),
+		tel.Error(err),
+	)
+}
+
+// deadLetter republish msg to dead-letter subject
+func (r *Retry) deadLetter(ctx context.Context, msg *nats.Msg, attempt int, err error,
+	attrs metric.MeasurementOption) error {
+	if r.dlq == nil {
+		return err
+	}
+
+	subj := r.dlqSubject(msg.Subject)
+
+	dlq := &nats.Msg{Subject: subj, Data: msg.Data, Header: cloneHeader(msg.Header)}
+
+	dlq.Header.Set(HeaderAttempt, strconv.Itoa(attempt))
+	dlq.Header.Set(HeaderDLQError, err.Error())
+	dlq.Header.Set(HeaderDLQSubject, msg.Subject)
+	dlq.Header.Set(HeaderDLQTime, time.Now().UTC().Format(time.RFC3339))
+
+	// trace context and baggage of original message are replaced by the current ones
+	natsprop.Clean(dlq, r.propOpts...)
+	natsprop.Inject(ctx, dlq, r.propOpts...)
+
+	publish := func(_ context.Context, msg *nats.Msg) error {
+		return r.dlq.PublishMsg(msg)
+	}
+
+	if r.pubInter != nil {
+		publish = r.pubInter(publish)
+	}
+
+	pubErr := publish(WrapKindOfContext(ctx, KindPub), dlq)
+
+	trace.SpanFromContext(ctx).AddEvent("dead_letter", trace.WithAttributes(
+		DLQSubject.String(subj),
+		RetryAttempt.Int(attempt),
+		IsError.Bool(pubErr != nil),
+	))
+
+	r.counters[DLQCount].Add(ctx, 1, attrs, metric.WithAttributes(IsError.Bool(pubErr != nil)))
+
+	if pubErr != nil {
+		tel.FromCtx(ctx).Error("nats dead letter",
+			tel.String(string(Subject), msg.Subject),
+			tel.String(string(DLQSubject), subj),
+			tel.Error(pubErr),
+		)
+
+		return err
+	}
+
+	return Permanent(err)
+}
+
+type retryAttemptKey struct{}
+
+func withRetryAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, retryAttemptKey{}, attempt)
+}
+
+// RetryAttemptFromContext returns number of current processing attempt of Retry middleware, starting from 1
+func RetryAttemptFromContext(ctx context.Context) (int, bool) {
+	attempt, ok := ctx.Value(retryAttemptKey{}).(int)
+	return attempt, ok
+}
+
+// retryPollInterval of subscription state while sleeping
+const retryPollInterval = 50 * time.Millisecond
+
+// sleep returns false when ctx is done or sub is closed before d elapsed.
+// Subscription state is polled: connection close doesn't change status of subscriptions.
+func sleep(ctx context.Context, sub *nats.Subscription, d time.Duration) bool {
+	closed := func() bool { return sub != nil && !sub.IsValid() }
+
+	if d <= 0 {
+		return ctx.Err() == nil && !closed()
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	poll := time.NewTicker(retryPollInterval)
+	defer poll.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			return true
+		case <-ctx.Done():
+			return false
+		case <-poll.C:
+			if closed() {
+				return false
+			}
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/tel-io/instrumentation/middleware/nats/v2/natsprop"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func (s *Suite) TestRetry() {
+	conn := s.runServer()
+
+	dlq, err := conn.SubscribeSync("dlq.retry.>")
+	s.Require().NoError(err)
+
+	mw := s.newCore(WithRetry(
+		WithMaxAttempts(3),
+		WithRetryBackoff(func(uint64) time.Duration { return time.Millisecond }),
+		WithDeadLetter(conn, DeadLetterPrefix("dlq.")),
+	)).Use(conn)
+
+	var calls atomic.Int32
+
+	_, err = mw.Subscribe("retry.>", func(ctx context.Context, msg *nats.Msg) error {
+		calls.Add(1)
+
+		switch string(msg.Data) {
+		case "flaky":
+			if attempt, _ := RetryAttemptFromContext(ctx); attempt == 1 {
+				return fmt.Errorf("temporary")
+			}
+
+			return nil
+		case "permanent":
+			return Permanent(fmt.Errorf("bad payload"))
+		}
+
+		return fmt.Errorf("broken")
+	})
+	s.Require().NoError(err)
+
+	for _, v := range []string{"flaky", "broken", "permanent"} {
+		s.Require().NoError(mw.PublishWithContext(s.tel.Ctx(), "retry.demo", []byte(v)))
+	}
+
+	got := map[string]*nats.Msg{}
+
+	for range 2 {
+		msg, err := dlq.NextMsg(time.Second)
+		s.Require().NoError(err)
+
+		got[string(msg.Data)] = msg
+	}
+
+	// flaky: 2, broken: 3, permanent: 1
+	s.Eventually(func() bool { return calls.Load() == 6 }, time.Second, 10*time.Millisecond)
+
+	s.Require().Contains(got, "broken")
+	s.Equal("dlq.retry.demo", got["broken"].Subject)
+	s.Equal("3", got["broken"].Header.Get(HeaderAttempt))
+	s.Equal("broken", got["broken"].Header.Get(HeaderDLQError))
+	s.Equal("retry.demo", got["broken"].Header.Get(HeaderDLQSubject))
+
+	s.Require().Contains(got, "permanent")
+	s.Equal("1", got["permanent"].Header.Get(HeaderAttempt))
+
+	s.Require().NoError(conn.Drain())
+	s.Eventually(conn.IsClosed, time.Second, 10*time.Millisecond)
+
+	s.Contains(s.buf.String(), "nats retry")
+}
+
+func (s *Suite) TestRetryDeadLetterPropagation() {
+	conn := s.runServer()
+
+	dlq, err := conn.SubscribeSync("dlq.prop.>")
+	s.Require().NoError(err)
+
+	mw := s.newCore(
+		WithPropagation(
+			natsprop.WithPropagators(propagation.NewCompositeTextMapPropagator(
+				propagation.TraceContext{}, propagation.Baggage{},
+			)),
+			natsprop.WithBaggageAllowList("tenant"),
+		),
+		WithRetry(WithMaxAttempts(1), WithDeadLetter(conn, DeadLetterPrefix("dlq."))),
+	).Use(conn)
+
+	_, err = mw.Subscribe("prop.demo", func(ctx context.Context, msg *nats.Msg) error {
+		return fmt.Errorf("broken")
+	})
+	s.Require().NoError(err)
+
+	msg := nats.NewMsg("prop.demo")
+	msg.Header.Set("Baggage", "tenant=acme,secret=pass")
+	s.Require().NoError(conn.PublishMsg(msg))
+
+	got, err := dlq.NextMsg(time.Second)
+	s.Require().NoError(err)
+
+	s.Equal("tenant=acme", got.Header.Get("Baggage"), "baggage is filtered by allow-list")
+
+	// published through publisher chain
+	s.Eventually(func() bool {
+		return strings.Contains(s.buf.String(), "NATS:PUB/dlq.prop.demo")
+	}, time.Second, 10*time.Millisecond)
+}
+
+func (s *Suite) TestRetryContinueAttempt() {
+	var calls int
+
+	r := NewRetry(s.newCore().metrics, WithMaxAttempts(3), WithRetryBackoff(func(uint64) time.Duration { return 0 }))
+
+	handler := r.apply(func(ctx context.Context, msg *nats.Msg) error {
+		calls++
+		return fmt.Errorf("broken")
+	})
+
+	msg := nats.NewMsg("retry.continue")
+	msg.Header.Set(HeaderAttempt, "2")
+
+	s.EqualError(handler(s.tel.Ctx(), msg), "broken")
+	s.Equal(2, calls)
+	// delivered message isn't modified
+	s.Equal("2", msg.Header.Get(HeaderAttempt))
+}
+
+func (s *Suite) TestRetryInterruptedByUnsubscribe() {
+	conn := s.runServer()
+
+	var (
+		failed = make(chan struct{}, 1)
+		done   = make(chan struct{})
+	)
+
+	mw := s.newCore(
+		WithRetry(WithRetryBackoff(func(uint64) time.Duration { return time.Hour })),
+		// the outermost: completes after retry
+		WithSubMiddleware(middlewareFunc(func(next MsgHandler) MsgHandler {
+			return func(ctx context.Context, msg *nats.Msg) error {
+				defer close(done)
+				return next(ctx, msg)
+			}
+		})),
+	).Use(conn)
+
+	sub, err := mw.Subscribe("retry.interrupt", func(ctx context.Context, msg *nats.Msg) error {
+		failed <- struct{}{}
+		return fmt.Errorf("broken")
+	})
+	s.Require().NoError(err)
+
+	s.Require().NoError(mw.PublishWithContext(s.tel.Ctx(), "retry.interrupt", []byte("1")))
+
+	select {
+	case <-failed:
+	case <-time.After(5 * time.Second):
+		s.FailNow("not handled")
+	}
+
+	s.Require().NoError(sub.Unsubscribe())
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		s.FailNow("backoff is not interrupted")
+	}
+}
+
+func (s *Suite) TestRetryRedelivery() {
+	conn := s.runServer()
+
+	dlq, err := conn.SubscribeSync("dlq.redelivery.>")
+	s.Require().NoError(err)
+
+	mw := s.newCore(
+		WithJetStreamAck(WithNakBackoff(func(uint64) time.Duration { return 0 })),
+		WithRetry(
+			WithMaxAttempts(3),
+			WithRetryRedelivery(true),
+			WithRetryBackoff(func(uint64) time.Duration { return time.Hour }),
+			WithDeadLetter(conn, DeadLetterPrefix("dlq.")),
+		),
+	).Use(conn)
+
+	js, err := mw.JetStream()
+	s.Require().NoError(err)
+
+	_, err = js.JS().AddStream(&nats.StreamConfig{Name: "REDELIVERY", Subjects: []string{"redelivery.>"}})
+	s.Require().NoError(err)
+
+	var attempts []int
+
+	_, err = js.Subscribe("redelivery.demo", func(ctx context.Context, msg *nats.Msg) error {
+		attempt, _ := RetryAttemptFromContext(ctx)
+		attempts = append(attempts, attempt)
+
+		return fmt.Errorf("broken")
+	}, nats.ManualAck())
+	s.Require().NoError(err)
+
+	_, err = js.JS().Publish("redelivery.demo", []byte("1"))
+	s.Require().NoError(err)
+
+	// attempts are redeliveries, not in-process sleeps of an hour
+	msg, err := dlq.NextMsg(5 * time.Second)
+	s.Require().NoError(err)
+
+	s.Equal("3", msg.Header.Get(HeaderAttempt))
+	s.Equal([]int{1, 2, 3}, attempts)
+}
+
+type middlewareFunc func(next MsgHandler) MsgHandler
+
+func (f middlewareFunc) apply(next MsgHandler) MsgHandler {
+	return f(next)
+}
@@ -0,0 +1,156 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// SemConv naming scheme of span attributes and metrics
+type SemConv int
+
+const (
+	// SemConvLegacy custom attributes and metrics: subject, kind_of, nats.count, nats.duration etc
+	SemConvLegacy SemConv = iota
+	// SemConvMessaging OpenTelemetry messaging semantic conventions: messaging.* attributes and metrics
+	SemConvMessaging
+	// SemConvBoth migration mode: legacy and messaging metrics are emitted at once
+	SemConvBoth
+)
+
+func (s SemConv) legacy() bool { return s != SemConvMessaging }
+
+func (s SemConv) messaging() bool { return s != SemConvLegacy }
+
+// MessagingSystemNATS value of messaging.system attribute
+const MessagingSystemNATS = "nats"
+
+// MessagingConsumerGroupName consumer group attribute key, NATS queue group is used as value
+const MessagingConsumerGroupName = attribute.Key("messaging.consumer.group.name")
+
+// Messaging semantic conventions metrics
+const (
+	MessagingPublishDuration = semconv.MessagingPublishDurationName // Publish duration, seconds
+	MessagingReceiveDuration = semconv.MessagingReceiveDurationName // Fetch duration, seconds
+	MessagingProcessDuration = semconv.MessagingProcessDurationName // Handler duration, seconds
+	MessagingPublishMessages = semconv.MessagingPublishMessagesName // Published messages
+	MessagingReceiveMessages = semconv.MessagingReceiveMessagesName // Fetched messages
+	MessagingProcessMessages = semconv.MessagingProcessMessagesName // Processed messages
+)
+
+// messaging operation types
+const (
+	opPublish = "publish"
+	opReceive = "receive"
+	opProcess = "process"
+)
+
+var (
+	messagingDurations = map[string]string{
+		opPublish: MessagingPublishDuration,
+		opReceive: MessagingReceiveDuration,
+		opProcess: MessagingProcessDuration,
+	}
+
+	messagingMessages = map[string]string{
+		opPublish: MessagingPublishMessages,
+		opReceive: MessagingReceiveMessages,
+		opProcess: MessagingProcessMessages,
+	}
+)
+
+// messagingOperation returns operation type and name of kind
+func messagingOperation(kind string) (string, string) {
+	switch kind {
+	case KindPub:
+		return opPublish, opPublish
+	case KindRequest, KindReply:
+		return opPublish, strings.ToLower(kind)
+	case KindFetch, KindRespond:
+		return opReceive, strings.ToLower(kind)
+	case KindSub:
+		return opProcess, opProcess
+	default:
+		return opProcess, strings.ToLower(kind)
+	}
+}
+
+// MessagingNameFn span name convention of messaging semantic conventions: {operation} {destination}
+func MessagingNameFn(kind string, msg *nats.Msg) string {
+	_, name := messagingOperation(kind)
+
+	return name + " " + destinationTemplate(msg)
+}
+
+// MessagingAttributes returns messaging semantic conventions attributes of the message processed as kind
+func MessagingAttributes(msg *nats.Msg, kind string) []attribute.KeyValue {
+	typ, name := messagingOperation(kind)
+
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemKey.String(MessagingSystemNATS),
+		semconv.MessagingDestinationName(msg.Subject),
+		semconv.MessagingDestinationTemplate(destinationTemplate(msg)),
+		semconv.MessagingOperationTypeKey.String(typ),
+		semconv.MessagingOperationName(name),
+		semconv.MessagingMessageBodySize(len(msg.Data)),
+	}
+
+	if id := msg.Header.Get(nats.MsgIdHdr); id != "" {
+		attrs = append(attrs, semconv.MessagingMessageID(id))
+	}
+
+	if msg.Sub != nil && msg.Sub.Queue != "" {
+		attrs = append(attrs, MessagingConsumerGroupName.String(msg.Sub.Queue))
+	}
+
+	if strings.HasPrefix(msg.Subject, nats.InboxPrefix) {
+		attrs = append(attrs, semconv.MessagingDestinationTemporary(true))
+	}
+
+	return attrs
+}
+
+// destinationTemplate subscription subject or subject with decreased cardinality
+func destinationTemplate(msg *nats.Msg) string {
+	if msg.Sub != nil && msg.Sub.Subject != "" {
+		return msg.Sub.Subject
+	}
+
+	return decreaseSubjectCardinality(msg.Subject)
+}
+
+// recordMessaging records duration and messages count of messaging semantic conventions
+// only low cardinality attributes are used: destination template is put as destination name
+func (m *metrics) recordMessaging(ctx context.Context, kind string, msg *nats.Msg, d time.Duration, n int64, err error) {
+	if !m.semConv.messaging() {
+		return
+	}
+
+	typ, name := messagingOperation(kind)
+
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemKey.String(MessagingSystemNATS),
+		semconv.MessagingDestinationName(destinationTemplate(msg)),
+		semconv.MessagingOperationName(name),
+	}
+
+	if msg.Sub != nil && msg.Sub.Queue != "" {
+		attrs = append(attrs, MessagingConsumerGroupName.String(msg.Sub.Queue))
+	}
+
+	if err != nil {
+		attrs = append(attrs, semconv.ErrorTypeKey.String(fmt.Sprintf("%T", errors.Cause(err))))
+	}
+
+	opt := metric.WithAttributes(attrs...)
+
+	m.valueRecorders[messagingDurations[typ]].Record(ctx, d.Seconds(), opt)
+	m.counters[messagingMessages[typ]].Add(ctx, n, opt)
+}
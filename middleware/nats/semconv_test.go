@@ -0,0 +1,88 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+func (s *Suite) TestMessagingAttributes() {
+	msg := &nats.Msg{
+		Subject: "orders.42",
+		Data:    []byte("hello"),
+		Header:  nats.Header{nats.MsgIdHdr: []string{"id-1"}},
+		Sub:     &nats.Subscription{Subject: "orders.*", Queue: "workers"},
+	}
+
+	set := attribute.NewSet(MessagingAttributes(msg, KindSub)...)
+
+	for k, v := range map[attribute.Key]attribute.Value{
+		semconv.MessagingSystemKey:              attribute.StringValue("nats"),
+		semconv.MessagingDestinationNameKey:     attribute.StringValue("orders.42"),
+		semconv.MessagingDestinationTemplateKey: attribute.StringValue("orders.*"),
+		semconv.MessagingOperationTypeKey:       attribute.StringValue("process"),
+		semconv.MessagingOperationNameKey:       attribute.StringValue("process"),
+		semconv.MessagingMessageIDKey:           attribute.StringValue("id-1"),
+		semconv.MessagingMessageBodySizeKey:     attribute.IntValue(5),
+		MessagingConsumerGroupName:              attribute.StringValue("workers"),
+	} {
+		got, ok := set.Value(k)
+		s.True(ok, k)
+		s.Equal(v, got, k)
+	}
+
+	s.Equal("publish orders.:partition:", MessagingNameFn(KindPub, &nats.Msg{Subject: "orders.42"}))
+}
+
+func (s *Suite) TestSemConvMetrics() {
+	for _, tc := range []struct {
+		semConv SemConv
+		want    []string
+	}{
+		{SemConvLegacy, []string{Count, ContentLength, Latency}},
+		{SemConvMessaging, []string{MessagingProcessDuration, MessagingProcessMessages}},
+		{SemConvBoth, []string{Count, ContentLength, Latency, MessagingProcessDuration, MessagingProcessMessages}},
+	} {
+		reader := sdkmetric.NewManualReader()
+		provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+		m := createMeasures(s.tel, provider.Meter("test"))
+		m.semConv = tc.semConv
+
+		handler := NewMetrics(m).apply(func(ctx context.Context, msg *nats.Msg) error {
+			return fmt.Errorf("broken")
+		})
+
+		ctx := WrapKindOfContext(s.tel.Ctx(), KindSub)
+		s.Error(handler(ctx, &nats.Msg{Subject: "orders.42", Sub: &nats.Subscription{Subject: "orders.*"}}))
+
+		var rm metricdata.ResourceMetrics
+		s.Require().NoError(reader.Collect(context.Background(), &rm))
+
+		var got []string
+
+		for _, sm := range rm.ScopeMetrics {
+			for _, mt := range sm.Metrics {
+				got = append(got, mt.Name)
+
+				if mt.Name != MessagingProcessMessages {
+					continue
+				}
+
+				dp := mt.Data.(metricdata.Sum[int64]).DataPoints[0]
+				name, _ := dp.Attributes.Value(semconv.MessagingDestinationNameKey)
+				errType, _ := dp.Attributes.Value(semconv.ErrorTypeKey)
+
+				s.Equal("orders.*", name.AsString())
+				s.Equal("*errors.errorString", errType.AsString())
+			}
+		}
+
+		s.ElementsMatch(tc.want, got, tc.semConv)
+	}
+}
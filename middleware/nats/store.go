@@ -0,0 +1,221 @@
+package nats
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/pkg/errors"
+	"github.com/tel-io/tel/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Kinds of KeyValue and ObjectStore operations
+const (
+	KindKVGet     = "KV_GET"
+	KindKVPut     = "KV_PUT"
+	KindKVCreate  = "KV_CREATE"
+	KindKVUpdate  = "KV_UPDATE"
+	KindKVDelete  = "KV_DELETE"
+	KindKVPurge   = "KV_PURGE"
+	KindKVWatch   = "KV_WATCH"
+	KindObjPut    = "OBJ_PUT"
+	KindObjGet    = "OBJ_GET"
+	KindObjDelete = "OBJ_DELETE"
+)
+
+// Attribute keys of KeyValue and ObjectStore spans
+const (
+	Bucket      = attribute.Key("bucket")
+	Key         = attribute.Key("key")
+	Revision    = attribute.Key("revision")
+	KVOperation = attribute.Key("kv.operation")
+	KVDelta     = attribute.Key("kv.delta")
+	KVFound     = attribute.Key("kv.found")
+	ObjectSize  = attribute.Key("object.size")
+)
+
+// storeOp runs KeyValue or ObjectStore operation within own span, measures latency and logs outcome.
+// Not found error is not treated as failure.
+func (c *Core) storeOp(ctx context.Context, kind, measure, bucket, key string,
+	fn func(ctx context.Context) ([]attribute.KeyValue, error)) error {
+	opr := c.nameFn(kind, &nats.Msg{Subject: bucket})
+
+	span, ctx := startSpan(ctx, c.tracer, opr,
+		trace.WithSpanKind(convSpanToKind(kind)),
+		trace.WithLinks(SpanLinks(ctx)...),
+		trace.WithAttributes(Kind.String(kind), Bucket.String(bucket), Key.String(key)),
+	)
+	defer span.End()
+
+	start := time.Now()
+	attrs, err := fn(ctx)
+	duration := time.Since(start)
+
+	notFound := isNotFound(err)
+	failed := err != nil && !notFound
+
+	span.SetAttributes(attrs...)
+
+	switch {
+	case failed:
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+	case notFound:
+		span.SetAttributes(KVFound.Bool(false))
+	default:
+		span.SetStatus(codes.Ok, "")
+	}
+
+	c.metrics.valueRecorders[measure].Record(ctx, float64(duration.Milliseconds()),
+		metric.WithAttributes(Kind.String(kind), Bucket.String(bucket), IsError.Bool(failed)))
+
+	lvl := zapcore.DebugLevel
+	if failed {
+		lvl = zapcore.ErrorLevel
+	}
+
+	if ce := tel.FromCtx(ctx).Check(lvl, opr); ce != nil {
+		fields := []zap.Field{
+			tel.String(string(Bucket), bucket),
+			tel.String(string(Key), key),
+			tel.String(string(Duration), duration.String()),
+		}
+
+		if err != nil {
+			fields = append(fields, tel.Error(err))
+		}
+
+		ce.Write(fields...)
+	}
+
+	return err
+}
+
+func isNotFound(err error) bool {
+	return errors.Is(err, nats.ErrKeyNotFound) || errors.Is(err, jetstream.ErrKeyNotFound) ||
+		errors.Is(err, nats.ErrObjectNotFound) || errors.Is(err, jetstream.ErrObjectNotFound)
+}
+
+// kvEntry common part of KeyValue entry of legacy and jetstream package API
+type kvEntry interface {
+	Bucket() string
+	Key() string
+	Revision() uint64
+	Created() time.Time
+	Delta() uint64
+}
+
+// watch process every update of watcher with handler within consumer span linked to the watch caller span.
+// nil entry, which marks that all initial values are received, is skipped.
+func watch[E kvEntry](c *Core, ctx context.Context, bucket string, updates <-chan E, op func(E) string,
+	cb func(ctx context.Context, entry E) error) {
+	lag := c.kvWatchers.add(bucket)
+
+	link := trace.Link{SpanContext: trace.SpanContextFromContext(ctx)}
+
+	go func() {
+		defer c.kvWatchers.remove(lag)
+
+		for entry := range updates {
+			if kvEntry(entry) == nil {
+				continue
+			}
+
+			lag.delta.Store(int64(entry.Delta()))
+
+			_ = c.storeOp(WithSpanLinks(c.config.tele.Ctx(), link), KindKVWatch, KVLatency, bucket, entry.Key(),
+				func(ctx context.Context) ([]attribute.KeyValue, error) {
+					attrs := []attribute.KeyValue{
+						Revision.Int64(int64(entry.Revision())),
+						KVOperation.String(op(entry)),
+						KVDelta.Int64(int64(entry.Delta())),
+					}
+
+					return attrs, cb(ctx, entry)
+				})
+		}
+	}()
+}
+
+// KVWatchStat exposes lag of active KeyValue watchers
+type KVWatchStat struct {
+	list sync.Map
+	lag  metric.Int64ObservableGauge
+
+	reg metric.Registration
+}
+
+// kvWatch state of single watcher
+type kvWatch struct {
+	bucket string
+	delta  atomic.Int64
+}
+
+func newKVWatchStat(meter metric.Meter) (*KVWatchStat, error) {
+	lag, err := meter.Int64ObservableGauge(KVWatchLag)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "create %s", KVWatchLag)
+	}
+
+	res := &KVWatchStat{lag: lag}
+
+	if res.reg, err = meter.RegisterCallback(res.callback, lag); err != nil {
+		return nil, errors.WithMessagef(err, "reggister callback")
+	}
+
+	return res, nil
+}
+
+func (s *KVWatchStat) add(bucket string) *kvWatch {
+	w := &kvWatch{bucket: bucket}
+	s.list.Store(w, w)
+
+	return w
+}
+
+func (s *KVWatchStat) remove(w *kvWatch) {
+	s.list.Delete(w)
+}
+
+// Close unregister metric callback and forget all watchers
+func (s *KVWatchStat) Close() error {
+	s.list.Range(func(key, _ interface{}) bool {
+		s.list.Delete(key)
+		return true
+	})
+
+	return errors.WithStack(s.reg.Unregister())
+}
+
+func (s *KVWatchStat) callback(_ context.Context, o metric.Observer) error {
+	// the slowest watcher of bucket
+	data := make(map[string]int64)
+
+	s.list.Range(func(key, _ interface{}) bool {
+		w, ok := key.(*kvWatch)
+		if !ok {
+			return true
+		}
+
+		if v := w.delta.Load(); v >= data[w.bucket] {
+			data[w.bucket] = v
+		}
+
+		return true
+	})
+
+	for k, v := range data {
+		o.ObserveInt64(s.lag, v, metric.WithAttributes(Bucket.String(k)))
+	}
+
+	return nil
+}
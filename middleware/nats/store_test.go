@@ -0,0 +1,141 @@
+package nats
+
+import (
+	"context"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+func (s *Suite) TestKeyValue() {
+	conn := s.runServer()
+	mw := s.newCore().Use(conn)
+
+	js, err := mw.JetStream()
+	s.Require().NoError(err)
+
+	kv, err := js.CreateKeyValue(&nats.KeyValueConfig{Bucket: "flags"})
+	s.Require().NoError(err)
+
+	ctx := s.tel.Ctx()
+
+	updates := make(chan string, 10)
+
+	w, err := kv.WatchWithHandler(ctx, "feature.>", func(ctx context.Context, entry nats.KeyValueEntry) error {
+		updates <- entry.Operation().String() + ":" + entry.Key()
+		return nil
+	})
+	s.Require().NoError(err)
+
+	defer func() { s.NoError(w.Stop()) }()
+
+	// updates are consumed by handler
+	_, ok := w.(nats.KeyWatcher)
+	s.False(ok)
+
+	rev, err := kv.Put(ctx, "feature.a", []byte("on"))
+	s.Require().NoError(err)
+
+	_, err = kv.Update(ctx, "feature.a", []byte("off"), rev)
+	s.Require().NoError(err)
+
+	_, err = kv.Create(ctx, "feature.a", []byte("on"))
+	s.Error(err)
+
+	entry, err := kv.Get(ctx, "feature.a")
+	s.Require().NoError(err)
+	s.Equal("off", string(entry.Value()))
+
+	s.Require().NoError(kv.Delete(ctx, "feature.a"))
+
+	_, err = kv.Get(ctx, "feature.a")
+	s.ErrorIs(err, nats.ErrKeyNotFound)
+
+	for _, v := range []string{"KeyValuePutOp:feature.a", "KeyValuePutOp:feature.a", "KeyValueDeleteOp:feature.a"} {
+		select {
+		case got := <-updates:
+			s.Equal(v, got)
+		case <-time.After(5 * time.Second):
+			s.FailNow("no update")
+		}
+	}
+
+	s.Contains(s.buf.String(), "NATS:KV_UPDATE/flags")
+	s.Contains(s.buf.String(), "feature.a")
+	s.Contains(s.buf.String(), "wrong last sequence")
+}
+
+func (s *Suite) TestJetStreamKeyValue() {
+	conn := s.runServer()
+
+	js, err := jetstream.New(conn)
+	s.Require().NoError(err)
+
+	ctx := s.tel.Ctx()
+
+	bucket, err := js.CreateKeyValue(ctx, jetstream.KeyValueConfig{Bucket: "config"})
+	s.Require().NoError(err)
+
+	// wrapper keeps interface
+	var kv jetstream.KeyValue = s.newCore().WrapJetStreamKeyValue(bucket)
+
+	_, err = kv.PutString(ctx, "db.dsn", "postgres://")
+	s.Require().NoError(err)
+
+	entry, err := kv.Get(ctx, "db.dsn")
+	s.Require().NoError(err)
+	s.Equal("postgres://", string(entry.Value()))
+
+	s.Require().NoError(kv.Purge(ctx, "db.dsn"))
+
+	_, err = kv.Get(ctx, "db.dsn")
+	s.ErrorIs(err, jetstream.ErrKeyNotFound)
+
+	s.Contains(s.buf.String(), "NATS:KV_PURGE/config")
+}
+
+func (s *Suite) TestObjectStore() {
+	conn := s.runServer()
+	core := s.newCore()
+
+	js, err := core.Use(conn).JetStream()
+	s.Require().NoError(err)
+
+	obs, err := js.CreateObjectStore(&nats.ObjectStoreConfig{Bucket: "files"})
+	s.Require().NoError(err)
+
+	ctx := s.tel.Ctx()
+
+	info, err := obs.PutBytes(ctx, "a.txt", []byte("hello"))
+	s.Require().NoError(err)
+	s.Equal(uint64(5), info.Size)
+
+	data, err := obs.GetBytes(ctx, "a.txt")
+	s.Require().NoError(err)
+	s.Equal("hello", string(data))
+
+	s.Require().NoError(obs.Delete(ctx, "a.txt"))
+
+	_, err = obs.Get(ctx, "a.txt")
+	s.ErrorIs(err, nats.ErrObjectNotFound)
+
+	s.Contains(s.buf.String(), "NATS:OBJ_PUT/files")
+
+	jsAPI, err := jetstream.New(conn)
+	s.Require().NoError(err)
+
+	bucket, err := jsAPI.CreateObjectStore(ctx, jetstream.ObjectStoreConfig{Bucket: "blobs"})
+	s.Require().NoError(err)
+
+	var store jetstream.ObjectStore = core.WrapJetStreamObjectStore("blobs", bucket)
+
+	_, err = store.PutString(ctx, "b.txt", "world")
+	s.Require().NoError(err)
+
+	str, err := store.GetString(ctx, "b.txt")
+	s.Require().NoError(err)
+	s.Equal("world", str)
+
+	s.Contains(s.buf.String(), "NATS:OBJ_GET/blobs")
+}
@@ -13,6 +13,13 @@ type Subscriber interface {
 
 	QueueSubscribeSyncWithChan(subj, queue string, ch chan *nats.Msg) (*nats.Subscription, error)
 
+	SubscribeSync(subj string) (*SyncSubscription, error)
+	QueueSubscribeSync(subj, queue string) (*SyncSubscription, error)
+	ChanQueueSubscribe(subj, queue string, ch chan *nats.Msg) (*SyncSubscription, error)
+
+	SubscribeBatch(subj string, cb BatchHandler, opts ...BatchOption) (*nats.Subscription, error)
+	QueueSubscribeBatch(subj, queue string, cb BatchHandler, opts ...BatchOption) (*nats.Subscription, error)
+
 	BuildWrappedHandler(next MsgHandler) nats.MsgHandler
 }
 
@@ -59,7 +66,7 @@ func (c *CommonSubscribe) QueueSubscribe(subj, queue string, cb MsgHandler) (*na
 // which will be placed on the channel.
 // You should not close the channel until sub.Unsubscribe() has been called.
 //
-// NOTE: middleware only subscription hook performed
+// NOTE: middleware only subscription hook performed, use ChanQueueSubscribe to process messages with middleware chain
 func (c *CommonSubscribe) QueueSubscribeSyncWithChan(subj, queue string, ch chan *nats.Msg) (*nats.Subscription, error) {
 	return c.subMeter.Hook(c.conn.QueueSubscribeSyncWithChan(subj, queue, ch))
 }
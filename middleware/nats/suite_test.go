@@ -3,9 +3,16 @@ package nats
 import (
 	"bytes"
 	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
 	"github.com/stretchr/testify/suite"
 	"github.com/tel-io/tel/v2"
-	"testing"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 type Suite struct {
@@ -14,7 +21,34 @@ type Suite struct {
 	tel   tel.Telemetry
 	close func()
 
-	buf *bytes.Buffer
+	buf *syncBuffer
+}
+
+// syncBuffer log output safe for handlers of concurrent subscriptions
+type syncBuffer struct {
+	mx  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	return b.buf.String()
+}
+
+func (b *syncBuffer) Reset() {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	b.buf.Reset()
 }
 
 func (s *Suite) SetupSuite() {
@@ -23,7 +57,15 @@ func (s *Suite) SetupSuite() {
 	c.OtelConfig.Enable = false
 
 	s.tel, s.close = tel.New(context.Background(), c)
-	s.buf = tel.SetLogOutput(&s.tel)
+	s.buf = &syncBuffer{}
+
+	// the same as tel.SetLogOutput, but safe for concurrent writes
+	out := zapcore.NewCore(zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig()),
+		zapcore.AddSync(s.buf), zapcore.DebugLevel)
+
+	s.tel.Logger = s.tel.Logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(core, out)
+	}))
 }
 
 func (s *Suite) TearDownSuite() {
@@ -34,6 +76,44 @@ func (s *Suite) TearDownTest() {
 	s.buf.Reset()
 }
 
+// runServer start embedded nats server with JetStream enabled
+func (s *Suite) runServer() *nats.Conn {
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      server.RANDOM_PORT,
+		NoLog:     true,
+		NoSigs:    true,
+		JetStream: true,
+		StoreDir:  s.T().TempDir(),
+	})
+	s.Require().NoError(err)
+
+	go srv.Start()
+	s.Require().True(srv.ReadyForConnections(5 * time.Second))
+
+	conn, err := nats.Connect(srv.ClientURL())
+	s.Require().NoError(err)
+
+	s.T().Cleanup(func() {
+		conn.Close()
+		srv.Shutdown()
+		srv.WaitForShutdown()
+	})
+
+	return conn
+}
+
+// newCore creates middleware instance closed on test cleanup
+func (s *Suite) newCore(opts ...Option) *Core {
+	c := New(append([]Option{WithTel(s.tel)}, opts...)...)
+
+	s.T().Cleanup(func() {
+		s.NoError(c.Close())
+	})
+
+	return c
+}
+
 func TestInit(t *testing.T) {
 	suite.Run(t, new(Suite))
 }
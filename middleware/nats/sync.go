@@ -0,0 +1,184 @@
+package nats
+
+import (
+	"context"
+	"sync"
+
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+)
+
+// SyncSubscription synchronous subscription which process every message obtained via NextMsgWithContext
+// with subscriber middleware chain: recovery, logs, tracer, metrics and etc.
+//
+// Middleware chain runs until Done is called, so Done must be called for every received message.
+// Chains of messages abandoned without Done are completed with ErrSyncAbandoned when subscription
+// or connection is closed.
+type SyncSubscription struct {
+	*nats.Subscription
+
+	core *Core
+	next func(ctx context.Context) (*nats.Msg, error)
+
+	// closed with subscription or connection
+	closed <-chan struct{}
+}
+
+// ErrSyncAbandoned subscription or connection is closed before Done call of received message
+var ErrSyncAbandoned = errors.New("nats: sync message abandoned without Done")
+
+// SubscribeSync will express interest on the given subject. Messages will
+// be received synchronously using SyncSubscription.NextMsgWithContext.
+func (c *CommonSubscribe) SubscribeSync(subj string) (*SyncSubscription, error) {
+	return c.syncSubscription(c.conn.SubscribeSync(subj))
+}
+
+// QueueSubscribeSync creates a synchronous queue subscriber on the given subject.
+// Messages will be received synchronously using SyncSubscription.NextMsgWithContext.
+func (c *CommonSubscribe) QueueSubscribeSync(subj, queue string) (*SyncSubscription, error) {
+	return c.syncSubscription(c.conn.QueueSubscribeSync(subj, queue))
+}
+
+// ChanQueueSubscribe will express interest in the given subject, messages are placed on the channel.
+// Messages should be received using SyncSubscription.NextMsgWithContext instead of reading channel directly.
+// You should not close the channel until sub.Unsubscribe() has been called.
+func (c *CommonSubscribe) ChanQueueSubscribe(subj, queue string, ch chan *nats.Msg) (*SyncSubscription, error) {
+	sub, err := c.subMeter.Hook(c.conn.ChanQueueSubscribe(subj, queue, ch))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &SyncSubscription{
+		Subscription: sub,
+		core:         c.Core,
+		closed:       subClosed(c.conn, sub),
+		next: func(ctx context.Context) (*nats.Msg, error) {
+			select {
+			case msg, ok := <-ch:
+				if !ok {
+					return nil, nats.ErrBadSubscription
+				}
+
+				return msg, nil
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		},
+	}, nil
+}
+
+func (c *CommonSubscribe) syncSubscription(sub *nats.Subscription, err error) (*SyncSubscription, error) {
+	sub, err = c.subMeter.Hook(sub, err)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &SyncSubscription{
+		Subscription: sub,
+		core:         c.Core,
+		next:         sub.NextMsgWithContext,
+		closed:       subClosed(c.conn, sub),
+	}, nil
+}
+
+// subClosed returns channel closed with subscription or connection:
+// connection close doesn't change status of its subscriptions
+func subClosed(conn *nats.Conn, sub *nats.Subscription) <-chan struct{} {
+	done := make(chan struct{})
+
+	subStatus := sub.StatusChanged(nats.SubscriptionClosed)
+	connStatus := conn.StatusChanged(nats.CLOSED)
+
+	if !sub.IsValid() || conn.IsClosed() {
+		close(done)
+		return done
+	}
+
+	go func() {
+		select {
+		case <-subStatus:
+		case <-connStatus:
+		}
+
+		close(done)
+	}()
+
+	return done
+}
+
+// NextMsgWithContext waits next message and starts its processing by middleware chain.
+// ctx is used only for waiting, returned context carries extracted trace and tel logger of the message.
+// Processing should be finished by Done with returned context.
+//
+// Messages which are handled by middleware without reaching the handler are skipped.
+func (s *SyncSubscription) NextMsgWithContext(ctx context.Context) (context.Context, *nats.Msg, error) {
+	for {
+		msg, err := s.next(ctx)
+		if err != nil {
+			return ctx, nil, errors.WithStack(err)
+		}
+
+		if mctx, ok := s.core.startSync(msg, s.closed); ok {
+			return mctx, msg, nil
+		}
+	}
+}
+
+// Done finishes processing of message obtained by NextMsgWithContext, err is outcome of processing.
+// It waits until middleware chain is completed. Second call is ignored.
+func (s *SyncSubscription) Done(ctx context.Context, err error) {
+	p, ok := ctx.Value(syncKey{}).(*syncProcessing)
+	if !ok {
+		return
+	}
+
+	p.once.Do(func() {
+		p.res <- err
+		<-p.done
+	})
+}
+
+type syncKey struct{}
+
+// syncProcessing state of message processed by middleware chain in background
+type syncProcessing struct {
+	ctx  chan context.Context
+	res  chan error
+	done chan struct{}
+	once sync.Once
+}
+
+// startSync runs middleware chain in background until Done is called or closed is closed,
+// false is returned when middleware didn't call handler
+func (c *Core) startSync(msg *nats.Msg, closed <-chan struct{}) (context.Context, bool) {
+	p := &syncProcessing{
+		ctx:  make(chan context.Context, 1),
+		res:  make(chan error, 1),
+		done: make(chan struct{}),
+	}
+
+	in := c.subHandler(func(ctx context.Context, _ *nats.Msg) error {
+		ctx = context.WithValue(ctx, syncKey{}, p)
+		p.ctx <- ctx
+
+		select {
+		case err := <-p.res:
+			return err
+		case <-closed:
+			return ErrSyncAbandoned
+		}
+	})
+
+	go func() {
+		defer close(p.done)
+
+		_ = in(c.config.tele.Ctx(), msg)
+	}()
+
+	select {
+	case ctx := <-p.ctx:
+		return ctx, true
+	case <-p.done:
+		return nil, false
+	}
+}
@@ -0,0 +1,104 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+func (s *Suite) TestSubscribeSync() {
+	conn := s.runServer()
+	mw := s.newCore().Use(conn)
+
+	sub, err := mw.QueueSubscribeSync("sync.demo", "queue")
+	s.Require().NoError(err)
+
+	s.Require().NoError(mw.PublishWithContext(s.tel.Ctx(), "sync.demo", []byte("hello")))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	mctx, msg, err := sub.NextMsgWithContext(ctx)
+	s.Require().NoError(err)
+	s.Equal("hello", string(msg.Data))
+	s.NotNil(mctx.Value(syncKey{}))
+
+	sub.Done(mctx, errors.New("sync failure"))
+	// second call is ignored
+	sub.Done(mctx, nil)
+
+	s.Contains(s.buf.String(), "sync failure")
+	s.Contains(s.buf.String(), "NATS:SUB/queue/sync.demo")
+
+	_, _, err = sub.NextMsgWithContext(ctx)
+	s.ErrorIs(err, context.DeadlineExceeded)
+}
+
+func (s *Suite) TestChanQueueSubscribe() {
+	conn := s.runServer()
+	mw := s.newCore().Use(conn)
+
+	ch := make(chan *nats.Msg, 8)
+
+	sub, err := mw.ChanQueueSubscribe("chan.demo", "queue", ch)
+	s.Require().NoError(err)
+
+	for _, v := range []string{"a", "b"} {
+		s.Require().NoError(mw.PublishWithContext(s.tel.Ctx(), "chan.demo", []byte(v)))
+	}
+
+	var got []string
+
+	for range 2 {
+		ctx, msg, err := sub.NextMsgWithContext(s.tel.Ctx())
+		s.Require().NoError(err)
+
+		got = append(got, string(msg.Data))
+		sub.Done(ctx, nil)
+	}
+
+	s.Equal([]string{"a", "b"}, got)
+	s.Contains(s.buf.String(), "NATS:SUB/queue/chan.demo")
+}
+
+func (s *Suite) TestSubscribeSync_Abandoned() {
+	conn := s.runServer()
+	mw := s.newCore().Use(conn)
+
+	sub, err := mw.SubscribeSync("sync.abandoned")
+	s.Require().NoError(err)
+
+	ch := make(chan *nats.Msg, 1)
+
+	chanSub, err := mw.ChanQueueSubscribe("chan.abandoned", "queue", ch)
+	s.Require().NoError(err)
+
+	for _, subj := range []string{"sync.abandoned", "chan.abandoned"} {
+		s.Require().NoError(mw.PublishWithContext(s.tel.Ctx(), subj, []byte("hello")))
+	}
+
+	// caller returns without Done
+	_, _, err = sub.NextMsgWithContext(s.tel.Ctx())
+	s.Require().NoError(err)
+
+	_, _, err = chanSub.NextMsgWithContext(s.tel.Ctx())
+	s.Require().NoError(err)
+
+	// chain is completed on unsubscribe
+	s.Require().NoError(sub.Unsubscribe())
+
+	s.Eventually(func() bool {
+		return strings.Contains(s.buf.String(), "sync.abandoned") &&
+			strings.Contains(s.buf.String(), ErrSyncAbandoned.Error())
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// and on connection close
+	conn.Close()
+
+	s.Eventually(func() bool {
+		return strings.Contains(s.buf.String(), "NATS:SUB/queue/chan.abandoned")
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -2,9 +2,13 @@ package nats
 
 import (
 	"context"
+	"strconv"
+	"time"
+
 	"github.com/nats-io/nats.go"
 	"github.com/tel-io/instrumentation/middleware/nats/v2/natsprop"
 	"github.com/tel-io/tel/v2"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/baggage"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
@@ -12,7 +16,22 @@ import (
 
 // Tracer for subscribers implementing Middleware
 type Tracer struct {
-	nameFn NameFn
+	// nil means tel tracer
+	tracer trace.Tracer
+
+	nameFn   NameFn
+	semConv  SemConv
+	propOpts []natsprop.Option
+
+	// consumer spans are new roots linked to producer span
+	newRoot bool
+	// producers stamp HeaderPublishTime
+	publishTime bool
+
+	// payload is added as span event decoded by registry
+	payload            *PayloadRegistry
+	dump               bool
+	dumpPayloadOnError bool
 }
 
 func NewTracer(fn NameFn) *Tracer {
@@ -27,19 +46,44 @@ func (t *Tracer) apply(next MsgHandler) MsgHandler {
 			attr = ExtractAttributes(msg, kind, true)
 		)
 
-		_, bg, spanContext := natsprop.Extract(ctx, msg)
-		ctx = trace.ContextWithRemoteSpanContext(ctx, spanContext)
-		ctx = baggage.ContextWithBaggage(ctx, bg)
+		spanKind := convSpanToKind(kind)
+		opts := []trace.SpanStartOption{trace.WithSpanKind(spanKind)}
 
-		span, ctx := tel.StartSpanFromContext(ctx, opr,
-			trace.WithSpanKind(convSpanToKind(kind)),
-		)
+		_, bg, spanContext := natsprop.Extract(ctx, msg, t.propOpts...)
+		// kind_of baggage could be sent by previous versions
+		ctx = baggage.ContextWithBaggage(ctx, bg.DeleteMember(KindKey))
+
+		links := SpanLinks(ctx)
+
+		switch {
+		case t.newRoot && spanKind == trace.SpanKindConsumer:
+			opts = append(opts, trace.WithNewRoot())
+
+			if spanContext.IsRemote() {
+				links = append(links, trace.Link{
+					SpanContext: spanContext,
+					Attributes:  []attribute.KeyValue{Kind.String(KindPub)},
+				})
+			}
+		default:
+			ctx = trace.ContextWithRemoteSpanContext(ctx, spanContext)
+		}
+
+		span, ctx := startSpan(ctx, t.tracer, opr, append(opts, trace.WithLinks(links...))...)
 		defer span.End(trace.WithStackTrace(true))
 
+		if t.semConv.messaging() {
+			span.SetAttributes(MessagingAttributes(msg, kind)...)
+		}
+
 		tel.FromCtx(ctx).PutAttr(attr...)
 		tel.UpdateTraceFields(ctx)
 
-		natsprop.Inject(ctx, msg)
+		natsprop.Inject(ctx, msg, t.propOpts...)
+
+		if t.publishTime && (kind == KindPub || kind == KindRequest) && msg.Header.Get(HeaderPublishTime) == "" {
+			msg.Header.Set(HeaderPublishTime, strconv.FormatInt(time.Now().UnixNano(), 10))
+		}
 
 		err := next(ctx, msg)
 		if err != nil {
@@ -49,6 +93,10 @@ func (t *Tracer) apply(next MsgHandler) MsgHandler {
 			span.SetStatus(codes.Ok, "")
 		}
 
+		if t.payload != nil && (t.dump || (t.dumpPayloadOnError && err != nil)) && msg.Data != nil {
+			span.AddEvent(PayloadKey, trace.WithAttributes(attribute.String(PayloadKey, t.payload.Dump(msg))))
+		}
+
 		return err
 	}
 }
@@ -56,11 +104,13 @@ func (t *Tracer) apply(next MsgHandler) MsgHandler {
 // convert kind_of to tracers span kinds
 func convSpanToKind(v string) trace.SpanKind {
 	switch v {
-	case KindSub:
+	case KindSub, KindFetch, KindKVWatch, KindBatch:
 		return trace.SpanKindConsumer
 	case KindPub:
 		return trace.SpanKindProducer
-	case KindRequest, KindRespond:
+	case KindRequest, KindRespond, KindPubAck,
+		KindKVGet, KindKVPut, KindKVCreate, KindKVUpdate, KindKVDelete, KindKVPurge,
+		KindObjPut, KindObjGet, KindObjDelete:
 		return trace.SpanKindClient
 	case KindReply:
 		return trace.SpanKindServer
@@ -0,0 +1,114 @@
+package nats
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func (s *Suite) TestConsumerNewRoot() {
+	prev := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	defer otel.SetTextMapPropagator(prev)
+
+	producer := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+	})
+
+	for _, newRoot := range []bool{false, true} {
+		mw := s.newCore(WithConsumerNewRoot(newRoot))
+
+		msg := nats.NewMsg("root.demo")
+		otel.GetTextMapPropagator().Inject(trace.ContextWithSpanContext(context.Background(), producer),
+			propagation.HeaderCarrier(msg.Header))
+
+		var got trace.SpanContext
+
+		handler := mw.subHandler(func(ctx context.Context, msg *nats.Msg) error {
+			got = trace.SpanContextFromContext(ctx)
+			return nil
+		})
+		s.Require().NoError(handler(s.tel.Ctx(), msg))
+
+		s.Equal(!newRoot, got.TraceID() == producer.TraceID(), newRoot)
+	}
+}
+
+func (s *Suite) TestPublishTimestamp() {
+	conn := s.runServer()
+
+	sub, err := conn.SubscribeSync("e2e.demo")
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.newCore(WithPublishTimestamp(true)).Use(conn).PublishWithContext(s.tel.Ctx(), "e2e.demo", nil))
+	// disabled by default
+	s.Require().NoError(s.newCore().Use(conn).PublishWithContext(s.tel.Ctx(), "e2e.demo", nil))
+
+	msg, err := sub.NextMsg(time.Second)
+	s.Require().NoError(err)
+
+	v, err := strconv.ParseInt(msg.Header.Get(HeaderPublishTime), 10, 64)
+	s.Require().NoError(err)
+	s.WithinDuration(time.Now(), time.Unix(0, v), time.Second)
+
+	msg, err = sub.NextMsg(time.Second)
+	s.Require().NoError(err)
+	s.Empty(msg.Header.Get(HeaderPublishTime))
+}
+
+func (s *Suite) TestEndToEndLatency() {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	handler := NewMetrics(createMeasures(s.tel, provider.Meter("test"))).apply(
+		func(ctx context.Context, msg *nats.Msg) error { return nil },
+	)
+
+	msg := nats.NewMsg("e2e.demo")
+	msg.Header.Set(HeaderPublishTime, strconv.FormatInt(time.Now().Add(-time.Second).UnixNano(), 10))
+
+	s.Require().NoError(handler(WrapKindOfContext(s.tel.Ctx(), KindSub), msg))
+
+	// producer clock is ahead
+	skewed := nats.NewMsg("e2e.demo")
+	skewed.Header.Set(HeaderPublishTime, strconv.FormatInt(time.Now().Add(time.Hour).UnixNano(), 10))
+	s.Require().NoError(handler(WrapKindOfContext(s.tel.Ctx(), KindSub), skewed))
+
+	// producers don't record it
+	s.Require().NoError(handler(WrapKindOfContext(s.tel.Ctx(), KindPub), msg))
+
+	var rm metricdata.ResourceMetrics
+	s.Require().NoError(reader.Collect(context.Background(), &rm))
+
+	var found bool
+
+	for _, sm := range rm.ScopeMetrics {
+		for _, mt := range sm.Metrics {
+			if mt.Name != EndToEnd {
+				continue
+			}
+
+			found = true
+			dp := mt.Data.(metricdata.Histogram[float64]).DataPoints[0]
+
+			s.Equal(uint64(2), dp.Count)
+			s.GreaterOrEqual(dp.Sum, float64(1000))
+
+			lowest, ok := dp.Min.Value()
+			s.True(ok)
+			s.Zero(lowest, "negative latency is clamped")
+		}
+	}
+
+	s.True(found)
+}
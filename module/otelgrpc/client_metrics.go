@@ -5,7 +5,6 @@ import (
 	"io"
 	"time"
 
-	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -16,7 +15,7 @@ import (
 // Prometheus metrics registry for a gRPC client.
 type ClientMetrics struct {
 	meter                         metric.Meter
-	labels                        []attribute.KeyValue
+	labels                        *labelSet
 	clientHandledHistogramEnabled bool
 
 	counters       map[string]metric.Int64Counter
@@ -50,7 +49,7 @@ func NewClientMetrics(counterOpts ...Option) *ClientMetrics {
 
 func (m *ClientMetrics) configure(c *config) {
 	m.meter = c.Meter
-	m.labels = c.Labels
+	m.labels = newLabelSet(c)
 
 	m.clientHandledHistogramEnabled = true
 }
@@ -7,40 +7,26 @@ import (
 	"context"
 	"time"
 
-	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/metric"
 	"google.golang.org/grpc/codes"
 )
 
 type clientReporter struct {
-	metrics     *ClientMetrics
-	rpcType     grpcType
-	serviceName string
-	methodName  string
-	startTime   time.Time
+	metrics   *ClientMetrics
+	labels    *callLabels
+	startTime time.Time
 }
 
 func newClientReporter(ctx context.Context, m *ClientMetrics, rpcType grpcType, fullMethod string) *clientReporter {
 	r := &clientReporter{
 		metrics: m,
-		rpcType: rpcType,
+		labels:  m.labels.call(ctx, rpcType, fullMethod),
 	}
 
 	if r.metrics.clientHandledHistogramEnabled {
 		r.startTime = time.Now()
 	}
 
-	r.serviceName, r.methodName = splitMethodName(fullMethod)
-	r.metrics.counters[clientStartedCounter].Add(ctx, 1,
-		metric.WithAttributes(
-			append(
-				r.metrics.labels,
-				attribute.String(AttrType, string(r.rpcType)),
-				attribute.String(AttrService, r.serviceName),
-				attribute.String(AttrMethod, r.methodName),
-			)...,
-		),
-	)
+	r.metrics.counters[clientStartedCounter].Add(ctx, 1, r.labels.commonOpt())
 
 	return r
 }
@@ -50,15 +36,7 @@ func (r *clientReporter) ReceiveMessageTimer(ctx context.Context, startTime time
 }
 
 func (r *clientReporter) ReceivedMessage(ctx context.Context) {
-	r.metrics.counters[clientStreamMsgReceived].Add(ctx, 1,
-		metric.WithAttributes(
-			append(r.metrics.labels,
-				attribute.String(AttrType, string(r.rpcType)),
-				attribute.String(AttrService, r.serviceName),
-				attribute.String(AttrMethod, r.methodName),
-			)...,
-		),
-	)
+	r.metrics.counters[clientStreamMsgReceived].Add(ctx, 1, r.labels.commonOpt())
 }
 
 func (r *clientReporter) SendMessageTimer(ctx context.Context, startTime time.Time) {
@@ -66,28 +44,11 @@ func (r *clientReporter) SendMessageTimer(ctx context.Context, startTime time.Ti
 }
 
 func (r *clientReporter) SentMessage(ctx context.Context) {
-	r.metrics.counters[clientStreamMsgSent].Add(ctx, 1,
-		metric.WithAttributes(
-			append(r.metrics.labels,
-				attribute.String(AttrType, string(r.rpcType)),
-				attribute.String(AttrService, r.serviceName),
-				attribute.String(AttrMethod, r.methodName),
-			)...,
-		),
-	)
+	r.metrics.counters[clientStreamMsgSent].Add(ctx, 1, r.labels.commonOpt())
 }
 
 func (r *clientReporter) Handled(ctx context.Context, code codes.Code) {
-	r.metrics.counters[clientHandledCounter].Add(ctx, 1,
-		metric.WithAttributes(
-			append(r.metrics.labels,
-				attribute.String(AttrType, string(r.rpcType)),
-				attribute.String(AttrService, r.serviceName),
-				attribute.String(AttrMethod, r.methodName),
-				attribute.String(AttrCode, code.String()),
-			)...,
-		),
-	)
+	r.metrics.counters[clientHandledCounter].Add(ctx, 1, r.labels.codeOpt(code))
 
 	if r.metrics.clientHandledHistogramEnabled {
 		r.startTimer(ctx, clientHandledHistogram, r.startTime)
@@ -101,13 +62,5 @@ func (r *clientReporter) startTimer(ctx context.Context, mtr string, startTime t
 
 	dur := float64(time.Since(startTime).Seconds())
 
-	r.metrics.valueRecorders[mtr].Record(ctx, dur,
-		metric.WithAttributes(
-			append(r.metrics.labels,
-				attribute.String(AttrType, string(r.rpcType)),
-				attribute.String(AttrService, r.serviceName),
-				attribute.String(AttrMethod, r.methodName),
-			)...,
-		),
-	)
+	r.metrics.valueRecorders[mtr].Record(r.labels.exemplarCtx(ctx), dur, r.labels.commonOpt())
 }
@@ -29,6 +29,8 @@ type config struct {
 	Meter         metric.Meter
 	MeterProvider metric.MeterProvider
 	Labels        []attribute.KeyValue
+	// LabelExtractor dynamic labels per call
+	LabelExtractor LabelExtractor
 
 	// grpc_server_handling_seconds metric
 	Bucket []float64
@@ -80,6 +82,13 @@ func WithConstLabels(l ...attribute.KeyValue) Option {
 	})
 }
 
+// WithLabelExtractor add dynamic labels, e.g. peer service or tenant, to all counters and histograms of the call
+func WithLabelExtractor(fn LabelExtractor) Option {
+	return optionFunc(func(cfg *config) {
+		cfg.LabelExtractor = fn
+	})
+}
+
 // WithBucket for grpc_server_handling_seconds metric
 func WithBucket(bucket []float64) Option {
 	return optionFunc(func(cfg *config) {
@@ -0,0 +1,149 @@
+package otelgrpc
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+)
+
+// LabelExtractor returns dynamic labels for the call, e.g. peer service or tenant.
+// Labels are applied to all counters and histograms of the call, so keep cardinality low.
+type LabelExtractor func(ctx context.Context, fullMethod string) []attribute.KeyValue
+
+type methodKey struct {
+	rpcType    grpcType
+	fullMethod string
+}
+
+// methodLabels precomputed attribute sets of single method
+type methodLabels struct {
+	base   []attribute.KeyValue
+	common metric.MeasurementOption
+
+	// codes.Code -> metric.MeasurementOption
+	handled sync.Map
+}
+
+func (m *methodLabels) codeOpt(code codes.Code) metric.MeasurementOption {
+	if v, ok := m.handled.Load(code); ok {
+		return v.(metric.MeasurementOption)
+	}
+
+	v, _ := m.handled.LoadOrStore(code, metric.WithAttributeSet(withCode(m.base, code)))
+
+	return v.(metric.MeasurementOption)
+}
+
+// labelSet cache attribute sets per method so hot path doesn't allocate
+type labelSet struct {
+	constLabels []attribute.KeyValue
+	extractor   LabelExtractor
+
+	// methodKey -> *methodLabels
+	methods sync.Map
+}
+
+func newLabelSet(c *config) *labelSet {
+	return &labelSet{
+		constLabels: c.Labels,
+		extractor:   c.LabelExtractor,
+	}
+}
+
+func (l *labelSet) method(rpcType grpcType, fullMethod string) *methodLabels {
+	key := methodKey{rpcType: rpcType, fullMethod: fullMethod}
+
+	if v, ok := l.methods.Load(key); ok {
+		return v.(*methodLabels)
+	}
+
+	serviceName, methodName := splitMethodName(fullMethod)
+
+	// never append to constLabels: it's shared across goroutines
+	base := make([]attribute.KeyValue, 0, len(l.constLabels)+3)
+	base = append(base, l.constLabels...)
+	base = append(base,
+		attribute.String(AttrType, string(rpcType)),
+		attribute.String(AttrService, serviceName),
+		attribute.String(AttrMethod, methodName),
+	)
+
+	v, _ := l.methods.LoadOrStore(key, &methodLabels{
+		base:   base,
+		common: metric.WithAttributeSet(attribute.NewSet(base...)),
+	})
+
+	return v.(*methodLabels)
+}
+
+// call returns labels of single call with dynamic labels applied
+func (l *labelSet) call(ctx context.Context, rpcType grpcType, fullMethod string) *callLabels {
+	c := &callLabels{
+		method:  l.method(rpcType, fullMethod),
+		spanCtx: trace.SpanContextFromContext(ctx),
+	}
+
+	if l.extractor == nil {
+		return c
+	}
+
+	extra := l.extractor(ctx, fullMethod)
+	if len(extra) == 0 {
+		return c
+	}
+
+	c.attrs = make([]attribute.KeyValue, 0, len(c.method.base)+len(extra))
+	c.attrs = append(c.attrs, c.method.base...)
+	c.attrs = append(c.attrs, extra...)
+	c.common = metric.WithAttributeSet(attribute.NewSet(c.attrs...))
+
+	return c
+}
+
+type callLabels struct {
+	method *methodLabels
+
+	// only with dynamic labels
+	attrs  []attribute.KeyValue
+	common metric.MeasurementOption
+
+	// spanCtx used for exemplars when measurement ctx lost span
+	spanCtx trace.SpanContext
+}
+
+func (c *callLabels) commonOpt() metric.MeasurementOption {
+	if c.common != nil {
+		return c.common
+	}
+
+	return c.method.common
+}
+
+func (c *callLabels) codeOpt(code codes.Code) metric.MeasurementOption {
+	if c.attrs != nil {
+		return metric.WithAttributeSet(withCode(c.attrs, code))
+	}
+
+	return c.method.codeOpt(code)
+}
+
+// exemplarCtx returns ctx which carry call span, so histogram exemplars could point to the trace
+func (c *callLabels) exemplarCtx(ctx context.Context) context.Context {
+	if !c.spanCtx.IsValid() || trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+
+	return trace.ContextWithSpanContext(ctx, c.spanCtx)
+}
+
+func withCode(base []attribute.KeyValue, code codes.Code) attribute.Set {
+	attrs := make([]attribute.KeyValue, 0, len(base)+1)
+	attrs = append(attrs, base...)
+	attrs = append(attrs, attribute.String(AttrCode, code.String()))
+
+	return attribute.NewSet(attrs...)
+}
@@ -0,0 +1,141 @@
+package otelgrpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestLabelExtractor(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	m := NewServerMetrics(
+		WithMeterProvider(provider),
+		WithConstLabels(attribute.String("const", "value")),
+		WithLabelExtractor(func(ctx context.Context, fullMethod string) []attribute.KeyValue {
+			md, _ := metadata.FromIncomingContext(ctx)
+			return []attribute.KeyValue{attribute.String("tenant", md.Get("tenant")[0])}
+		}),
+	)
+
+	interceptor := m.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/helloworld.Greeter/SayHello"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return req, nil }
+
+	for _, tenant := range []string{"a", "b", "a"} {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("tenant", tenant))
+		_, err := interceptor(ctx, nil, info, handler)
+		require.NoError(t, err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	got := map[string]int64{}
+
+	for _, sm := range rm.ScopeMetrics {
+		for _, mt := range sm.Metrics {
+			if mt.Name != serverHandledCounter {
+				continue
+			}
+
+			for _, dp := range mt.Data.(metricdata.Sum[int64]).DataPoints {
+				tenant, _ := dp.Attributes.Value("tenant")
+				code, _ := dp.Attributes.Value(AttrCode)
+				constant, _ := dp.Attributes.Value("const")
+
+				assert.Equal(t, "OK", code.AsString())
+				assert.Equal(t, "value", constant.AsString())
+
+				got[tenant.AsString()] = dp.Value
+			}
+		}
+	}
+
+	assert.Equal(t, map[string]int64{"a": 2, "b": 1}, got)
+}
+
+func TestLabelSetCache(t *testing.T) {
+	l := newLabelSet(&config{Labels: []attribute.KeyValue{attribute.String("const", "value")}})
+
+	a := l.call(context.Background(), Unary, "/svc/A")
+	b := l.call(context.Background(), Unary, "/svc/A")
+
+	assert.Same(t, a.method, b.method)
+	assert.Len(t, l.constLabels, 1)
+}
+
+func TestExemplarCtx(t *testing.T) {
+	call := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+	})
+	own := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{2},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+	})
+
+	l := newLabelSet(&config{})
+	c := l.call(trace.ContextWithSpanContext(context.Background(), call), Unary, "/svc/A")
+
+	assert.Equal(t, call, trace.SpanContextFromContext(c.exemplarCtx(context.Background())),
+		"ctx without span gets span of the call")
+	assert.Equal(t, own, trace.SpanContextFromContext(c.exemplarCtx(trace.ContextWithSpanContext(context.Background(), own))),
+		"span of ctx is kept")
+
+	empty := l.call(context.Background(), Unary, "/svc/A")
+	assert.False(t, trace.SpanContextFromContext(empty.exemplarCtx(context.Background())).IsValid())
+}
+
+func TestExemplars(t *testing.T) {
+	t.Setenv("OTEL_GO_X_EXEMPLAR", "true")
+
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	m := NewServerMetrics(WithMeterProvider(provider))
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0xa},
+		SpanID:     trace.SpanID{0xb},
+		TraceFlags: trace.FlagsSampled,
+	})
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/helloworld.Greeter/SayHello"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return req, nil }
+
+	_, err := m.UnaryServerInterceptor()(trace.ContextWithSpanContext(context.Background(), sc), nil, info, handler)
+	require.NoError(t, err)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	var exemplars []metricdata.Exemplar[float64]
+
+	for _, sm := range rm.ScopeMetrics {
+		for _, mt := range sm.Metrics {
+			if mt.Name != serverHandledHistogram {
+				continue
+			}
+
+			for _, dp := range mt.Data.(metricdata.Histogram[float64]).DataPoints {
+				exemplars = append(exemplars, dp.Exemplars...)
+			}
+		}
+	}
+
+	require.Len(t, exemplars, 1)
+	assert.Equal(t, sc.TraceID().String(), trace.TraceID(exemplars[0].TraceID).String())
+	assert.Equal(t, sc.SpanID().String(), trace.SpanID(exemplars[0].SpanID).String())
+}
@@ -5,7 +5,6 @@ import (
 
 	"github.com/tel-io/instrumentation/module/otelgrpc/packages/grpcstatus"
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
 	"google.golang.org/grpc"
 )
@@ -29,7 +28,7 @@ const (
 // Prometheus metrics registry for a gRPC server.
 type ServerMetrics struct {
 	meter                         metric.Meter
-	labels                        []attribute.KeyValue
+	labels                        *labelSet
 	bucket                        []float64
 	serverHandledHistogramEnabled bool
 
@@ -53,7 +52,7 @@ func NewServerMetrics(counterOpts ...Option) *ServerMetrics {
 
 func (m *ServerMetrics) configure(c *config) {
 	m.meter = c.Meter
-	m.labels = c.Labels
+	m.labels = newLabelSet(c)
 	m.bucket = c.Bucket
 
 	m.serverHandledHistogramEnabled = true
@@ -7,90 +7,44 @@ import (
 	"context"
 	"time"
 
-	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/metric"
 	"google.golang.org/grpc/codes"
 )
 
 type serverReporter struct {
-	metrics     *ServerMetrics
-	rpcType     grpcType
-	serviceName string
-	methodName  string
-	startTime   time.Time
+	metrics   *ServerMetrics
+	labels    *callLabels
+	startTime time.Time
 }
 
 func newServerReporter(ctx context.Context, m *ServerMetrics, rpcType grpcType, fullMethod string) *serverReporter {
 	r := &serverReporter{
 		metrics: m,
-		rpcType: rpcType,
+		labels:  m.labels.call(ctx, rpcType, fullMethod),
 	}
 
 	if r.metrics.serverHandledHistogramEnabled {
 		r.startTime = time.Now()
 	}
 
-	r.serviceName, r.methodName = splitMethodName(fullMethod)
-	r.metrics.counters[serverStartedCounter].Add(ctx, 1,
-		metric.WithAttributes(
-			append(r.metrics.labels,
-				attribute.String(AttrType, string(r.rpcType)),
-				attribute.String(AttrService, r.serviceName),
-				attribute.String(AttrMethod, r.methodName),
-			)...,
-		),
-	)
+	r.metrics.counters[serverStartedCounter].Add(ctx, 1, r.labels.commonOpt())
 
 	return r
 }
 
 func (r *serverReporter) ReceivedMessage(ctx context.Context) {
-	r.metrics.counters[serverStreamMsgReceived].Add(ctx, 1,
-		metric.WithAttributes(
-			append(r.metrics.labels,
-				attribute.String(AttrType, string(r.rpcType)),
-				attribute.String(AttrService, r.serviceName),
-				attribute.String(AttrMethod, r.methodName),
-			)...,
-		),
-	)
+	r.metrics.counters[serverStreamMsgReceived].Add(ctx, 1, r.labels.commonOpt())
 }
 
 func (r *serverReporter) SentMessage(ctx context.Context) {
-	r.metrics.counters[serverStreamMsgSent].Add(ctx, 1,
-		metric.WithAttributes(
-			append(r.metrics.labels,
-				attribute.String(AttrType, string(r.rpcType)),
-				attribute.String(AttrService, r.serviceName),
-				attribute.String(AttrMethod, r.methodName),
-			)...,
-		),
-	)
+	r.metrics.counters[serverStreamMsgSent].Add(ctx, 1, r.labels.commonOpt())
 }
 
 func (r *serverReporter) Handled(ctx context.Context, code codes.Code) {
-	r.metrics.counters[serverHandledCounter].Add(ctx, 1,
-		metric.WithAttributes(
-			append(r.metrics.labels,
-				attribute.String(AttrType, string(r.rpcType)),
-				attribute.String(AttrService, r.serviceName),
-				attribute.String(AttrMethod, r.methodName),
-				attribute.String(AttrCode, code.String()),
-			)...,
-		),
-	)
+	r.metrics.counters[serverHandledCounter].Add(ctx, 1, r.labels.codeOpt(code))
 
 	if r.metrics.serverHandledHistogramEnabled {
 		dur := float64(time.Since(r.startTime).Seconds())
 
-		r.metrics.valueRecorders[serverHandledHistogram].Record(ctx, dur,
-			metric.WithAttributes(
-				append(r.metrics.labels,
-					attribute.String(AttrType, string(r.rpcType)),
-					attribute.String(AttrService, r.serviceName),
-					attribute.String(AttrMethod, r.methodName),
-				)...,
-			),
-		)
+		r.metrics.valueRecorders[serverHandledHistogram].Record(r.labels.exemplarCtx(ctx), dur, r.labels.commonOpt())
 	}
 }
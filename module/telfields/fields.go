@@ -0,0 +1,131 @@
+// Package telfields propagates allow-listed tel log fields between services,
+// it's shared by nats, http and grpc middlewares, transport specific code only reads and writes carrier.
+package telfields
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+
+	"github.com/tel-io/tel/v2"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// DefaultMaxValue max size of single field value, bytes
+	DefaultMaxValue = 256
+	// DefaultMaxTotal max size of all propagated fields: keys and values, bytes
+	DefaultMaxTotal = 4096
+)
+
+// Propagator writes and reads tel log fields with listed keys via carrier keys Prefix+key.
+// Values are URL-encoded, values longer than MaxValue and fields exceeding MaxTotal are skipped, 0 is no limit.
+type Propagator struct {
+	Keys     []string
+	Prefix   string
+	MaxValue int
+	MaxTotal int
+}
+
+// NewPropagator with default limits and carrier key prefix
+func NewPropagator(prefix string) *Propagator {
+	return &Propagator{
+		Prefix:   prefix,
+		MaxValue: DefaultMaxValue,
+		MaxTotal: DefaultMaxTotal,
+	}
+}
+
+// Inject calls set with carrier key and value of every allowed field recorded by tel of ctx
+func (p *Propagator) Inject(ctx context.Context, set func(key, value string)) {
+	t := tel.ContextValue(ctx)
+	if t == nil {
+		return
+	}
+
+	recorded, ok := Recorded(t.Logger.Core())
+	if !ok {
+		return
+	}
+
+	values := fieldValues(recorded, p.Keys)
+	total := 0
+
+	for _, key := range p.Keys {
+		v, ok := values[key]
+		if !ok {
+			continue
+		}
+
+		v = url.QueryEscape(v)
+		if !p.fits(key, v, &total) {
+			continue
+		}
+
+		set(p.Prefix+key, v)
+	}
+}
+
+// Extract puts allowed fields returned by get for carrier keys to tel of ctx, empty value means no field
+func (p *Propagator) Extract(ctx context.Context, get func(key string) string) {
+	t := tel.ContextValue(ctx)
+	if t == nil {
+		return
+	}
+
+	var (
+		fields []zap.Field
+		total  int
+	)
+
+	for _, key := range p.Keys {
+		v := get(p.Prefix + key)
+		if v == "" || !p.fits(key, v, &total) {
+			continue
+		}
+
+		if val, err := url.QueryUnescape(v); err == nil {
+			fields = append(fields, zap.String(key, val))
+		}
+	}
+
+	if len(fields) > 0 {
+		// recorded, so restored fields are propagated further
+		t.Logger = RecordFields(t.Logger).With(fields...)
+	}
+}
+
+// fits checks limits and counts field size in total
+func (p *Propagator) fits(key, value string, total *int) bool {
+	if p.MaxValue > 0 && len(value) > p.MaxValue {
+		return false
+	}
+
+	size := len(key) + len(value)
+	if p.MaxTotal > 0 && *total+size > p.MaxTotal {
+		return false
+	}
+
+	*total += size
+
+	return true
+}
+
+// fieldValues converts fields with listed keys to string values, the last field wins
+func fieldValues(fields []zapcore.Field, keys []string) map[string]string {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, field := range fields {
+		if slices.Contains(keys, field.Key) {
+			field.AddTo(enc)
+		}
+	}
+
+	res := make(map[string]string, len(enc.Fields))
+	for k, v := range enc.Fields {
+		res[k] = fmt.Sprint(v)
+	}
+
+	return res
+}
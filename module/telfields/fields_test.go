@@ -0,0 +1,83 @@
+package telfields
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tel-io/tel/v2"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRecorded(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+
+	_, ok := Recorded(core)
+	assert.False(t, ok)
+
+	l := RecordFields(zap.New(core)).With(zap.String("order_id", "42"))
+	assert.Same(t, l, RecordFields(l), "already recorded")
+
+	// tel tees logger core on span start
+	tee := zap.New(zapcore.NewTee(l.Core(), zapcore.NewNopCore())).With(zap.Int("n", 1))
+
+	fields, ok := Recorded(tee.Core())
+	require.True(t, ok)
+	assert.Equal(t, []zapcore.Field{zap.String("order_id", "42"), zap.Int("n", 1)}, fields)
+
+	tee.Info("hello")
+	assert.Equal(t, 0, logs.FilterLevelExact(probeLevel).Len(), "probe isn't logged")
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, map[string]any{"order_id": "42", "n": int64(1)}, logs.All()[0].ContextMap())
+}
+
+func TestPropagator(t *testing.T) {
+	producer := tel.NewNull()
+	producer.Logger = RecordFields(producer.Logger).With(
+		zap.String("order_id", "42"),
+		zap.String("tenant", "acme corp"),
+		zap.String("secret", "pass"),
+		zap.String("note", strings.Repeat("x", 10)),
+	)
+
+	p := NewPropagator("f-")
+	p.Keys = []string{"order_id", "tenant", "note"}
+	p.MaxValue = 9
+
+	span, ctx := producer.StartSpan(producer.Ctx(), "test")
+	defer span.End()
+
+	carrier := map[string]string{}
+	p.Inject(ctx, func(key, value string) {
+		carrier[key] = value
+	})
+
+	assert.Equal(t, map[string]string{"f-order_id": "42", "f-tenant": "acme+corp"}, carrier)
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	consumer := tel.NewNull()
+	consumer.Logger = zap.New(core)
+
+	ctx = consumer.Ctx()
+	p.MaxTotal = 20
+	p.Extract(ctx, func(key string) string {
+		return carrier[key]
+	})
+
+	tel.FromCtx(ctx).Info("handler")
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, map[string]any{"order_id": "42"}, logs.All()[0].ContextMap(), "tenant exceeds total limit")
+
+	// restored fields are propagated further
+	fields, ok := Recorded(tel.FromCtx(ctx).Logger.Core())
+	require.True(t, ok)
+	assert.Equal(t, []zapcore.Field{zap.String("order_id", "42")}, fields)
+
+	p.Inject(context.Background(), func(string, string) {
+		t.Error("no tel in ctx")
+	})
+}
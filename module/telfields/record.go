@@ -0,0 +1,78 @@
+package telfields
+
+import (
+	"math"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// probeLevel of entry looking for recordCore, it's below any real level, so other cores don't accept it
+const probeLevel = zapcore.Level(math.MinInt8)
+
+// RecordFields wraps logger core, so fields put to tel afterwards are available for propagation
+func RecordFields(l *zap.Logger) *zap.Logger {
+	if _, ok := Recorded(l.Core()); ok {
+		return l
+	}
+
+	return l.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &recordCore{Core: core}
+	}))
+}
+
+// Recorded returns fields of core wrapped by RecordFields.
+// tel tees logger core on each span start, so recordCore is found via Check with probe entry:
+// tee and wrappers delegating Check pass it to recordCore, which returns its fields on Write of probe field.
+func Recorded(core zapcore.Core) ([]zapcore.Field, bool) {
+	ce := core.Check(zapcore.Entry{Level: probeLevel}, nil)
+	if ce == nil {
+		return nil, false
+	}
+
+	p := &probe{}
+	ce.Write(zapcore.Field{Type: zapcore.SkipType, Interface: p})
+
+	return p.fields, p.found
+}
+
+// probe collects fields of the first recordCore
+type probe struct {
+	fields []zapcore.Field
+	found  bool
+}
+
+type recordCore struct {
+	zapcore.Core
+	fields []zapcore.Field
+}
+
+func (c *recordCore) With(fields []zapcore.Field) zapcore.Core {
+	return &recordCore{
+		Core:   c.Core.With(fields),
+		fields: append(c.fields[:len(c.fields):len(c.fields)], fields...),
+	}
+}
+
+func (c *recordCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level == probeLevel {
+		return ce.AddCore(ent, c)
+	}
+
+	return c.Core.Check(ent, ce)
+}
+
+// Write is called only for probe entry, others are written by wrapped core added to ce by its Check
+func (c *recordCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if ent.Level != probeLevel {
+		return c.Core.Write(ent, fields)
+	}
+
+	for _, f := range fields {
+		if p, ok := f.Interface.(*probe); ok && !p.found {
+			p.fields, p.found = c.fields, true
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package telmsg
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/tel-io/tel/v2"
+)
+
+// Recover turns panic of handler into *err and writes it to log of tel from ctx, stack is printed in debug mode.
+// It calls recover itself, so it must be deferred directly:
+//
+//	defer telmsg.Recover(ctx, &err)
+func Recover(ctx context.Context, err *error) {
+	hasRecovery := recover()
+	if hasRecovery == nil {
+		return
+	}
+
+	//nolint: goerr113
+	*err = fmt.Errorf("recovery info: %+v", hasRecovery)
+
+	tel.FromCtx(ctx).Error("recovery", tel.Error(*err))
+
+	if tel.FromCtx(ctx).IsDebug() {
+		debug.PrintStack()
+	}
+}
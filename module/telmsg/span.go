@@ -0,0 +1,49 @@
+// Package telmsg contains span and recovery helpers shared by message broker middlewares (kafka, amqp),
+// broker specific code only names operations and maps messages to attributes.
+package telmsg
+
+import (
+	"context"
+
+	"github.com/tel-io/tel/v2"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// StartSpan starts span with tracer, tel tracer is used when it's nil, e.g. WithTracerProvider isn't set.
+// Returned ctx carries tel instance with the span the same as tel.StartSpanFromContext does.
+func StartSpan(ctx context.Context, tracer trace.Tracer, name string, opts ...trace.SpanStartOption) (
+	trace.Span, context.Context) {
+	if tracer == nil {
+		return tel.StartSpanFromContext(ctx, name, opts...)
+	}
+
+	t := tel.FromCtx(ctx)
+
+	// continue active span of tel instance
+	if !trace.SpanContextFromContext(ctx).IsValid() && t.Span() != nil && t.Span().IsRecording() {
+		ctx = trace.ContextWithSpan(ctx, t.Span())
+	}
+
+	ctx, span := tracer.Start(ctx, name, opts...)
+
+	tele := t.WithSpan(span)
+	tele.PutSpan(span)
+
+	ctx = tel.WrapContext(ctx, tele)
+	tel.UpdateTraceFields(ctx)
+
+	return span, ctx
+}
+
+// SetStatus of span by result of handler, error is recorded as span event
+func SetStatus(span trace.Span, err error) {
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+
+		return
+	}
+
+	span.SetStatus(codes.Ok, "")
+}
@@ -0,0 +1,70 @@
+package telmsg
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tel-io/tel/v2"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestStartSpan(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)).Tracer("test")
+
+	ctx := tel.WithContext(context.Background(), tel.NewNull())
+
+	parent, ctx := StartSpan(ctx, tracer, "parent")
+	child, cctx := StartSpan(ctx, tracer, "child")
+
+	assert.Same(t, child, tel.FromCtx(cctx).Span(), "tel of ctx carries the span")
+
+	SetStatus(child, fmt.Errorf("broken"))
+	child.End()
+	SetStatus(parent, nil)
+	parent.End()
+
+	spans := rec.Ended()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, spans[1].SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Len(t, spans[0].Events(), 1, "error is recorded")
+	assert.Equal(t, codes.Ok, spans[1].Status().Code)
+}
+
+func TestRecover(t *testing.T) {
+	var buf bytes.Buffer
+
+	tele := tel.NewNull()
+	tele.Logger = zap.New(zapcore.NewCore(
+		zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(&buf), zapcore.DebugLevel,
+	))
+
+	ctx := tel.WithContext(context.Background(), tele)
+
+	handle := func() (err error) {
+		defer Recover(ctx, &err)
+
+		panic("boom")
+	}
+
+	assert.EqualError(t, handle(), "recovery info: boom")
+	assert.Contains(t, buf.String(), "recovery")
+
+	handle = func() (err error) {
+		defer Recover(ctx, &err)
+
+		return fmt.Errorf("broken")
+	}
+
+	assert.EqualError(t, handle(), "broken", "error of handler is kept")
+}
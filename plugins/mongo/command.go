@@ -0,0 +1,154 @@
+package mongo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// CommandFormatter formats command document for logs
+type CommandFormatter func(cmd bson.Raw) string
+
+// driverFields are added by driver to every command, they don't describe the operation
+var driverFields = map[string]bool{
+	"lsid":         true,
+	"$clusterTime": true,
+	"$db":          true,
+}
+
+// RawCommand formats command as relaxed extended JSON with all values
+func RawCommand(cmd bson.Raw) string {
+	b, _ := bson.MarshalExtJSON(cmd, false, false)
+
+	return string(b)
+}
+
+// SanitizeCommand keeps field names, operators and collection of command, replaces literal values with '?':
+// {"find": "users", "filter": {"age": {"$gt": 30}}} -> {"find":"users","filter":{"age":{"$gt":?}}}.
+// Session fields added by driver are omitted.
+func SanitizeCommand(cmd bson.Raw) string {
+	elems, err := cmd.Elements()
+	if err != nil {
+		return ""
+	}
+
+	var b strings.Builder
+
+	b.WriteByte('{')
+
+	first := true
+
+	for i, elt := range elems {
+		key := elt.Key()
+		if driverFields[key] {
+			continue
+		}
+
+		if !first {
+			b.WriteByte(',')
+		}
+
+		first = false
+
+		b.WriteString(strconv.Quote(key))
+		b.WriteByte(':')
+
+		// the first element of command is "<command>": "<collection>"
+		if v := elt.Value(); i == 0 && v.Type == bsontype.String {
+			b.WriteString(strconv.Quote(v.StringValue()))
+			continue
+		}
+
+		writeSanitized(&b, elt.Value())
+	}
+
+	b.WriteByte('}')
+
+	return b.String()
+}
+
+// writeSanitized value: documents and arrays keep structure, literals are replaced with '?'
+func writeSanitized(b *strings.Builder, v bson.RawValue) {
+	switch v.Type {
+	case bsontype.EmbeddedDocument:
+		elems, _ := v.Document().Elements()
+
+		b.WriteByte('{')
+
+		for i, elt := range elems {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+
+			b.WriteString(strconv.Quote(elt.Key()))
+			b.WriteByte(':')
+			writeSanitized(b, elt.Value())
+		}
+
+		b.WriteByte('}')
+	case bsontype.Array:
+		values, _ := v.Array().Values()
+
+		b.WriteByte('[')
+
+		for i, item := range values {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+
+			writeSanitized(b, item)
+		}
+
+		b.WriteByte(']')
+	default:
+		b.WriteByte('?')
+	}
+}
+
+// truncate s to size bytes keeping utf8 runes
+func truncate(s string, size int) string {
+	if size <= 0 || len(s) <= size {
+		return s
+	}
+
+	l := size
+	for l > 0 && !utf8.RuneStart(s[l]) {
+		l--
+	}
+
+	return fmt.Sprintf("%s (truncated %d bytes)", s[:l], len(s)-l)
+}
+
+// collectionOf command: the first element of CRUD command document is "<command>": "<collection>",
+// empty for database level commands
+func collectionOf(evt *event.CommandStartedEvent) string {
+	elt, err := evt.Command.IndexErr(0)
+	if err != nil {
+		return ""
+	}
+
+	if key, err := elt.KeyErr(); err != nil || key != evt.CommandName {
+		return ""
+	}
+
+	v, err := elt.ValueErr()
+	if err != nil || v.Type != bsontype.String {
+		return ""
+	}
+
+	return v.StringValue()
+}
+
+// serverAddress of connection: driver formats connection id as "host:port[-N]"
+func serverAddress(connectionID string) string {
+	if i := strings.IndexByte(connectionID, '['); i >= 0 {
+		return connectionID[:i]
+	}
+
+	return connectionID
+}
@@ -1,13 +1,35 @@
 package mongo
 
 import (
+	"time"
+
 	"github.com/tel-io/tel/v2"
+	"go.mongodb.org/mongo-driver/event"
 	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	// DefaultMaxStatementSize of command in logs, bytes
+	DefaultMaxStatementSize = 1024
 )
 
 type config struct {
 	log       *tel.Telemetry
 	otelmongo []otelmongo.Option
+
+	meterProvider metric.MeterProvider
+
+	// command of failure and slow operation logs, nil disables it
+	commandFormatter CommandFormatter
+	maxStatementSize int
+
+	// succeeded commands longer than threshold are logged, 0 disables it
+	slowThreshold time.Duration
+
+	// user monitors chained after plugin ones
+	commandMonitors []*event.CommandMonitor
+	poolMonitors    []*event.PoolMonitor
 }
 
 // Option interface used for setting optional config properties.
@@ -26,7 +48,12 @@ func newConfig(opts ...Option) *config {
 	l := tel.Global()
 
 	c := &config{
-		log: &l,
+		log:              &l,
+		meterProvider:    l.MetricProvider(),
+		commandFormatter: SanitizeCommand,
+		maxStatementSize: DefaultMaxStatementSize,
+		// command documents with user data are not put into spans
+		otelmongo: []otelmongo.Option{otelmongo.WithCommandAttributeDisabled(true)},
 	}
 
 	for _, opt := range opts {
@@ -40,16 +67,65 @@ func newConfig(opts ...Option) *config {
 func WithTel(t *tel.Telemetry) Option {
 	return optionFunc(func(c *config) {
 		c.log = t
+		c.meterProvider = t.MetricProvider()
 
 		c.otelmongo = append(c.otelmongo,
-			//otelmongo.WithMeterProvider(t.MetricProvider()),
 			otelmongo.WithTracerProvider(t.TracerProvider()),
 		)
 	})
 }
 
+// WithMeterProvider sets meter provider, put it after WithTel
+func WithMeterProvider(p metric.MeterProvider) Option {
+	return optionFunc(func(c *config) {
+		c.meterProvider = p
+	})
+}
+
+// WithOtelConf passes options to otelmongo monitor.
+// Command attribute of spans is disabled by default, otelmongo.WithCommandAttributeDisabled(false) enables it
 func WithOtelConf(opt ...otelmongo.Option) Option {
 	return optionFunc(func(c *config) {
 		c.otelmongo = append(c.otelmongo, opt...)
 	})
 }
+
+// WithCommandFormatter formats command of failure and slow operation logs, nil disables it.
+// RawCommand writes command with all values.
+// Default: SanitizeCommand
+func WithCommandFormatter(fn CommandFormatter) Option {
+	return optionFunc(func(c *config) {
+		c.commandFormatter = fn
+	})
+}
+
+// WithMaxStatementSize truncates formatted command, 0 means no limit
+// Default: DefaultMaxStatementSize
+func WithMaxStatementSize(size int) Option {
+	return optionFunc(func(c *config) {
+		c.maxStatementSize = size
+	})
+}
+
+// WithSlowThreshold logs succeeded commands lasting longer than d with warn level, 0 disables it
+func WithSlowThreshold(d time.Duration) Option {
+	return optionFunc(func(c *config) {
+		c.slowThreshold = d
+	})
+}
+
+// WithCommandMonitor chains user monitor after plugin ones,
+// monitor already set to client options is chained by Inject as well
+func WithCommandMonitor(m *event.CommandMonitor) Option {
+	return optionFunc(func(c *config) {
+		c.commandMonitors = append(c.commandMonitors, m)
+	})
+}
+
+// WithPoolMonitor chains user monitor after plugin ones,
+// monitor already set to client options is chained by Inject as well
+func WithPoolMonitor(m *event.PoolMonitor) Option {
+	return optionFunc(func(c *config) {
+		c.poolMonitors = append(c.poolMonitors, m)
+	})
+}
@@ -1,12 +1,88 @@
 package mongo
 
 import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
 )
 
+const instrumentationName = "github.com/tel-io/instrumentation/plugins/mongo"
+
+// Inject command monitor with traces, metrics and failure logs and pool monitor with connection pool metrics.
+// Monitors already set to client options are chained after plugin ones.
 func Inject(c *options.ClientOptions, opts ...Option) {
 	cfg := newConfig(opts...)
 
-	c.Monitor = otelmongo.NewMonitor(cfg.otelmongo...)
+	c.Monitor = ChainCommandMonitors(append([]*event.CommandMonitor{
+		otelmongo.NewMonitor(cfg.otelmongo...),
+		newCommandMonitor(cfg).monitor(),
+		c.Monitor,
+	}, cfg.commandMonitors...)...)
+
+	c.PoolMonitor = ChainPoolMonitors(append([]*event.PoolMonitor{
+		newPoolMonitor(cfg).monitor(),
+		c.PoolMonitor,
+	}, cfg.poolMonitors...)...)
+}
+
+// ChainCommandMonitors calls monitors in order, nil monitors and callbacks are skipped
+func ChainCommandMonitors(list ...*event.CommandMonitor) *event.CommandMonitor {
+	var started, succeeded, failed []*event.CommandMonitor
+
+	for _, m := range list {
+		if m == nil {
+			continue
+		}
+
+		if m.Started != nil {
+			started = append(started, m)
+		}
+
+		if m.Succeeded != nil {
+			succeeded = append(succeeded, m)
+		}
+
+		if m.Failed != nil {
+			failed = append(failed, m)
+		}
+	}
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+			for _, m := range started {
+				m.Started(ctx, evt)
+			}
+		},
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			for _, m := range succeeded {
+				m.Succeeded(ctx, evt)
+			}
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			for _, m := range failed {
+				m.Failed(ctx, evt)
+			}
+		},
+	}
+}
+
+// ChainPoolMonitors calls monitors in order, nil monitors and callbacks are skipped
+func ChainPoolMonitors(list ...*event.PoolMonitor) *event.PoolMonitor {
+	var res []*event.PoolMonitor
+
+	for _, m := range list {
+		if m != nil && m.Event != nil {
+			res = append(res, m)
+		}
+	}
+
+	return &event.PoolMonitor{
+		Event: func(evt *event.PoolEvent) {
+			for _, m := range res {
+				m.Event(evt)
+			}
+		},
+	}
 }
@@ -0,0 +1,381 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/tel-io/tel/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+const addr = "localhost:27017"
+
+type Suite struct {
+	suite.Suite
+
+	opts   *options.ClientOptions
+	reader *sdkmetric.ManualReader
+	logs   *observer.ObservedLogs
+}
+
+func (s *Suite) SetupTest() {
+	s.setup()
+}
+
+func TestMongo(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+// setup injects plugin with opts to new client options
+func (s *Suite) setup(opts ...Option) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	tele := tel.NewNull()
+	tele.Logger = zap.New(core)
+
+	s.opts = options.Client()
+	s.reader = sdkmetric.NewManualReader()
+	s.logs = logs
+
+	Inject(s.opts, append([]Option{
+		WithTel(&tele),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(s.reader))),
+	}, opts...)...)
+}
+
+// command runs started and finished events of command, failure is empty for succeeded one
+func (s *Suite) command(requestID int64, doc bson.D, failure string) {
+	raw, err := bson.Marshal(doc)
+	s.Require().NoError(err)
+
+	ctx := context.Background()
+
+	s.opts.Monitor.Started(ctx, &event.CommandStartedEvent{
+		Command:      raw,
+		DatabaseName: "shop",
+		CommandName:  doc[0].Key,
+		RequestID:    requestID,
+		ConnectionID: addr + "[-1]",
+	})
+
+	finished := event.CommandFinishedEvent{
+		DurationNanos: int64(5 * time.Millisecond),
+		CommandName:   doc[0].Key,
+		RequestID:     requestID,
+		ConnectionID:  addr + "[-1]",
+	}
+
+	if failure != "" {
+		s.opts.Monitor.Failed(ctx, &event.CommandFailedEvent{CommandFinishedEvent: finished, Failure: failure})
+	} else {
+		s.opts.Monitor.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: finished})
+	}
+}
+
+func (s *Suite) collect(name string) metricdata.Aggregation {
+	var rm metricdata.ResourceMetrics
+	s.Require().NoError(s.reader.Collect(context.Background(), &rm))
+
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				return m.Data
+			}
+		}
+	}
+
+	return nil
+}
+
+// value of counter or gauge data points with attributes
+func (s *Suite) value(name string, attrs ...attribute.KeyValue) int64 {
+	var points []metricdata.DataPoint[int64]
+
+	switch data := s.collect(name).(type) {
+	case metricdata.Sum[int64]:
+		points = data.DataPoints
+	case metricdata.Gauge[int64]:
+		points = data.DataPoints
+	}
+
+	var res int64
+
+	for _, dp := range points {
+		if hasAttrs(dp.Attributes, attrs) {
+			res += dp.Value
+		}
+	}
+
+	return res
+}
+
+func hasAttrs(set attribute.Set, attrs []attribute.KeyValue) bool {
+	for _, kv := range attrs {
+		if v, ok := set.Value(kv.Key); !ok || v != kv.Value {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *Suite) TestCommandMonitor() {
+
+	s.command(1, bson.D{{Key: "find", Value: "users"}, {Key: "filter", Value: bson.D{{Key: "name", Value: "bob"}}}}, "")
+	s.command(2, bson.D{{Key: "insert", Value: "users"}}, "E11000 duplicate key error")
+	s.command(3, bson.D{{Key: "ping", Value: 1}}, "")
+
+	find := []attribute.KeyValue{semconv.DBOperationName("find"), semconv.DBCollectionName("users")}
+	s.Equal(int64(1), s.value(dbMongoClientCalls, append(find, dbMongoStatusOK)...))
+	s.Equal(int64(0), s.value(dbMongoClientErrors, find...))
+
+	insert := []attribute.KeyValue{semconv.DBOperationName("insert"), semconv.DBCollectionName("users")}
+	s.Equal(int64(1), s.value(dbMongoClientCalls, append(insert, dbMongoStatusERROR)...))
+	s.Equal(int64(1), s.value(dbMongoClientErrors, insert...))
+
+	s.Equal(int64(1), s.value(dbMongoClientCalls, semconv.DBOperationName("ping"), semconv.DBCollectionName("")))
+
+	latency, ok := s.collect(dbMongoClientLatencyMs).(metricdata.Histogram[float64])
+	s.Require().True(ok)
+	s.Require().Len(latency.DataPoints, 3)
+	s.Equal(5.0, latency.DataPoints[0].Sum)
+
+	entries := s.logs.All()
+	s.Require().Len(entries, 1)
+	s.Equal("mongo:insert", entries[0].Message)
+	s.Equal(zapcore.ErrorLevel, entries[0].Level)
+	s.Equal("users", entries[0].ContextMap()["collection"])
+	s.Equal(`{"insert":"users"}`, entries[0].ContextMap()["command"])
+
+	s.Run("without command", func() {
+		s.setup(WithCommandFormatter(nil))
+
+		s.command(1, bson.D{{Key: "insert", Value: "users"}}, "failure")
+
+		s.Require().Equal(1, s.logs.Len())
+		s.NotContains(s.logs.All()[0].ContextMap(), "command")
+	})
+}
+
+func (s *Suite) TestChainCommandMonitors() {
+	var calls []string
+
+	user := &event.CommandMonitor{
+		Started: func(context.Context, *event.CommandStartedEvent) {
+			calls = append(calls, "started")
+		},
+		Failed: func(context.Context, *event.CommandFailedEvent) {
+			calls = append(calls, "failed")
+		},
+	}
+
+	s.Run("client options", func() {
+		calls = nil
+
+		opts := options.Client().SetMonitor(user)
+		Inject(opts, WithMeterProvider(sdkmetric.NewMeterProvider()))
+
+		s.opts = opts
+		s.command(1, bson.D{{Key: "ping", Value: 1}}, "")
+
+		s.Equal([]string{"started"}, calls)
+	})
+
+	s.Run("option", func() {
+		calls = nil
+
+		s.setup(WithCommandMonitor(user), WithCommandMonitor(nil))
+		s.command(1, bson.D{{Key: "ping", Value: 1}}, "failure")
+
+		s.Equal([]string{"started", "failed"}, calls)
+	})
+}
+
+func (s *Suite) TestPoolMonitor() {
+	var userEvents int
+
+	s.setup(WithPoolMonitor(&event.PoolMonitor{Event: func(*event.PoolEvent) { userEvents++ }}))
+
+	for _, typ := range []string{
+		event.ConnectionCreated, event.ConnectionCreated,
+		event.GetStarted, event.GetSucceeded,
+		event.GetStarted, event.GetSucceeded,
+		event.ConnectionReturned,
+		event.GetStarted, event.GetFailed,
+	} {
+		s.opts.PoolMonitor.Event(&event.PoolEvent{Type: typ, Address: addr, Reason: event.ReasonTimedOut})
+	}
+
+	s.opts.PoolMonitor.Event(&event.PoolEvent{Type: event.ConnectionClosed, Address: addr, Reason: event.ReasonIdle})
+
+	server := semconv.ServerAddress(addr)
+
+	s.Equal(int64(1), s.value(dbMongoPoolCheckedOut, server))
+	s.Equal(int64(1), s.value(dbMongoPoolOpen, server))
+	s.Equal(int64(2), s.value(dbMongoPoolCreated, server))
+	s.Equal(int64(1), s.value(dbMongoPoolClosed, server, dbMongoPoolReason.String(event.ReasonIdle)))
+	s.Equal(int64(1), s.value(dbMongoPoolCheckoutFail, server, dbMongoPoolReason.String(event.ReasonTimedOut)))
+
+	wait, ok := s.collect(dbMongoPoolWaitMs).(metricdata.Histogram[float64])
+	s.Require().True(ok)
+	s.Require().Len(wait.DataPoints, 1)
+	s.Equal(uint64(3), wait.DataPoints[0].Count)
+
+	s.Equal(10, userEvents)
+
+	// connections are closed and returned after pool is closed
+	for _, typ := range []string{event.PoolClosedEvent, event.ConnectionReturned, event.ConnectionClosed} {
+		s.opts.PoolMonitor.Event(&event.PoolEvent{Type: typ, Address: addr, Reason: event.ReasonPoolClosed})
+	}
+
+	open, _ := s.collect(dbMongoPoolOpen).(metricdata.Gauge[int64])
+	s.Empty(open.DataPoints, "closed pool is not reported")
+	s.Equal(int64(1), s.value(dbMongoPoolClosed, server, dbMongoPoolReason.String(event.ReasonPoolClosed)))
+
+	for _, typ := range []string{event.PoolCreated, event.ConnectionCreated} {
+		s.opts.PoolMonitor.Event(&event.PoolEvent{Type: typ, Address: addr})
+	}
+
+	s.Equal(int64(1), s.value(dbMongoPoolOpen, server), "pool is created again")
+	s.Equal(int64(0), s.value(dbMongoPoolCheckedOut, server))
+}
+
+func (s *Suite) TestPoolMonitor_PendingCheckoutsLimit() {
+
+	for _, typ := range []string{event.GetStarted, event.GetSucceeded} {
+		for i := 0; i < maxPendingCheckouts+10; i++ {
+			s.opts.PoolMonitor.Event(&event.PoolEvent{Type: typ, Address: addr})
+		}
+	}
+
+	wait, ok := s.collect(dbMongoPoolWaitMs).(metricdata.Histogram[float64])
+	s.Require().True(ok)
+	s.Require().Len(wait.DataPoints, 1)
+	s.Equal(uint64(maxPendingCheckouts), wait.DataPoints[0].Count, "checkouts over limit aren't measured")
+}
+
+func (s *Suite) TestSanitizeCommand() {
+	tests := []struct {
+		name string
+		doc  bson.D
+		want string
+	}{
+		{
+			name: "operators",
+			doc: bson.D{
+				{Key: "find", Value: "users"},
+				{Key: "filter", Value: bson.D{
+					{Key: "age", Value: bson.D{{Key: "$gt", Value: 30}}},
+					{Key: "name", Value: "bob"},
+				}},
+				{Key: "limit", Value: 10},
+			},
+			want: `{"find":"users","filter":{"age":{"$gt":?},"name":?},"limit":?}`,
+		},
+		{
+			name: "arrays",
+			doc: bson.D{
+				{Key: "insert", Value: "users"},
+				{Key: "documents", Value: bson.A{
+					bson.D{{Key: "name", Value: "bob"}, {Key: "tags", Value: bson.A{"a", "b"}}},
+				}},
+			},
+			want: `{"insert":"users","documents":[{"name":?,"tags":[?,?]}]}`,
+		},
+		{
+			name: "driver fields",
+			doc: bson.D{
+				{Key: "ping", Value: 1},
+				{Key: "lsid", Value: bson.D{{Key: "id", Value: "session"}}},
+				{Key: "$db", Value: "shop"},
+			},
+			want: `{"ping":?}`,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			raw, err := bson.Marshal(tt.doc)
+			s.Require().NoError(err)
+
+			s.Equal(tt.want, SanitizeCommand(raw))
+		})
+	}
+}
+
+func (s *Suite) TestTruncate() {
+	s.Equal("abc", truncate("abc", 0))
+	s.Equal("abc", truncate("abc", 3))
+	s.Equal("ab (truncated 1 bytes)", truncate("abc", 2))
+	s.Equal("a (truncated 2 bytes)", truncate("aя", 2), "rune is not split")
+}
+
+func (s *Suite) TestSlowThreshold() {
+	s.setup(WithSlowThreshold(5*time.Millisecond), WithMaxStatementSize(20))
+
+	s.command(1, bson.D{{Key: "find", Value: "users"}, {Key: "filter", Value: bson.D{{Key: "name", Value: "bob"}}}}, "")
+
+	entries := s.logs.All()
+	s.Require().Len(entries, 1)
+	s.Equal("mongo:find slow", entries[0].Message)
+	s.Equal(zapcore.WarnLevel, entries[0].Level)
+
+	fields := entries[0].ContextMap()
+	s.Equal("users", fields["collection"])
+	s.Equal(addr, fields["address"])
+	s.Equal(5*time.Millisecond, fields["duration"])
+	s.Equal(`{"find":"users","fil (truncated 16 bytes)`, fields["command"])
+
+	s.Run("fast", func() {
+		s.setup(WithSlowThreshold(time.Second))
+
+		s.command(1, bson.D{{Key: "find", Value: "users"}}, "")
+
+		s.Zero(s.logs.Len())
+	})
+}
+
+func (s *Suite) TestCommandFormattedOnlyWhenLogged() {
+	var formatted int
+
+	s.setup(WithCommandFormatter(func(cmd bson.Raw) string {
+		formatted++
+		return SanitizeCommand(cmd)
+	}))
+
+	s.command(1, bson.D{{Key: "find", Value: "users"}}, "")
+	s.Zero(formatted, "succeeded command without slow threshold is never logged")
+
+	s.command(2, bson.D{{Key: "find", Value: "users"}}, "boom")
+	s.Equal(1, formatted)
+	s.Equal(`{"find":"users"}`, s.logs.All()[0].ContextMap()["command"])
+}
+
+func (s *Suite) TestCommandAttribute() {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+
+	s.setup(WithOtelConf(otelmongo.WithTracerProvider(tp)))
+	s.command(1, bson.D{{Key: "find", Value: "users"}, {Key: "filter", Value: bson.D{{Key: "name", Value: "bob"}}}}, "")
+
+	spans := sr.Ended()
+	s.Require().Len(spans, 1)
+	s.Equal("users.find", spans[0].Name())
+
+	for _, kv := range spans[0].Attributes() {
+		s.NotContains(kv.Value.Emit(), "bob", kv.Key)
+	}
+}
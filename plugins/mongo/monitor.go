@@ -0,0 +1,185 @@
+package mongo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tel-io/tel/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.uber.org/zap"
+)
+
+const (
+	dbMongoClientLatencyMs = "db.mongo.client.latency"
+	dbMongoClientCalls     = "db.mongo.client.calls"
+	dbMongoClientErrors    = "db.mongo.client.errors"
+)
+
+const (
+	// dbMongoStatus is status of command: OK, ERROR
+	dbMongoStatus = attribute.Key("db.mongo.status")
+)
+
+var (
+	dbMongoStatusOK    = dbMongoStatus.String("OK")
+	dbMongoStatusERROR = dbMongoStatus.String("ERROR")
+)
+
+type commandKey struct {
+	connectionID string
+	requestID    int64
+}
+
+// startedCommand keeps data of started event required by finished one
+type startedCommand struct {
+	database   string
+	collection string
+	// command is formatted only when it's logged, driver passes own copy of command to every event
+	command bson.Raw
+}
+
+// commandMonitor records latency, calls and errors of commands by collection and command,
+// logs failures and slow commands
+type commandMonitor struct {
+	cfg *config
+
+	latency metric.Float64Histogram
+	calls   metric.Int64Counter
+	errors  metric.Int64Counter
+
+	mx      sync.Mutex
+	started map[commandKey]startedCommand
+}
+
+func newCommandMonitor(cfg *config) *commandMonitor {
+	meter := cfg.meterProvider.Meter(instrumentationName)
+
+	latency, err := meter.Float64Histogram(dbMongoClientLatencyMs,
+		metric.WithUnit("ms"),
+		metric.WithDescription(`The distribution of latencies of commands in milliseconds`),
+	)
+	if err != nil {
+		cfg.log.Panic("mongo plugin", tel.String("key", dbMongoClientLatencyMs))
+	}
+
+	calls, err := meter.Int64Counter(dbMongoClientCalls,
+		metric.WithUnit("1"),
+		metric.WithDescription(`The number of calls of commands`),
+	)
+	if err != nil {
+		cfg.log.Panic("mongo plugin", tel.String("key", dbMongoClientCalls))
+	}
+
+	errs, err := meter.Int64Counter(dbMongoClientErrors,
+		metric.WithUnit("1"),
+		metric.WithDescription(`The number of failed calls of commands`),
+	)
+	if err != nil {
+		cfg.log.Panic("mongo plugin", tel.String("key", dbMongoClientErrors))
+	}
+
+	return &commandMonitor{
+		cfg:     cfg,
+		latency: latency,
+		calls:   calls,
+		errors:  errs,
+		started: make(map[commandKey]startedCommand),
+	}
+}
+
+func (m *commandMonitor) monitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: m.Started,
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			m.finished(ctx, &evt.CommandFinishedEvent, "")
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			m.finished(ctx, &evt.CommandFinishedEvent, evt.Failure)
+		},
+	}
+}
+
+func (m *commandMonitor) Started(_ context.Context, evt *event.CommandStartedEvent) {
+	s := startedCommand{
+		database:   evt.DatabaseName,
+		collection: collectionOf(evt),
+	}
+
+	if m.cfg.commandFormatter != nil {
+		s.command = evt.Command
+	}
+
+	m.mx.Lock()
+	m.started[commandKey{connectionID: evt.ConnectionID, requestID: evt.RequestID}] = s
+	m.mx.Unlock()
+}
+
+// finished records command, failure is empty for succeeded one
+func (m *commandMonitor) finished(ctx context.Context, evt *event.CommandFinishedEvent, failure string) {
+	key := commandKey{connectionID: evt.ConnectionID, requestID: evt.RequestID}
+
+	m.mx.Lock()
+	s, ok := m.started[key]
+	delete(m.started, key)
+	m.mx.Unlock()
+
+	if !ok {
+		return
+	}
+
+	duration := time.Duration(evt.DurationNanos)
+
+	attrs := []attribute.KeyValue{
+		semconv.DBOperationName(evt.CommandName),
+		semconv.DBCollectionName(s.collection),
+		semconv.DBNamespace(s.database),
+		dbMongoStatusOK,
+	}
+
+	if failure != "" {
+		attrs[len(attrs)-1] = dbMongoStatusERROR
+		m.errors.Add(ctx, 1, metric.WithAttributes(attrs...))
+	}
+
+	m.calls.Add(ctx, 1, metric.WithAttributes(attrs...))
+	m.latency.Record(ctx, float64(duration.Nanoseconds())/1e6, metric.WithAttributes(attrs...))
+
+	slow := m.cfg.slowThreshold > 0 && duration >= m.cfg.slowThreshold
+	if failure == "" && !slow {
+		return
+	}
+
+	fields := []zap.Field{
+		tel.String("database", s.database),
+		tel.String("collection", s.collection),
+		tel.String("address", serverAddress(evt.ConnectionID)),
+		tel.Duration("duration", duration),
+	}
+
+	if s.command != nil {
+		if cmd := truncate(m.cfg.commandFormatter(s.command), m.cfg.maxStatementSize); cmd != "" {
+			fields = append(fields, tel.String("command", cmd))
+		}
+	}
+
+	if failure != "" {
+		m.logger(ctx).Error("mongo:"+evt.CommandName, append(fields, tel.String("failure", failure))...)
+		return
+	}
+
+	m.logger(ctx).Warn("mongo:"+evt.CommandName+" slow", fields...)
+}
+
+// logger of ctx, tel of plugin otherwise
+func (m *commandMonitor) logger(ctx context.Context) *tel.Telemetry {
+	if t := tel.ContextValue(ctx); t != nil {
+		return t
+	}
+
+	return m.cfg.log
+}
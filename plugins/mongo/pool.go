@@ -0,0 +1,190 @@
+package mongo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tel-io/tel/v2"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const (
+	dbMongoPoolCheckedOut   = "db.mongo.pool.connections.checked_out"
+	dbMongoPoolOpen         = "db.mongo.pool.connections.open"
+	dbMongoPoolCreated      = "db.mongo.pool.connections.created"
+	dbMongoPoolClosed       = "db.mongo.pool.connections.closed"
+	dbMongoPoolCheckoutFail = "db.mongo.pool.checkout.failed"
+	dbMongoPoolWaitMs       = "db.mongo.pool.wait.duration"
+)
+
+// dbMongoPoolReason is reason of closed connection or failed checkout
+const dbMongoPoolReason = attribute.Key("db.mongo.pool.reason")
+
+// maxPendingCheckouts bounds start times of pending checkouts per pool, checkouts over it aren't measured
+const maxPendingCheckouts = 1024
+
+// poolStat of server pool
+type poolStat struct {
+	checkedOut int64
+	open       int64
+
+	// start time of pending checkouts, they are paired with finished checkouts in order:
+	// pool events don't identify checkout, so wait time of concurrent checkouts is approximate
+	waiting []time.Time
+}
+
+// poolMonitor records connection pool metrics per server address
+type poolMonitor struct {
+	created      metric.Int64Counter
+	closed       metric.Int64Counter
+	checkoutFail metric.Int64Counter
+	wait         metric.Float64Histogram
+
+	mx    sync.Mutex
+	pools map[string]*poolStat
+	// addresses of closed pools until pool is created again
+	closedPools map[string]struct{}
+}
+
+// nolint: funlen
+func newPoolMonitor(cfg *config) *poolMonitor {
+	meter := cfg.meterProvider.Meter(instrumentationName)
+
+	m := &poolMonitor{pools: make(map[string]*poolStat), closedPools: make(map[string]struct{})}
+
+	var err error
+
+	if m.created, err = meter.Int64Counter(dbMongoPoolCreated,
+		metric.WithUnit("1"),
+		metric.WithDescription("The number of created connections"),
+	); err != nil {
+		cfg.log.Panic("mongo plugin", tel.String("key", dbMongoPoolCreated))
+	}
+
+	if m.closed, err = meter.Int64Counter(dbMongoPoolClosed,
+		metric.WithUnit("1"),
+		metric.WithDescription("The number of closed connections by reason"),
+	); err != nil {
+		cfg.log.Panic("mongo plugin", tel.String("key", dbMongoPoolClosed))
+	}
+
+	if m.checkoutFail, err = meter.Int64Counter(dbMongoPoolCheckoutFail,
+		metric.WithUnit("1"),
+		metric.WithDescription("The number of failed connection checkouts by reason"),
+	); err != nil {
+		cfg.log.Panic("mongo plugin", tel.String("key", dbMongoPoolCheckoutFail))
+	}
+
+	if m.wait, err = meter.Float64Histogram(dbMongoPoolWaitMs,
+		metric.WithUnit("ms"),
+		metric.WithDescription("The distribution of connection checkout wait time in milliseconds"),
+	); err != nil {
+		cfg.log.Panic("mongo plugin", tel.String("key", dbMongoPoolWaitMs))
+	}
+
+	checkedOut, err := meter.Int64ObservableGauge(dbMongoPoolCheckedOut,
+		metric.WithUnit("1"),
+		metric.WithDescription("Count of connections checked out of the pool"),
+	)
+	if err != nil {
+		cfg.log.Panic("mongo plugin", tel.String("key", dbMongoPoolCheckedOut))
+	}
+
+	open, err := meter.Int64ObservableGauge(dbMongoPoolOpen,
+		metric.WithUnit("1"),
+		metric.WithDescription("Count of open connections of the pool"),
+	)
+	if err != nil {
+		cfg.log.Panic("mongo plugin", tel.String("key", dbMongoPoolOpen))
+	}
+
+	if _, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		m.mx.Lock()
+		defer m.mx.Unlock()
+
+		for addr, s := range m.pools {
+			attrs := metric.WithAttributes(semconv.ServerAddress(addr))
+
+			o.ObserveInt64(checkedOut, s.checkedOut, attrs)
+			o.ObserveInt64(open, s.open, attrs)
+		}
+
+		return nil
+	}, checkedOut, open); err != nil {
+		cfg.log.Panic("mongo plugin", tel.String("key", dbMongoPoolOpen))
+	}
+
+	return m
+}
+
+func (m *poolMonitor) monitor() *event.PoolMonitor {
+	return &event.PoolMonitor{Event: m.Event}
+}
+
+func (m *poolMonitor) Event(evt *event.PoolEvent) {
+	ctx := context.Background()
+	addr := semconv.ServerAddress(evt.Address)
+
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	s := m.pool(evt.Address)
+
+	switch evt.Type {
+	case event.PoolCreated:
+		delete(m.closedPools, evt.Address)
+	case event.ConnectionCreated:
+		s.open++
+		m.created.Add(ctx, 1, metric.WithAttributes(addr))
+	case event.ConnectionClosed:
+		s.open--
+		m.closed.Add(ctx, 1, metric.WithAttributes(addr, dbMongoPoolReason.String(evt.Reason)))
+	case event.GetStarted:
+		if len(s.waiting) < maxPendingCheckouts {
+			s.waiting = append(s.waiting, time.Now())
+		}
+	case event.GetSucceeded:
+		s.checkedOut++
+		m.waited(ctx, s, addr)
+	case event.GetFailed:
+		m.waited(ctx, s, addr)
+		m.checkoutFail.Add(ctx, 1, metric.WithAttributes(addr, dbMongoPoolReason.String(evt.Reason)))
+	case event.ConnectionReturned:
+		s.checkedOut--
+	case event.PoolClosedEvent:
+		delete(m.pools, evt.Address)
+		m.closedPools[evt.Address] = struct{}{}
+	}
+}
+
+// waited records wait time of the oldest pending checkout
+func (m *poolMonitor) waited(ctx context.Context, s *poolStat, addr attribute.KeyValue) {
+	if len(s.waiting) == 0 {
+		return
+	}
+
+	start := s.waiting[0]
+	s.waiting = s.waiting[1:]
+
+	m.wait.Record(ctx, float64(time.Since(start).Nanoseconds())/1e6, metric.WithAttributes(addr))
+}
+
+// pool returns stat of addr, connections of closed pool are closed and returned after PoolClosedEvent,
+// their events update detached stat, so closed pool isn't reported again
+func (m *poolMonitor) pool(addr string) *poolStat {
+	if _, ok := m.closedPools[addr]; ok {
+		return &poolStat{}
+	}
+
+	s, ok := m.pools[addr]
+	if !ok {
+		s = &poolStat{}
+		m.pools[addr] = s
+	}
+
+	return s
+}
@@ -0,0 +1,17 @@
+package redis
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// dbRedisStatus is status of command: OK, ERROR
+	dbRedisStatus = attribute.Key("db.redis.status")
+	// dbRedisNumCmd is number of commands in pipeline
+	dbRedisNumCmd = attribute.Key("db.redis.num_cmd")
+)
+
+var (
+	dbRedisStatusOK    = dbRedisStatus.String("OK")
+	dbRedisStatusERROR = dbRedisStatus.String("ERROR")
+)
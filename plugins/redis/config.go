@@ -0,0 +1,136 @@
+package redis
+
+import (
+	"time"
+
+	"github.com/tel-io/tel/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// DefaultMaxStatementSize of db.query.text attribute and log field, bytes
+	DefaultMaxStatementSize = 256
+
+	// defaultMinimumReadStatsInterval is the default minimum interval between calls to PoolStats().
+	defaultMinimumReadStatsInterval = time.Second
+)
+
+type config struct {
+	tele *tel.Telemetry
+
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+
+	allowRoot     bool
+	nameFormatter NameFormatter
+
+	// statement formats command args, nil disables statement
+	statement        StatementFn
+	maxStatementSize int
+
+	defaultAttributes []attribute.KeyValue
+
+	minimumReadStatsInterval time.Duration
+}
+
+// Option interface used for setting optional config properties.
+type Option interface {
+	apply(*config)
+}
+
+type optionFunc func(*config)
+
+func (o optionFunc) apply(c *config) {
+	o(c)
+}
+
+// newConfig creates a new config struct and applies opts to it.
+func newConfig(opts ...Option) *config {
+	l := tel.Global()
+
+	c := &config{
+		tele:                     &l,
+		tracerProvider:           l.TracerProvider(),
+		meterProvider:            l.MetricProvider(),
+		nameFormatter:            formatSpanName,
+		statement:                SanitizeArgs,
+		maxStatementSize:         DefaultMaxStatementSize,
+		minimumReadStatsInterval: defaultMinimumReadStatsInterval,
+	}
+
+	for _, opt := range opts {
+		opt.apply(c)
+	}
+
+	return c
+}
+
+// WithTel also add options to pass own metric and trace provider
+func WithTel(t *tel.Telemetry) Option {
+	return optionFunc(func(c *config) {
+		c.tele = t
+		c.tracerProvider = t.TracerProvider()
+		c.meterProvider = t.MetricProvider()
+	})
+}
+
+// WithTracerProvider sets tracer provider, put it after WithTel
+func WithTracerProvider(p trace.TracerProvider) Option {
+	return optionFunc(func(c *config) {
+		c.tracerProvider = p
+	})
+}
+
+// WithMeterProvider sets meter provider, put it after WithTel
+func WithMeterProvider(p metric.MeterProvider) Option {
+	return optionFunc(func(c *config) {
+		c.meterProvider = p
+	})
+}
+
+// WithTraceRoot create trace if no parent span occurred
+func WithTraceRoot(enable bool) Option {
+	return optionFunc(func(c *config) {
+		c.allowRoot = enable
+	})
+}
+
+// WithNameFormatter of span names and log messages
+func WithNameFormatter(fn NameFormatter) Option {
+	return optionFunc(func(c *config) {
+		c.nameFormatter = fn
+	})
+}
+
+// WithStatement formats command args for db.query.text attribute and log field, nil disables them.
+// Default: SanitizeArgs, use FullArgs to see values.
+func WithStatement(fn StatementFn) Option {
+	return optionFunc(func(c *config) {
+		c.statement = fn
+	})
+}
+
+// WithMaxStatementSize truncates statement, 0 means no limit
+// Default: DefaultMaxStatementSize
+func WithMaxStatementSize(size int) Option {
+	return optionFunc(func(c *config) {
+		c.maxStatementSize = size
+	})
+}
+
+// WithDefaultAttributes set to each span and metric, e.g. name of redis instance
+func WithDefaultAttributes(attrs ...attribute.KeyValue) Option {
+	return optionFunc(func(c *config) {
+		c.defaultAttributes = append(c.defaultAttributes, attrs...)
+	})
+}
+
+// WithMinimumReadStatsInterval between calls of PoolStats by RecordStats
+// Default: 1s
+func WithMinimumReadStatsInterval(interval time.Duration) Option {
+	return optionFunc(func(c *config) {
+		c.minimumReadStatsInterval = interval
+	})
+}
@@ -0,0 +1,80 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const (
+	dbRedisClientLatencyMs = "db.redis.client.latency"
+	dbRedisClientCalls     = "db.redis.client.calls"
+	dbRedisClientErrors    = "db.redis.client.errors"
+
+	dbRedisConnectionsHits     = "db.redis.connections.hits"
+	dbRedisConnectionsMisses   = "db.redis.connections.misses"
+	dbRedisConnectionsTimeouts = "db.redis.connections.timeouts"
+	dbRedisConnectionsTotal    = "db.redis.connections.total"
+	dbRedisConnectionsIdle     = "db.redis.connections.idle"
+	dbRedisConnectionsStale    = "db.redis.connections.stale"
+)
+
+// recorder records metrics of commands, pipelines and dials
+type recorder struct {
+	latency metric.Float64Histogram
+	calls   metric.Int64Counter
+	errors  metric.Int64Counter
+
+	attrs []attribute.KeyValue
+}
+
+func newRecorder(meter metric.Meter, attrs []attribute.KeyValue) (*recorder, error) {
+	latency, err := meter.Float64Histogram(dbRedisClientLatencyMs,
+		metric.WithUnit("ms"),
+		metric.WithDescription(`The distribution of latencies of various calls in milliseconds`),
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	calls, err := meter.Int64Counter(dbRedisClientCalls,
+		metric.WithUnit("1"),
+		metric.WithDescription(`The number of various calls of commands`),
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	errs, err := meter.Int64Counter(dbRedisClientErrors,
+		metric.WithUnit("1"),
+		metric.WithDescription(`The number of failed calls of commands`),
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &recorder{latency: latency, calls: calls, errors: errs, attrs: attrs}, nil
+}
+
+// record call of operation started at start
+func (r *recorder) record(ctx context.Context, op string, start time.Time, err error) {
+	elapsed := float64(time.Since(start).Nanoseconds()) / 1e6
+
+	attrs := make([]attribute.KeyValue, 0, len(r.attrs)+2)
+	attrs = append(attrs, r.attrs...)
+	attrs = append(attrs, semconv.DBOperationName(op))
+
+	if err != nil {
+		attrs = append(attrs, dbRedisStatusERROR)
+		r.errors.Add(ctx, 1, metric.WithAttributes(attrs...))
+	} else {
+		attrs = append(attrs, dbRedisStatusOK)
+	}
+
+	r.calls.Add(ctx, 1, metric.WithAttributes(attrs...))
+	r.latency.Record(ctx, elapsed, metric.WithAttributes(attrs...))
+}
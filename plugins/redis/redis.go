@@ -0,0 +1,204 @@
+// Package redis instruments github.com/redis/go-redis/v9 clients with tel: spans, metrics and error logs of commands
+package redis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/tel-io/tel/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const instrumentationName = "github.com/tel-io/instrumentation/plugins/redis"
+
+const (
+	opPipeline = "pipeline"
+	opDial     = "dial"
+)
+
+// Hook implements redis.Hook: every command, pipeline and dial gets client span, metrics and error log
+type Hook struct {
+	*config
+
+	tracer   trace.Tracer
+	recorder *recorder
+}
+
+var _ redis.Hook = &Hook{}
+
+// New hook, add it to client by AddHook
+func New(opts ...Option) (*Hook, error) {
+	cfg := newConfig(opts...)
+
+	rec, err := newRecorder(cfg.meterProvider.Meter(instrumentationName), cfg.defaultAttributes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Hook{
+		config:   cfg,
+		tracer:   cfg.tracerProvider.Tracer(instrumentationName),
+		recorder: rec,
+	}, nil
+}
+
+// Instrument adds hook to client and records its pool statistics
+func Instrument(rdb redis.UniversalClient, opts ...Option) error {
+	h, err := New(opts...)
+	if err != nil {
+		return err
+	}
+
+	rdb.AddHook(h)
+
+	return RecordStats(rdb, opts...)
+}
+
+func (h *Hook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		start := time.Now()
+		ctx, end := h.trace(ctx, opDial, addrAttributes(network, addr)...)
+
+		conn, err := next(ctx, network, addr)
+
+		end(err)
+		h.recorder.record(ctx, opDial, start, err)
+
+		if err != nil {
+			h.logger(ctx).Error(h.nameFormatter(opDial), tel.Error(err),
+				tel.String("network", network), tel.String("addr", addr),
+				tel.Duration("duration", time.Since(start)),
+			)
+		}
+
+		return conn, err
+	}
+}
+
+func (h *Hook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		op := cmd.FullName()
+		stmt := h.formatStatement(cmd)
+
+		ctx, end := h.trace(ctx, op, h.statementAttributes(stmt)...)
+
+		err := next(ctx, cmd)
+		cmdErr := commandError(err)
+
+		end(cmdErr)
+		h.recorder.record(ctx, op, start, cmdErr)
+		h.log(ctx, op, stmt, start, cmdErr)
+
+		return err
+	}
+}
+
+func (h *Hook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		stmt := h.formatStatement(cmds...)
+
+		ctx, end := h.trace(ctx, opPipeline,
+			append(h.statementAttributes(stmt), dbRedisNumCmd.Int(len(cmds)))...,
+		)
+
+		err := next(ctx, cmds)
+		cmdErr := commandError(err)
+
+		end(cmdErr)
+		h.recorder.record(ctx, opPipeline, start, cmdErr)
+		h.log(ctx, opPipeline, stmt, start, cmdErr)
+
+		return err
+	}
+}
+
+// trace starts client span of operation when it's allowed, end finishes it with status of err
+func (h *Hook) trace(ctx context.Context, op string, attrs ...attribute.KeyValue) (context.Context, func(err error)) {
+	if !h.allowRoot && !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, func(error) {}
+	}
+
+	list := make([]attribute.KeyValue, 0, len(h.defaultAttributes)+len(attrs)+2)
+	list = append(list, h.defaultAttributes...)
+	list = append(list, semconv.DBSystemRedis, semconv.DBOperationName(op))
+	list = append(list, attrs...)
+
+	ctx, span := h.tracer.Start(ctx, h.nameFormatter(op),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(list...),
+	)
+
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+
+		span.End()
+	}
+}
+
+// log failed operation
+func (h *Hook) log(ctx context.Context, op, stmt string, start time.Time, err error) {
+	if err == nil {
+		return
+	}
+
+	fields := []zap.Field{tel.Error(err), tel.Duration("duration", time.Since(start))}
+	if stmt != "" {
+		fields = append(fields, tel.String("statement", stmt))
+	}
+
+	h.logger(ctx).Error(h.nameFormatter(op), fields...)
+}
+
+// logger of ctx, tel of hook otherwise
+func (h *Hook) logger(ctx context.Context) *tel.Telemetry {
+	if t := tel.ContextValue(ctx); t != nil {
+		return t
+	}
+
+	return h.tele
+}
+
+func (h *Hook) statementAttributes(stmt string) []attribute.KeyValue {
+	if stmt == "" {
+		return nil
+	}
+
+	return []attribute.KeyValue{semconv.DBQueryText(stmt)}
+}
+
+// commandError skips redis.Nil: it's reply of missing key, not a failure
+func commandError(err error) error {
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+
+	return err
+}
+
+func addrAttributes(network, addr string) []attribute.KeyValue {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return []attribute.KeyValue{semconv.NetworkTransportKey.String(network), semconv.ServerAddress(addr)}
+	}
+
+	attrs := []attribute.KeyValue{semconv.NetworkTransportKey.String(network), semconv.ServerAddress(host)}
+
+	if p, err := strconv.Atoi(port); err == nil {
+		attrs = append(attrs, semconv.ServerPort(p))
+	}
+
+	return attrs
+}
@@ -0,0 +1,228 @@
+package redis
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/suite"
+	"github.com/tel-io/tel/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type Suite struct {
+	suite.Suite
+
+	rdb    *redis.Client
+	spans  *tracetest.SpanRecorder
+	reader *sdkmetric.ManualReader
+	logs   *observer.ObservedLogs
+}
+
+func (s *Suite) SetupTest() {
+	s.setup()
+}
+
+func (s *Suite) TearDownTest() {
+	_ = s.rdb.Close()
+	s.rdb = nil
+}
+
+func TestRedis(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+// setup instruments new client of new server with opts, client of previous setup is closed
+func (s *Suite) setup(opts ...Option) {
+	if s.rdb != nil {
+		_ = s.rdb.Close()
+	}
+
+	srv := miniredis.RunT(s.T())
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	tele := tel.NewNull()
+	tele.Logger = zap.New(core)
+
+	s.rdb = redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	s.spans = tracetest.NewSpanRecorder()
+	s.reader = sdkmetric.NewManualReader()
+	s.logs = logs
+
+	s.Require().NoError(Instrument(s.rdb, append([]Option{
+		WithTel(&tele),
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(s.spans))),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(s.reader))),
+		WithTraceRoot(true),
+		WithMinimumReadStatsInterval(0),
+	}, opts...)...))
+}
+
+func (s *Suite) metric(name string) metricdata.Aggregation {
+	var rm metricdata.ResourceMetrics
+	s.Require().NoError(s.reader.Collect(context.Background(), &rm))
+
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				return m.Data
+			}
+		}
+	}
+
+	return nil
+}
+
+// sum of counter data points with attribute
+func (s *Suite) sum(name string, kv attribute.KeyValue) int64 {
+	data, ok := s.metric(name).(metricdata.Sum[int64])
+	if !ok {
+		return 0
+	}
+
+	var res int64
+
+	for _, dp := range data.DataPoints {
+		if v, ok := dp.Attributes.Value(kv.Key); ok && v == kv.Value {
+			res += dp.Value
+		}
+	}
+
+	return res
+}
+
+func (s *Suite) span(name string) sdktrace.ReadOnlySpan {
+	for _, span := range s.spans.Ended() {
+		if span.Name() == name {
+			return span
+		}
+	}
+
+	s.Require().Failf("span not found", name)
+
+	return nil
+}
+
+func attr(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+
+	return attribute.Value{}
+}
+
+func (s *Suite) TestProcessHook() {
+	ctx := context.Background()
+
+	s.Require().NoError(s.rdb.Set(ctx, "user:1", "secret", 0).Err())
+	s.Require().ErrorIs(s.rdb.Get(ctx, "missing").Err(), redis.Nil)
+
+	set := s.span("redis:set")
+	s.Equal(trace.SpanKindClient, set.SpanKind())
+	s.Equal("set ? ?", attr(set, semconv.DBQueryTextKey).AsString())
+	s.Equal("redis", attr(set, semconv.DBSystemKey).AsString())
+
+	get := s.span("redis:get")
+	s.Equal(codes.Unset, get.Status().Code, "redis.Nil is not an error")
+
+	s.Equal(int64(1), s.sum(dbRedisClientCalls, semconv.DBOperationName("set")))
+	s.Equal(int64(1), s.sum(dbRedisClientCalls, semconv.DBOperationName("get")))
+	s.Equal(int64(0), s.sum(dbRedisClientErrors, semconv.DBOperationName("get")))
+	s.Equal(0, s.logs.Len())
+}
+
+func (s *Suite) TestProcessHook_Error() {
+	ctx := context.Background()
+
+	s.Require().NoError(s.rdb.Set(ctx, "counter", "text", 0).Err())
+	s.Require().Error(s.rdb.Incr(ctx, "counter").Err())
+
+	span := s.span("redis:incr")
+	s.Equal(codes.Error, span.Status().Code)
+
+	s.Equal(int64(1), s.sum(dbRedisClientErrors, semconv.DBOperationName("incr")))
+
+	entries := s.logs.FilterMessage("redis:incr").All()
+	s.Require().Len(entries, 1)
+	s.Equal(zapcore.ErrorLevel, entries[0].Level)
+	s.Equal("incr ?", entries[0].ContextMap()["statement"])
+}
+
+func (s *Suite) TestProcessPipelineHook() {
+	ctx := context.Background()
+
+	_, err := s.rdb.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.Set(ctx, "a", "1", 0)
+		p.Get(ctx, "a")
+
+		return nil
+	})
+	s.Require().NoError(err)
+
+	span := s.span("redis:pipeline")
+	s.Equal(int64(2), attr(span, dbRedisNumCmd).AsInt64())
+	s.Equal("set ? ?\nget ?", attr(span, semconv.DBQueryTextKey).AsString())
+
+	s.Equal(int64(1), s.sum(dbRedisClientCalls, semconv.DBOperationName(opPipeline)))
+}
+
+func (s *Suite) TestStatement() {
+	s.setup(WithStatement(FullArgs), WithMaxStatementSize(16))
+	ctx := context.Background()
+
+	s.Require().NoError(s.rdb.Set(ctx, "k", strings.Repeat("v", 32), 0).Err())
+
+	stmt := attr(s.span("redis:set"), semconv.DBQueryTextKey).AsString()
+	s.Equal("set k vvvvvvvvvv (truncated 22 bytes)", stmt)
+
+	s.Run("disabled", func() {
+		s.setup(WithStatement(nil))
+
+		s.Require().NoError(s.rdb.Ping(ctx).Err())
+		s.Equal(attribute.INVALID, attr(s.span("redis:ping"), semconv.DBQueryTextKey).Type())
+	})
+}
+
+func (s *Suite) TestTraceRoot() {
+	s.setup(WithTraceRoot(false))
+	ctx := context.Background()
+
+	s.Require().NoError(s.rdb.Ping(ctx).Err())
+	s.Empty(s.spans.Ended(), "no span without parent")
+	s.Equal(int64(1), s.sum(dbRedisClientCalls, semconv.DBOperationName("ping")))
+}
+
+func (s *Suite) TestRecordStats() {
+	ctx := context.Background()
+
+	s.Require().NoError(s.rdb.Ping(ctx).Err())
+	s.Require().NoError(s.rdb.Ping(ctx).Err())
+
+	for name, want := range map[string]int64{
+		dbRedisConnectionsTotal:  1,
+		dbRedisConnectionsIdle:   1,
+		dbRedisConnectionsHits:   1,
+		dbRedisConnectionsMisses: 1,
+	} {
+		gauge, ok := s.metric(name).(metricdata.Gauge[int64])
+		s.Require().True(ok, name)
+		s.Require().Len(gauge.DataPoints, 1, name)
+		s.Equal(want, gauge.DataPoints[0].Value, name)
+	}
+
+	s.Equal(int64(1), s.sum(dbRedisClientCalls, semconv.DBOperationName(opDial)))
+}
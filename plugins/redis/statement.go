@@ -0,0 +1,103 @@
+package redis
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// NameFormatter of span name and log message by operation: command full name, "pipeline" or "dial"
+type NameFormatter func(op string) string
+
+// StatementFn formats command for db.query.text attribute and log field
+type StatementFn func(cmd redis.Cmder) string
+
+func formatSpanName(op string) string {
+	return "redis:" + op
+}
+
+// SanitizeArgs keeps command name and replaces other args with '?': "set k v ex 10" -> "set ? ? ? ?"
+func SanitizeArgs(cmd redis.Cmder) string {
+	args := cmd.Args()
+	skip := nameArgs(cmd)
+
+	var b strings.Builder
+
+	b.WriteString(cmd.FullName())
+
+	for i := skip; i < len(args); i++ {
+		b.WriteString(" ?")
+	}
+
+	return b.String()
+}
+
+// FullArgs writes command with all args: "set k v ex 10"
+func FullArgs(cmd redis.Cmder) string {
+	args := cmd.Args()
+
+	var b strings.Builder
+
+	for i, arg := range args {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+
+		switch v := arg.(type) {
+		case string:
+			b.WriteString(v)
+		case []byte:
+			b.Write(v)
+		default:
+			fmt.Fprint(&b, v)
+		}
+	}
+
+	return b.String()
+}
+
+// nameArgs is number of args of command name: "cluster info" has 2
+func nameArgs(cmd redis.Cmder) int {
+	n := strings.Count(cmd.FullName(), " ") + 1
+
+	return min(n, len(cmd.Args()))
+}
+
+// truncate s to size bytes keeping utf8 runes
+func truncate(s string, size int) string {
+	if size <= 0 || len(s) <= size {
+		return s
+	}
+
+	l := size
+	for l > 0 && !utf8.RuneStart(s[l]) {
+		l--
+	}
+
+	return fmt.Sprintf("%s (truncated %d bytes)", s[:l], len(s)-l)
+}
+
+// statement of commands, pipeline commands are joined by new line
+func (c *config) formatStatement(cmds ...redis.Cmder) string {
+	if c.statement == nil {
+		return ""
+	}
+
+	var b strings.Builder
+
+	for i, cmd := range cmds {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+
+		b.WriteString(c.statement(cmd))
+
+		if c.maxStatementSize > 0 && b.Len() > c.maxStatementSize {
+			break
+		}
+	}
+
+	return truncate(b.String(), c.maxStatementSize)
+}
@@ -0,0 +1,131 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Pooler is implemented by redis.Client, redis.ClusterClient and redis.Ring
+type Pooler interface {
+	PoolStats() *redis.PoolStats
+}
+
+// RecordStats records connection pool statistics of provided client
+// Options WithTel, WithMeterProvider, WithDefaultAttributes and WithMinimumReadStatsInterval are used.
+func RecordStats(rdb Pooler, opts ...Option) error {
+	cfg := newConfig(opts...)
+
+	meter := cfg.meterProvider.Meter(instrumentationName)
+
+	return recordStats(meter, rdb, cfg.minimumReadStatsInterval, cfg.defaultAttributes...)
+}
+
+// nolint: funlen
+func recordStats(
+	meter metric.Meter,
+	rdb Pooler,
+	minimumReadStatsInterval time.Duration,
+	attrs ...attribute.KeyValue,
+) error {
+	var (
+		err error
+
+		hits     metric.Int64ObservableGauge
+		misses   metric.Int64ObservableGauge
+		timeouts metric.Int64ObservableGauge
+		total    metric.Int64ObservableGauge
+		idle     metric.Int64ObservableGauge
+		stale    metric.Int64ObservableGauge
+
+		stats     redis.PoolStats
+		lastStats time.Time
+
+		// lock prevents a race between batch observer and instrument registration.
+		lock sync.Mutex
+	)
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	if hits, err = meter.Int64ObservableGauge(
+		dbRedisConnectionsHits,
+		metric.WithUnit("1"),
+		metric.WithDescription("The number of times free connection was found in the pool"),
+	); err != nil {
+		return err
+	}
+
+	if misses, err = meter.Int64ObservableGauge(
+		dbRedisConnectionsMisses,
+		metric.WithUnit("1"),
+		metric.WithDescription("The number of times free connection was not found in the pool"),
+	); err != nil {
+		return err
+	}
+
+	if timeouts, err = meter.Int64ObservableGauge(
+		dbRedisConnectionsTimeouts,
+		metric.WithUnit("1"),
+		metric.WithDescription("The number of times a wait timeout occurred"),
+	); err != nil {
+		return err
+	}
+
+	if total, err = meter.Int64ObservableGauge(
+		dbRedisConnectionsTotal,
+		metric.WithUnit("1"),
+		metric.WithDescription("Count of total connections in the pool"),
+	); err != nil {
+		return err
+	}
+
+	if idle, err = meter.Int64ObservableGauge(
+		dbRedisConnectionsIdle,
+		metric.WithUnit("1"),
+		metric.WithDescription("Count of idle connections in the pool"),
+	); err != nil {
+		return err
+	}
+
+	if stale, err = meter.Int64ObservableGauge(
+		dbRedisConnectionsStale,
+		metric.WithUnit("1"),
+		metric.WithDescription("The number of stale connections removed from the pool"),
+	); err != nil {
+		return err
+	}
+
+	_, err = meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		lock.Lock()
+		defer lock.Unlock()
+
+		now := time.Now()
+		if now.Sub(lastStats) >= minimumReadStatsInterval {
+			stats = *rdb.PoolStats()
+			lastStats = now
+		}
+
+		o.ObserveInt64(hits, int64(stats.Hits), metric.WithAttributes(attrs...))
+		o.ObserveInt64(misses, int64(stats.Misses), metric.WithAttributes(attrs...))
+		o.ObserveInt64(timeouts, int64(stats.Timeouts), metric.WithAttributes(attrs...))
+		o.ObserveInt64(total, int64(stats.TotalConns), metric.WithAttributes(attrs...))
+		o.ObserveInt64(idle, int64(stats.IdleConns), metric.WithAttributes(attrs...))
+		o.ObserveInt64(stale, int64(stats.StaleConns), metric.WithAttributes(attrs...))
+
+		return nil
+	}, []metric.Observable{
+		hits,
+		misses,
+		timeouts,
+		total,
+		idle,
+		stale,
+	}...)
+
+	return err
+}